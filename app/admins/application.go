@@ -0,0 +1,226 @@
+package admins
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// applicationReviewer is an admin or adviser who can be assigned to review the
+// applications of their cohort.
+type applicationReviewer struct {
+	UserRoleID int
+	Role       string
+	User       skylab.User
+}
+
+// ApplicationView shows an application with its answers, status history and
+// reviews, and lets the admin assign and unassign its reviewers.
+func (adm Admins) ApplicationView(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminListApplications)
+	applicationID, err := urlparams.Int(r, "applicationID")
+	if err != nil {
+		adm.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	type Data struct {
+		Application   skylab.Application
+		StatusHistory []skylab.ApplicationStatusChange
+		Reviews       []skylab.ApplicationReview
+		Score         skylab.ApplicationScore
+		Reviewers     []applicationReviewer // reviewers that have not been assigned yet
+		ReviewForm    skylab.Form
+	}
+	var data Data
+	va := tables.V_APPLICATIONS()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(va).
+		Where(va.APPLICATION_ID.EqInt(applicationID)).
+		SelectRowx((&data.Application).RowMapper(va)).
+		Fetch(adm.skylb.DB)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.StatusHistory, err = adm.skylb.ApplicationStatusHistory(applicationID)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.ReviewForm, err = adm.skylb.ApplicationReviewForm(data.Application.Cohort)
+	if err != nil && !erro.Is(err, skylab.ErrApplicationReviewFormNotExist) {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	// The normalized score of an application depends on every other review by
+	// the same reviewers, so the whole cohort has to be ranked
+	a := tables.APPLICATIONS()
+	reviews, err := adm.skylb.ApplicationReviews(a.COHORT.EqString(data.Application.Cohort))
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.Score = skylab.RankApplications(reviews)[applicationID]
+	assigned := make(map[int]bool)
+	for _, review := range reviews {
+		if review.Application.ApplicationID == applicationID {
+			data.Reviews = append(data.Reviews, review)
+			assigned[review.ReviewerUserRoleID] = true
+		}
+	}
+	reviewers, err := adm.applicationReviewers(data.Application.Cohort)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	for _, reviewer := range reviewers {
+		if !assigned[reviewer.UserRoleID] {
+			data.Reviewers = append(data.Reviewers, reviewer)
+		}
+	}
+	funcs := formx.Funcs(nil, adm.skylb.Policy)
+	adm.skylb.Render(w, r, data, funcs, "app/admins/application.html", "helpers/formx/render_form_results.html")
+}
+
+// applicationReviewers returns the admins and advisers of a cohort.
+func (adm Admins) applicationReviewers(cohort string) ([]applicationReviewer, error) {
+	var reviewers []applicationReviewer
+	var reviewer applicationReviewer
+	ur, u := tables.USER_ROLES(), tables.USERS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(ur).
+		Join(u, u.USER_ID.Eq(ur.USER_ID)).
+		Where(
+			ur.COHORT.EqString(cohort),
+			ur.ROLE.In([]string{skylab.RoleAdmin, skylab.RoleAdviser}),
+			ur.DELETED_AT.IsNull(),
+		).
+		OrderBy(ur.ROLE, u.DISPLAYNAME).
+		Selectx(func(row *sq.Row) {
+			reviewer = applicationReviewer{
+				UserRoleID: row.Int(ur.USER_ROLE_ID),
+				Role:       row.String(ur.ROLE),
+				User: skylab.User{
+					Valid:       row.IntValid(u.USER_ID),
+					UserID:      row.Int(u.USER_ID),
+					Displayname: row.String(u.DISPLAYNAME),
+					Email:       row.String(u.EMAIL),
+				},
+			}
+		}, func() {
+			reviewers = append(reviewers, reviewer)
+		}).
+		Fetch(adm.skylb.DB)
+	return reviewers, erro.Wrap(err)
+}
+
+// ApplicationReviewerAssign assigns the admin or adviser with the posted
+// user_role_id to review the application. Assigning a reviewer twice does
+// nothing.
+func (adm Admins) ApplicationReviewerAssign(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		applicationID, err := urlparams.Int(r, "applicationID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		userRoleID, err := strconv.Atoi(r.FormValue("user_role_id"))
+		if err != nil {
+			adm.skylb.BadRequest(w, r, fmt.Sprintf("Invalid user_role_id: %s", r.FormValue("user_role_id")))
+			return
+		}
+		err = adm.assignApplicationReviewer(applicationID, userRoleID)
+		if err != nil {
+			if !erro.Is(err, skylab.ErrApplicationReviewerInvalid) {
+				adm.skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], "Reviewer assigned")
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (adm Admins) assignApplicationReviewer(applicationID, userRoleID int) error {
+	// Only admins and advisers of the application's own cohort may review it
+	a, ur := tables.APPLICATIONS(), tables.USER_ROLES()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(a).
+		Join(ur, ur.COHORT.Eq(a.COHORT)).
+		Where(
+			a.APPLICATION_ID.EqInt(applicationID),
+			ur.USER_ROLE_ID.EqInt(userRoleID),
+			ur.ROLE.In([]string{skylab.RoleAdmin, skylab.RoleAdviser}),
+		).
+		SelectRowx(func(row *sq.Row) {
+			row.Int(a.APPLICATION_ID)
+		}).
+		Fetch(adm.skylb.DB)
+	if erro.Is(err, sql.ErrNoRows) {
+		return erro.Errorf(skylab.ErrApplicationReviewerInvalid, userRoleID, applicationID)
+	}
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	ar := tables.APPLICATION_REVIEWS()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		InsertInto(ar).
+		Columns(ar.APPLICATION_ID, ar.REVIEWER_USER_ROLE_ID).
+		Values(applicationID, userRoleID).
+		OnConflict(ar.APPLICATION_ID, ar.REVIEWER_USER_ROLE_ID).DoNothing().
+		Exec(adm.skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// ApplicationReviewerUnassign removes the review with the posted
+// application_review_id from the application, along with whatever the
+// reviewer had scored.
+func (adm Admins) ApplicationReviewerUnassign(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		applicationID, err := urlparams.Int(r, "applicationID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		applicationReviewID, err := strconv.Atoi(r.FormValue("application_review_id"))
+		if err != nil {
+			adm.skylb.BadRequest(w, r, fmt.Sprintf("Invalid application_review_id: %s", r.FormValue("application_review_id")))
+			return
+		}
+		ar := tables.APPLICATION_REVIEWS()
+		_, err = sq.WithDefaultLog(sq.Lverbose).
+			DeleteFrom(ar).
+			Where(
+				ar.APPLICATION_REVIEW_ID.EqInt(applicationReviewID),
+				ar.APPLICATION_ID.EqInt(applicationID),
+			).
+			Exec(adm.skylb.DB, 0)
+		if err != nil {
+			adm.skylb.InternalServerError(w, r, err)
+			return
+		}
+		msgs[flash.Success] = append(msgs[flash.Success], "Reviewer unassigned")
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,12 +2,64 @@ package admins
 
 import (
 	"net/http"
+	"time"
 
+	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/tables"
 )
 
+// teamStatusTrendDays is how far back the dashboard looks for team status
+// changes.
+const teamStatusTrendDays = 30
+
+// Dashboard shows the status of the current cohort's teams grouped by adviser,
+// along with the status changes over the last teamStatusTrendDays days.
 func (adm Admins) Dashboard(w http.ResponseWriter, r *http.Request) {
 	adm.skylb.Log.TraceRequest(r)
 	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminDashboard)
-	adm.skylb.Render(w, r, nil, nil, "app/admins/dashboard.html")
+	type Data struct {
+		Cohort        string
+		Since         time.Time
+		Trends        []skylab.AdviserTeamStatusTrend
+		RecentChanges []skylab.TeamStatusChange
+		TeamNames     map[int]string
+	}
+	data := Data{
+		Cohort:    adm.skylb.CurrentCohort(),
+		Since:     time.Now().AddDate(0, 0, -teamStatusTrendDays),
+		TeamNames: make(map[int]string),
+	}
+	var teams []skylab.Team
+	t := tables.V_TEAMS()
+	team := &skylab.Team{}
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(t).
+		Where(t.COHORT.EqString(data.Cohort)).
+		OrderBy(t.TEAM_ID).
+		Selectx(team.RowMapper(t), func() {
+			teams = append(teams, *team)
+			data.TeamNames[team.TeamID] = team.TeamName
+		}).
+		Fetch(adm.skylb.DB)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	tt, tsh := tables.TEAMS(), tables.TEAM_STATUS_HISTORY()
+	history, err := adm.skylb.TeamStatusHistory(
+		tt.COHORT.EqString(data.Cohort),
+		tsh.CREATED_AT.GeTime(data.Since),
+	)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.Trends = skylab.TeamStatusTrends(teams, history, data.Since)
+	for _, change := range history {
+		if change.OldStatus != "" {
+			data.RecentChanges = append(data.RecentChanges, change)
+		}
+	}
+	adm.skylb.Render(w, r, data, nil, "app/admins/dashboard.html")
 }
@@ -1,9 +1,13 @@
 package admins
 
 import (
+	"encoding/json"
+	"fmt"
 	"html/template"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
@@ -31,6 +35,7 @@ func (adm Admins) FormEdit(w http.ResponseWriter, r *http.Request) {
 		data.Form.FormID = row.Int(f.FORM_ID)
 		data.Form.Name = row.String(f.NAME)
 		data.Form.Subsection = row.String(f.SUBSECTION)
+		data.Form.Version = row.Int(f.VERSION)
 		row.ScanInto(&data.Form.Questions, f.QUESTIONS)
 		// Period
 		data.Form.Period.Valid = row.IntValid(p.PERIOD_ID)
@@ -49,11 +54,21 @@ func (adm Admins) FormEdit(w http.ResponseWriter, r *http.Request) {
 	data.QuestionsAnswers = formx.MergeQuestionsAnswers(data.Form.Questions, formx.Answers{})
 	data.PreviewURL = skylab.AdminForm + "/" + strconv.Itoa(formID) + "/preview"
 	data.UpdateURL = skylab.AdminForm + "/" + strconv.Itoa(formID) + "/update"
+	data.ExportURL = skylab.AdminForm + "/" + strconv.Itoa(formID) + "/export"
+	data.AnalyticsURL = skylab.AdminForm + "/" + strconv.Itoa(formID) + "/analytics"
 	funcs := template.FuncMap{}
 	funcs = templateutil.Funcs(funcs)
 	adm.skylb.Render(w, r, data, funcs, "app/skylab/form_edit.html")
 }
 
+// renameFieldPrefix prefixes the fields of form_update_confirm.html that say
+// which new question each orphaned question name was renamed to
+const renameFieldPrefix = "rename."
+
+// FormUpdate saves the questions of a form as a new version of the form. If
+// the change would orphan existing answers (i.e. a question that has answers
+// was removed or renamed), the admin is first shown form_update_confirm.html
+// to say which questions were renamed and which can be discarded.
 func (adm Admins) FormUpdate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		formID, err := urlparams.Int(r, "formID")
@@ -64,22 +79,106 @@ func (adm Admins) FormUpdate(next http.Handler) http.Handler {
 		msgs := make(map[string][]string)
 		_ = formutil.ParseForm(r)
 		data := r.FormValue("data")
+		var questions, oldQuestions formx.Questions
+		err = json.Unmarshal([]byte(data), &questions)
+		if err != nil {
+			msgs[flash.Error] = []string{"Unable to read the form questions: " + err.Error()}
+			r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
 		f := tables.FORMS()
-		_, err = sq.WithDefaultLog(sq.Lstats).
-			Update(f).
-			Set(f.QUESTIONS.Set(data)).
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(f).
 			Where(f.FORM_ID.EqInt(formID)).
-			Exec(adm.skylb.DB, sq.ErowsAffected)
+			SelectRowx(func(row *sq.Row) {
+				row.ScanInto(&oldQuestions, f.QUESTIONS)
+			}).
+			Fetch(adm.skylb.DB)
+		if err != nil {
+			adm.skylb.InternalServerError(w, r, err)
+			return
+		}
+		questions = formx.CarryPreviousNames(oldQuestions, questions)
+		confirmed := r.FormValue("confirm") == "true"
+		renames := make(map[string]string)
+		if confirmed {
+			for field := range r.PostForm {
+				if oldName := strings.TrimPrefix(field, renameFieldPrefix); oldName != field && r.PostForm.Get(field) != "" {
+					renames[oldName] = r.PostForm.Get(field)
+				}
+			}
+		}
+		questions, err = questions.Rename(renames)
+		if err != nil {
+			msgs[flash.Error] = []string{err.Error()}
+			r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !confirmed {
+			orphans := formx.Orphans(oldQuestions, questions)
+			counts, err := adm.skylb.CountOrphanedAnswers(formID, orphans)
+			if err != nil {
+				adm.skylb.InternalServerError(w, r, err)
+				return
+			}
+			if len(counts) > 0 {
+				adm.formUpdateConfirm(w, r, formID, data, oldQuestions, questions, counts)
+				return
+			}
+		}
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		version, err := adm.skylb.SaveFormVersion(formID, questions, renames, user.UserID)
 		if err != nil {
 			msgs[flash.Error] = []string{err.Error()}
 		} else {
-			msgs[flash.Success] = []string{"Form updated!"}
+			msgs[flash.Success] = []string{fmt.Sprintf("Form updated! (version %d)", version)}
 		}
 		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
 		next.ServeHTTP(w, r)
 	})
 }
 
+// OrphanedQuestion is a question name that answers are stored under, which
+// the updated form no longer has.
+type OrphanedQuestion struct {
+	Name      string
+	Answers   int    // number of answers to the form that answered the question
+	Suggested string // name of the question it was most likely renamed to
+}
+
+func (adm Admins) formUpdateConfirm(w http.ResponseWriter, r *http.Request, formID int, data string, oldQuestions, questions formx.Questions, counts map[string]int) {
+	type Data struct {
+		Title     string
+		Data      string
+		ActionURL string
+		EditURL   string
+		Orphans   []OrphanedQuestion
+		Names     []string // names of the updated questions that an orphaned name may be renamed to
+	}
+	var orphans []string
+	for name := range counts {
+		orphans = append(orphans, name)
+	}
+	sort.Strings(orphans)
+	suggestions := formx.SuggestRenames(oldQuestions, questions, orphans)
+	d := Data{
+		Title:     "Confirm form changes",
+		Data:      data,
+		ActionURL: r.URL.Path,
+		EditURL:   skylab.AdminForm + "/" + strconv.Itoa(formID) + "/edit",
+		Names:     questions.Names(),
+	}
+	for _, name := range orphans {
+		d.Orphans = append(d.Orphans, OrphanedQuestion{Name: name, Answers: counts[name], Suggested: suggestions[name]})
+	}
+	funcs := template.FuncMap{}
+	funcs = templateutil.Funcs(funcs)
+	funcs["renameField"] = func(name string) string { return renameFieldPrefix + name }
+	adm.skylb.Render(w, r, d, funcs, "app/admins/form_update_confirm.html")
+}
+
 func (adm Admins) FormView(w http.ResponseWriter, r *http.Request) {
 	adm.skylb.Log.TraceRequest(r)
 	r = adm.skylb.SetRoleSection(w, r, skylab.RolePreserve, skylab.SectionPreserve)
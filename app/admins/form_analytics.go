@@ -0,0 +1,79 @@
+package admins
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/templateutil"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// FormAnalytics shows the aggregate results of every response to a form,
+// question by question, optionally filtered by cohort and project level.
+func (adm Admins) FormAnalytics(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	r = adm.skylb.SetRoleSection(w, r, skylab.RolePreserve, skylab.SectionPreserve)
+	formID, err := urlparams.Int(r, "formID")
+	if err != nil {
+		adm.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	type Data struct {
+		Title     string
+		Form      skylab.Form
+		Filter    skylab.ResponseFilter
+		Responses int
+		Stats     []formx.QuestionStats
+		EditURL   string
+	}
+	var data Data
+	data.Filter.Cohort = r.FormValue("cohort")
+	data.Filter.ProjectLevel = r.FormValue("project_level")
+	data.Filter.IncludeDrafts = r.FormValue("drafts") == "true"
+	f, p := tables.FORMS(), tables.PERIODS()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(f).
+		Join(p, p.PERIOD_ID.Eq(f.PERIOD_ID)).
+		Where(f.FORM_ID.EqInt(formID)).
+		SelectRowx(func(row *sq.Row) {
+			data.Form.Valid = row.IntValid(f.FORM_ID)
+			data.Form.FormID = row.Int(f.FORM_ID)
+			data.Form.Name = row.String(f.NAME)
+			data.Form.Subsection = row.String(f.SUBSECTION)
+			data.Form.Version = row.Int(f.VERSION)
+			row.ScanInto(&data.Form.Questions, f.QUESTIONS)
+			data.Form.Period.Valid = row.IntValid(p.PERIOD_ID)
+			data.Form.Period.PeriodID = row.Int(p.PERIOD_ID)
+			data.Form.Period.Cohort = row.String(p.COHORT)
+			data.Form.Period.Stage = row.String(p.STAGE)
+			data.Form.Period.Milestone = row.String(p.MILESTONE)
+		}).
+		Fetch(adm.skylb.DB)
+	if err != nil {
+		adm.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	responses, err := adm.skylb.FormResponses(formID, data.Filter)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.Title = data.Form.Title()
+	data.Responses = len(responses)
+	data.Stats = formx.Analyze(data.Form.Questions, responses)
+	data.EditURL = skylab.AdminForm + "/" + strconv.Itoa(formID) + "/edit"
+	funcs := template.FuncMap{}
+	funcs = formx.Funcs(funcs, adm.skylb.Policy)
+	funcs = templateutil.Funcs(funcs)
+	funcs["formatNumber"] = func(f float64) string {
+		return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+	}
+	funcs["stringID"] = func(i int) string { return "texts-" + strconv.Itoa(i) }
+	adm.skylb.Render(w, r, data, funcs, "app/admins/form_analytics.html")
+}
@@ -0,0 +1,106 @@
+package admins
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+)
+
+// maxFormFileSize is the largest form file that can be imported. The largest
+// form so far is well under 100KB.
+const maxFormFileSize = 4 << 20
+
+// FormExport downloads the definition of a form as a JSON or YAML file
+// (?format=json or ?format=yaml), see skylab.FormFile.
+func (adm Admins) FormExport(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	formID, err := urlparams.Int(r, "formID")
+	if err != nil {
+		adm.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	format := r.FormValue("format")
+	if format != skylab.FormFileFormatYAML {
+		format = skylab.FormFileFormatJSON
+	}
+	ff, err := adm.skylb.ExportForm(formID)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	b, err := skylab.MarshalFormFile(ff, format)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	switch format {
+	case skylab.FormFileFormatYAML:
+		w.Header().Set("Content-Type", "application/x-yaml; charset=utf-8")
+	default:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ff.Filename(format)))
+	_, _ = w.Write(b)
+}
+
+// ListFormsImport creates or updates a form from an uploaded JSON or YAML form
+// file, see skylab.Skylab.ImportForm.
+func (adm Admins) ListFormsImport(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		fail := func(w http.ResponseWriter, r *http.Request, msgs map[string][]string) {
+			r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+			adm.skylb.Redirect(skylab.AdminListForms)(w, r)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			msgs[flash.Error] = []string{"Please choose a form file to import"}
+			fail(w, r, msgs)
+			return
+		}
+		defer file.Close()
+		format, err := skylab.FormFileFormatOf(header.Filename)
+		if err != nil {
+			msgs[flash.Error] = []string{err.Error()}
+			fail(w, r, msgs)
+			return
+		}
+		b, err := ioutil.ReadAll(http.MaxBytesReader(w, file, maxFormFileSize))
+		if err != nil {
+			msgs[flash.Error] = []string{err.Error()}
+			fail(w, r, msgs)
+			return
+		}
+		ff, err := skylab.UnmarshalFormFile(b, format)
+		if err != nil {
+			msgs[flash.Error] = []string{fmt.Sprintf("Unable to read %s: %s", header.Filename, err)}
+			fail(w, r, msgs)
+			return
+		}
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		formID, version, err := adm.skylb.ImportForm(ff, user.UserID, r.FormValue("discard") == "true")
+		if err != nil {
+			var schemaErr formx.SchemaError
+			if errors.As(err, &schemaErr) {
+				msgs[flash.Error] = append([]string{header.Filename + " has invalid questions:"}, schemaErr.Problems...)
+			} else {
+				msgs[flash.Error] = []string{err.Error()}
+			}
+			fail(w, r, msgs)
+			return
+		}
+		msgs[flash.Success] = []string{fmt.Sprintf("Form imported from %s! (version %d)", header.Filename, version)}
+		r = urlparams.SetInt(r, "formID", formID)
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
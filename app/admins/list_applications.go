@@ -1,9 +1,15 @@
 package admins
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
 	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
 	"github.com/bokwoon95/nusskylabx/tables"
 
@@ -26,8 +32,12 @@ func (adm Admins) ListApplications(w http.ResponseWriter, r *http.Request) {
 	type Data struct {
 		Applications []skylab.Application
 		Cohort       string
+		Decisions    []string
+		Staged       map[int]string // application_id -> staged decision
+		Scores       map[int]skylab.ApplicationScore
 	}
 	var data Data
+	data.Decisions = skylab.ApplicationDecisions()
 	var msgs = make(map[string][]string)
 	var application skylab.Application
 	data.Cohort = cohort
@@ -67,6 +77,210 @@ func (adm Admins) ListApplications(w http.ResponseWriter, r *http.Request) {
 		adm.skylb.InternalServerError(w, r, err)
 		return
 	}
+	data.Staged, err = adm.stagedDecisions(cohort)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	reviews, err := adm.skylb.ApplicationReviews(tables.APPLICATIONS().COHORT.EqString(cohort))
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.Scores = skylab.RankApplications(reviews)
 	r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
 	adm.skylb.Render(w, r, data, nil, "app/admins/list_applications.html")
 }
+
+// stagedDecisions returns the decisions staged on the applications of a
+// cohort that have not been published yet.
+func (adm Admins) stagedDecisions(cohort string) (map[int]string, error) {
+	staged := make(map[int]string)
+	var applicationID int
+	var decision string
+	a := tables.APPLICATIONS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(a).
+		Where(
+			a.COHORT.EqString(cohort),
+			a.STAGED_STATUS.IsNotNull(),
+		).
+		Selectx(func(row *sq.Row) {
+			applicationID = row.Int(a.APPLICATION_ID)
+			decision = row.String(a.STAGED_STATUS)
+		}, func() {
+			staged[applicationID] = decision
+		}).
+		Fetch(adm.skylb.DB)
+	return staged, erro.Wrap(err)
+}
+
+// ListApplicationsStage stages the posted decision on an application. Staged
+// decisions are not visible to applicants until they are published with
+// ListApplicationsPublish. An empty decision unstages the application.
+func (adm Admins) ListApplicationsStage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		cohort, err := urlparams.String(r, "cohort")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		applicationID, err := strconv.Atoi(r.FormValue("application_id"))
+		if err != nil {
+			adm.skylb.BadRequest(w, r, fmt.Sprintf("Invalid application_id: %s", r.FormValue("application_id")))
+			return
+		}
+		decision := r.FormValue("decision")
+		if decision != "" && !skylab.Contains(skylab.ApplicationDecisions(), decision) {
+			adm.skylb.BadRequest(w, r, fmt.Sprintf("Invalid decision: %s", decision))
+			return
+		}
+		a := tables.APPLICATIONS()
+		set := a.STAGED_STATUS.SetString(decision)
+		if decision == "" {
+			set = a.STAGED_STATUS.Set(nil)
+		}
+		_, err = sq.WithDefaultLog(sq.Lverbose).
+			Update(a).
+			Set(set).
+			Where(
+				a.APPLICATION_ID.EqInt(applicationID),
+				a.COHORT.EqString(cohort),
+			).
+			Exec(adm.skylb.DB, 0)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else if decision == "" {
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Unstaged application %d", applicationID))
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Staged application %d as %s", applicationID, decision))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ListApplicationsPublish publishes every decision staged on the applications
+// of a cohort in one transaction, then emails the applicants of each
+// application whose status changed.
+func (adm Admins) ListApplicationsPublish(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		cohort, err := urlparams.String(r, "cohort")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		applicationIDs, err := adm.publishApplicationDecisions(cohort, user.UserID)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+			r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		var failed []string
+		for _, applicationID := range applicationIDs {
+			var application skylab.Application
+			a := tables.V_APPLICATIONS()
+			err = sq.WithDefaultLog(sq.Lverbose).
+				From(a).
+				Where(a.APPLICATION_ID.EqInt(applicationID)).
+				SelectRowx((&application).RowMapper(a)).
+				Fetch(adm.skylb.DB)
+			if err == nil {
+				err = adm.skylb.SendApplicationDecisionEmail(application)
+			}
+			if err != nil {
+				adm.skylb.Log.Printf("unable to email the decision on application %d: %s", applicationID, err)
+				failed = append(failed, strconv.Itoa(applicationID))
+			}
+		}
+		msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Published the decisions on %d application(s)", len(applicationIDs)))
+		if len(failed) > 0 {
+			msgs[flash.Error] = append(msgs[flash.Error], "Unable to email the applicants of application(s) "+strings.Join(failed, ", "))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// publishApplicationDecisions applies the staged decisions of a cohort on
+// behalf of the admin and returns the applications whose status changed.
+// Accepting an application goes through app.accept_application so that its
+// team and students are created, and moving an accepted application to any
+// other status goes through app.undo_accept_application first.
+func (adm Admins) publishApplicationDecisions(cohort string, adminUserID int) (applicationIDs []int, err error) {
+	tx, err := adm.skylb.DB.Begin()
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec(`SELECT set_config('var.user_id', $1, TRUE)`, strconv.Itoa(adminUserID))
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	type decision struct {
+		applicationID int
+		status        string
+		stagedStatus  string
+	}
+	var decisions []decision
+	rows, err := tx.Query(`
+	SELECT application_id, status, staged_status
+	FROM applications
+	WHERE cohort = $1 AND staged_status IS NOT NULL
+	ORDER BY application_id
+	FOR UPDATE
+	`, cohort)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	for rows.Next() {
+		var d decision
+		err = rows.Scan(&d.applicationID, &d.status, &d.stagedStatus)
+		if err != nil {
+			rows.Close()
+			return nil, erro.Wrap(err)
+		}
+		decisions = append(decisions, d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, erro.Wrap(err)
+	}
+	for _, d := range decisions {
+		if d.stagedStatus == d.status {
+			continue
+		}
+		switch {
+		case d.stagedStatus == skylab.ApplicationStatusAccepted:
+			_, err = tx.Exec(`SELECT app.accept_application($1, NULL)`, d.applicationID)
+		case d.status == skylab.ApplicationStatusAccepted:
+			_, err = tx.Exec(`SELECT app.undo_accept_application($1)`, d.applicationID)
+			if err == nil {
+				_, err = tx.Exec(`UPDATE applications SET status = $1 WHERE application_id = $2`, d.stagedStatus, d.applicationID)
+			}
+		default:
+			_, err = tx.Exec(`UPDATE applications SET status = $1 WHERE application_id = $2`, d.stagedStatus, d.applicationID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("application %d: %w", d.applicationID, erro.Wrap(err))
+		}
+		applicationIDs = append(applicationIDs, d.applicationID)
+	}
+	_, err = tx.Exec(`UPDATE applications SET staged_status = NULL WHERE cohort = $1 AND staged_status IS NOT NULL`, cohort)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	err = tx.Commit()
+	return applicationIDs, erro.Wrap(err)
+}
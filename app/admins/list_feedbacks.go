@@ -4,13 +4,35 @@ import (
 	"net/http"
 
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
 )
 
 func (adm Admins) ListFeedbacks(w http.ResponseWriter, r *http.Request) {
 	adm.skylb.Log.TraceRequest(r)
 	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminListFeedbacks)
+	headers.DoNotCache(w)
+
+	// Get the last valid cohort
+	cohort, _ := urlparams.PersistentString(w, r, "cohort", "_admin_list_feedbacks_cohort")
+	if cohort == "" || !skylab.Contains(adm.skylb.Cohorts(), cohort) {
+		http.Redirect(w, r, skylab.AdminListFeedbacks+"/"+adm.skylb.CurrentCohort(), http.StatusMovedPermanently)
+		return
+	}
+
 	type Data struct {
+		Cohort        string
+		UserFeedbacks []skylab.UserFeedback
 	}
 	var data Data
+	data.Cohort = cohort
+	var err error
+	p := tables.PERIODS()
+	data.UserFeedbacks, err = adm.skylb.UserFeedbacks(p.COHORT.EqString(cohort))
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
 	adm.skylb.Render(w, r, data, nil, "app/admins/list_feedbacks.html")
 }
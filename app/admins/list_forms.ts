@@ -12,6 +12,7 @@ MicroModal.init();
 const selected = new Set<string>();
 const selectAllBtn = document.querySelector("#select-all");
 const createFormBtn = document.querySelector("#create-form");
+const importFormBtn = document.querySelector("#import-form");
 const deleteFormBtn = document.querySelector("#delete-form");
 const unselectAllBtn = document.querySelector("#unselect-all");
 const periodDuplicateBtn = document.querySelector("#period-duplicate");
@@ -39,6 +40,9 @@ unselectAllBtn.addEventListener("click", datatablesUnselectAll(selected, redraw)
 createFormBtn.addEventListener("click", function () {
   MicroModal.show("create-form-form");
 });
+importFormBtn.addEventListener("click", function () {
+  MicroModal.show("import-form-form");
+});
 deleteFormBtn.addEventListener("click", function () {
   const deleteFormForm = document.querySelector("form#delete-form-form") as HTMLFormElement;
   const deleteFormFormInputs = deleteFormForm.querySelector("#delete-form-form-inputs");
@@ -0,0 +1,170 @@
+package admins
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/app/webhooks"
+	"github.com/bokwoon95/nusskylabx/helpers/auth"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+	"github.com/lib/pq"
+)
+
+func (adm Admins) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminListWebhooks)
+	type Data struct {
+		Webhooks []skylab.Webhook
+		Events   []string
+	}
+	data := Data{Events: skylab.WebhookEvents()}
+	wh := tables.WEBHOOKS()
+	var webhook skylab.Webhook
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(wh).
+		OrderBy(wh.WEBHOOK_ID).
+		Selectx(webhook.RowMapper(wh), func() {
+			data.Webhooks = append(data.Webhooks, webhook)
+		}).
+		Fetch(adm.skylb.DB)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	funcs := template.FuncMap{}
+	adm.skylb.Render(w, r, data, funcs, "app/admins/list_webhooks.html")
+}
+
+func (adm Admins) ListWebhooksCreate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		pass := func(w http.ResponseWriter, r *http.Request, msgs map[string][]string) {
+			r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+		}
+		webhookURL := r.FormValue("url")
+		u, err := url.Parse(webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			msgs[flash.Error] = append(msgs[flash.Error], fmt.Sprintf("'%s' is not a valid http(s) URL", webhookURL))
+			pass(w, r, msgs)
+			return
+		}
+		events := []string{}
+		for _, event := range r.Form["events"] {
+			if skylab.Contains(skylab.WebhookEvents(), event) {
+				events = append(events, event)
+			}
+		}
+		secret, err := auth.GenerateRandomString()
+		if err != nil {
+			adm.skylb.InternalServerError(w, r, err)
+			return
+		}
+		wh := tables.WEBHOOKS()
+		_, err = sq.WithDefaultLog(sq.Lverbose).
+			InsertInto(wh).
+			Columns(wh.URL, wh.SECRET, wh.DESCRIPTION, wh.EVENTS).
+			Values(webhookURL, secret, r.FormValue("description"), pq.Array(events)).
+			Exec(adm.skylb.DB, 0)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], "Created webhook for "+webhookURL)
+		}
+		pass(w, r, msgs)
+	})
+}
+
+func (adm Admins) ListWebhooksDelete(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		webhookID, err := urlparams.Int(r, "webhookID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		wh := tables.WEBHOOKS()
+		_, err = sq.WithDefaultLog(sq.Lverbose).
+			DeleteFrom(wh).
+			Where(wh.WEBHOOK_ID.EqInt(webhookID)).
+			Exec(adm.skylb.DB, 0)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Deleted webhook %d", webhookID))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (adm Admins) ListWebhooksToggle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		webhookID, err := urlparams.Int(r, "webhookID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		query := `UPDATE webhooks SET enabled = NOT enabled WHERE webhook_id = $1`
+		_, err = adm.skylb.DB.Exec(query, webhookID)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Toggled webhook %d", webhookID))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ListWebhooksTest synchronously sends a ping event to the webhook, so that
+// admins can check that their receiver is set up correctly.
+func (adm Admins) ListWebhooksTest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		webhookID, err := urlparams.Int(r, "webhookID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		wh := tables.WEBHOOKS()
+		var webhook skylab.Webhook
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(wh).
+			Where(wh.WEBHOOK_ID.EqInt(webhookID)).
+			SelectRowx(webhook.RowMapper(wh)).
+			Fetch(adm.skylb.DB)
+		if err != nil {
+			adm.skylb.InternalServerError(w, r, err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+		resp, err := webhooks.Ping(ctx, webhook)
+		switch {
+		case err != nil:
+			msgs[flash.Error] = append(msgs[flash.Error], fmt.Sprintf("Could not reach %s: %s", webhook.URL, err.Error()))
+		case !resp.OK():
+			msgs[flash.Error] = append(msgs[flash.Error], fmt.Sprintf("%s responded with %d: %s", webhook.URL, resp.StatusCode, resp.Body))
+		default:
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("%s responded with %d", webhook.URL, resp.StatusCode))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,113 @@
+package admins
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+func (adm Admins) PasswordLogin(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminPasswordLogin)
+	type Data struct {
+		Roles        []string
+		EnabledRoles map[string]bool
+	}
+	data := Data{
+		Roles:        skylab.Roles(),
+		EnabledRoles: make(map[string]bool),
+	}
+	roles, err := adm.skylb.PasswordLoginRoles()
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	for _, role := range roles {
+		data.EnabledRoles[role] = true
+	}
+	funcs := template.FuncMap{}
+	adm.skylb.Render(w, r, data, funcs, "app/admins/password_login.html")
+}
+
+func (adm Admins) PasswordLoginUpdate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		roles := r.Form["roles"]
+		err := adm.skylb.SetPasswordLoginRoles(roles)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else if len(roles) == 0 {
+			msgs[flash.Success] = append(msgs[flash.Success], "Password login disabled for all roles")
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], "Password login enabled for "+strings.Join(roles, ", "))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserSendPasswordEmail emails the user a link to set their password, so that
+// users without an NUS or Google account can login.
+func (adm Admins) UserSendPasswordEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		userID, err := urlparams.Int(r, "userID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		var user skylab.User
+		u := tables.USERS()
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(u).
+			Where(u.USER_ID.EqInt(userID)).
+			SelectRowx(func(row *sq.Row) {
+				user.Valid = row.IntValid(u.USER_ID)
+				user.UserID = row.Int(u.USER_ID)
+				user.Displayname = row.String(u.DISPLAYNAME)
+				user.Email = row.String(u.EMAIL)
+			}).
+			Fetch(adm.skylb.DB)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				adm.skylb.BadRequest(w, r, fmt.Sprintf("No such user found for userID %d", userID))
+			default:
+				adm.skylb.InternalServerError(w, r, err)
+			}
+			return
+		}
+		allowed, err := adm.skylb.PasswordLoginAllowed(userID)
+		if err != nil {
+			adm.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !allowed {
+			msgs[flash.Warning] = append(msgs[flash.Warning], fmt.Sprintf(
+				`None of %s's roles are allowed to use password login, they will not be able to login until it is enabled in <a href="%s">Password Login</a>`,
+				user.Email, skylab.AdminPasswordLogin,
+			))
+		}
+		err = adm.skylb.SendPasswordEmail(user, skylab.PasswordTokenPurposeSet)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], "Sent a set password link to "+user.Email)
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,102 @@
+package admins
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/helpers/timeutil"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// PeerReviewSettings shows the peer review settings of an evaluation period
+// for editing.
+func (adm Admins) PeerReviewSettings(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminListPeriods)
+	headers.DoNotCache(w)
+	periodID, err := urlparams.Int(r, "periodID")
+	if err != nil {
+		adm.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	type Data struct {
+		Period      skylab.Period
+		Settings    skylab.PeerReviewSettings
+		ReleaseDate string // release_at in Singapore time, for the date input
+		ReleaseTime string // release_at in Singapore time, for the time input
+	}
+	var data Data
+	p := tables.PERIODS()
+	err = sq.WithDefaultLog(sq.Lstats).
+		From(p).
+		Where(p.PERIOD_ID.EqInt(periodID)).
+		SelectRowx((&data.Period).RowMapper(p)).
+		Fetch(adm.skylb.DB)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			adm.skylb.BadRequest(w, r, fmt.Sprintf("No period found for periodID %d", periodID))
+		default:
+			adm.skylb.InternalServerError(w, r, err)
+		}
+		return
+	}
+	if data.Period.Stage != skylab.StageEvaluation {
+		adm.skylb.BadRequest(w, r, fmt.Sprintf("Period %d is not an evaluation period", periodID))
+		return
+	}
+	data.Settings, err = adm.skylb.PeerReviewSettingsByPeriod(periodID)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if data.Settings.ReleaseAt.Valid {
+		singapore, err := time.LoadLocation("Asia/Singapore")
+		if err != nil {
+			panic(err)
+		}
+		releaseAt := data.Settings.ReleaseAt.Time.In(singapore)
+		data.ReleaseDate = releaseAt.Format("2006-01-02")
+		data.ReleaseTime = releaseAt.Format("15:04")
+	}
+	adm.skylb.Render(w, r, data, nil, "app/admins/peer_review.html")
+}
+
+// PeerReviewSettingsUpdate saves the posted peer review settings of an
+// evaluation period. A blank release date releases evaluations as soon as they
+// are made.
+func (adm Admins) PeerReviewSettingsUpdate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		periodID, err := urlparams.Int(r, "periodID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		_ = formutil.ParseForm(r)
+		settings := skylab.PeerReviewSettings{
+			PeriodID:              periodID,
+			Anonymous:             r.FormValue("anonymous") == "true",
+			RevealAfterSubmission: r.FormValue("reveal_after_submission") == "true",
+			ReleaseAt:             timeutil.ParseDateTimeString(r.FormValue("release_date"), r.FormValue("release_time")),
+		}
+		err = adm.skylb.SavePeerReviewSettings(settings)
+		if err != nil {
+			msgs[flash.Error] = []string{err.Error()}
+		} else {
+			msgs[flash.Success] = []string{"Peer review settings saved"}
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
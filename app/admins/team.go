@@ -28,6 +28,18 @@ func (adm Admins) TeamView(w http.ResponseWriter, r *http.Request) {
 		adm.skylb.InternalServerError(w, r, err)
 		return
 	}
+	tm := tables.TEAM_MEETINGS()
+	data.Meetings, err = adm.skylb.TeamMeetings(tm.TEAM_ID.EqInt(teamID))
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	tsh := tables.TEAM_STATUS_HISTORY()
+	data.StatusHistory, err = adm.skylb.TeamStatusHistory(tsh.TEAM_ID.EqInt(teamID))
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
 	data.UserBaseURL = skylab.AdminUser
-	adm.skylb.Render(w, r, data, nil, "app/skylab/team_view.html")
+	adm.skylb.Render(w, r, data, nil, "app/skylab/team_view.html", "app/skylab/team_meetings.html")
 }
@@ -177,3 +177,25 @@ func (adm Admins) UserPreviewAs(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// UserRevokeSessions logs the user out everywhere by revoking all of their
+// sessions, e.g. when their cookie is suspected to have been stolen.
+func (adm Admins) UserRevokeSessions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		userID, err := urlparams.Int(r, "userID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		revoked, err := adm.skylb.RevokeAllSessions(userID)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Revoked %d sessions of user %d", revoked, userID))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,86 @@
+package admins
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/app/webhooks"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+func (adm Admins) WebhookView(w http.ResponseWriter, r *http.Request) {
+	adm.skylb.Log.TraceRequest(r)
+	r = adm.skylb.SetRoleSection(w, r, skylab.RoleAdmin, skylab.AdminListWebhooks)
+	webhookID, err := urlparams.Int(r, "webhookID")
+	if err != nil {
+		adm.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	type Data struct {
+		Webhook    skylab.Webhook
+		Deliveries []skylab.WebhookDelivery
+	}
+	var data Data
+	wh := tables.WEBHOOKS()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(wh).
+		Where(wh.WEBHOOK_ID.EqInt(webhookID)).
+		SelectRowx((&data.Webhook).RowMapper(wh)).
+		Fetch(adm.skylb.DB)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.Deliveries, err = webhooks.ListDeliveries(adm.skylb, webhookID, 100)
+	if err != nil {
+		adm.skylb.InternalServerError(w, r, err)
+		return
+	}
+	funcs := template.FuncMap{}
+	adm.skylb.Render(w, r, data, funcs, "app/admins/webhook.html")
+}
+
+// WebhookRetry reschedules a delivery to be attempted again on the next tick
+// of the dispatcher, regardless of whether it had failed or succeeded.
+func (adm Admins) WebhookRetry(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adm.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		webhookID, err := urlparams.Int(r, "webhookID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		deliveryID, err := urlparams.Int(r, "deliveryID")
+		if err != nil {
+			adm.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		wd := tables.WEBHOOK_DELIVERIES()
+		_, err = sq.WithDefaultLog(sq.Lverbose).
+			Update(wd).
+			Set(
+				wd.STATUS.SetString(skylab.WebhookDeliveryStatusPending),
+				wd.ATTEMPTS.SetInt(0),
+				wd.NEXT_ATTEMPT_AT.SetTime(time.Now()),
+			).
+			Where(
+				wd.WEBHOOK_DELIVERY_ID.EqInt(deliveryID),
+				wd.WEBHOOK_ID.EqInt(webhookID),
+			).
+			Exec(adm.skylb.DB, 0)
+		if err != nil {
+			msgs[flash.Error] = append(msgs[flash.Error], err.Error())
+		} else {
+			msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf("Delivery %d will be retried shortly", deliveryID))
+		}
+		r, _ = adm.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,215 @@
+package advisers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// evaluationPairs returns the teams advised by the user together with the
+// team_evaluation_pairs between them.
+func (adv Advisers) evaluationPairs(user skylab.User) (teams []skylab.Team, pairs []skylab.EvaluationPair, err error) {
+	t := tables.V_TEAMS()
+	team := &skylab.Team{}
+	err = sq.WithDefaultLog(sq.Lstats).
+		From(t).
+		Where(t.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser])).
+		OrderBy(t.TEAM_NAME, t.TEAM_ID).
+		Selectx(team.RowMapper(t), func() { teams = append(teams, *team) }).
+		Fetch(adv.skylb.DB)
+	if err != nil {
+		return teams, pairs, erro.Wrap(err)
+	}
+	teamIDs := make([]int, len(teams))
+	teamIndex := make(map[int]int)
+	for i, team := range teams {
+		teamIDs[i] = team.TeamID
+		teamIndex[team.TeamID] = i
+	}
+	if len(teamIDs) == 0 {
+		return teams, pairs, nil
+	}
+	tp := tables.TEAM_EVALUATION_PAIRS()
+	var evaluateeTeamID, evaluatorTeamID int
+	err = sq.WithDefaultLog(sq.Lstats).
+		From(tp).
+		Where(
+			tp.EVALUATEE_TEAM_ID.In(teamIDs),
+			tp.EVALUATOR_TEAM_ID.In(teamIDs),
+		).
+		Selectx(func(row *sq.Row) {
+			evaluateeTeamID = row.Int(tp.EVALUATEE_TEAM_ID)
+			evaluatorTeamID = row.Int(tp.EVALUATOR_TEAM_ID)
+		}, func() {
+			pairs = append(pairs, skylab.EvaluationPair{
+				Evaluatee: teams[teamIndex[evaluateeTeamID]],
+				Evaluator: teams[teamIndex[evaluatorTeamID]],
+			})
+		}).
+		Fetch(adv.skylb.DB)
+	if err != nil {
+		return teams, pairs, erro.Wrap(err)
+	}
+	// Order the pairs the same way the teams are ordered
+	sort.SliceStable(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		if teamIndex[a.Evaluatee.TeamID] != teamIndex[b.Evaluatee.TeamID] {
+			return teamIndex[a.Evaluatee.TeamID] < teamIndex[b.Evaluatee.TeamID]
+		}
+		return teamIndex[a.Evaluator.TeamID] < teamIndex[b.Evaluator.TeamID]
+	})
+	return teams, pairs, nil
+}
+
+// EvaluationPairsExport downloads the evaluation pairs between the teams
+// advised by the user as a CSV file of team names, see
+// skylab.WriteEvaluationPairsCSV.
+func (adv Advisers) EvaluationPairsExport(w http.ResponseWriter, r *http.Request) {
+	adv.skylb.Log.TraceRequest(r)
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	_, pairs, err := adv.evaluationPairs(user)
+	if err != nil {
+		adv.skylb.InternalServerError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("evaluation-pairs-%d.csv", user.UserID)))
+	err = skylab.WriteEvaluationPairsCSV(w, pairs)
+	if err != nil {
+		adv.skylb.Log.Printf("unable to export the evaluation pairs of user %d: %s", user.UserID, err)
+	}
+}
+
+// importedPairsCSV returns the CSV posted to the import page, either as an
+// uploaded file or pasted into the csv textarea.
+func importedPairsCSV(r *http.Request) (string, error) {
+	_ = formutil.ParseForm(r)
+	file, _, err := r.FormFile("csv_file")
+	if err != nil {
+		if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+			return r.FormValue("csv"), nil
+		}
+		return "", erro.Wrap(err)
+	}
+	defer file.Close()
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	return string(b), nil
+}
+
+// EvaluationPairsImport previews the changes that importing the posted CSV of
+// evaluation pairs would make: the rows that were rejected, and the pairs that
+// will be added and removed. The pairs between the adviser's teams that are
+// not in the CSV are removed.
+func (adv Advisers) EvaluationPairsImport(w http.ResponseWriter, r *http.Request) {
+	adv.skylb.Log.TraceRequest(r)
+	r = adv.skylb.SetRoleSection(w, r, skylab.RoleAdviser, skylab.AdviserEvaluateeEvaluators)
+	headers.DoNotCache(w)
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	type Data struct {
+		CSV      string
+		Problems []string
+		Added    []skylab.EvaluationPair
+		Removed  []skylab.EvaluationPair
+	}
+	var data Data
+	var err error
+	data.CSV, err = importedPairsCSV(r)
+	if err != nil {
+		adv.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	teams, current, err := adv.evaluationPairs(user)
+	if err != nil {
+		adv.skylb.InternalServerError(w, r, err)
+		return
+	}
+	var wanted []skylab.EvaluationPair
+	wanted, data.Problems = skylab.ParseEvaluationPairsCSV(strings.NewReader(data.CSV), teams)
+	data.Added, data.Removed = skylab.DiffEvaluationPairs(current, wanted)
+	adv.skylb.Render(w, r, data, nil, "app/advisers/evaluation_pairs_import.html")
+}
+
+// EvaluationPairsImportApply replaces the evaluation pairs between the
+// adviser's teams with the pairs in the posted CSV. Nothing is changed if any
+// row of the CSV is rejected.
+func (adv Advisers) EvaluationPairsImportApply(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adv.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		msgs := make(map[string][]string)
+		teams, current, err := adv.evaluationPairs(user)
+		if err != nil {
+			adv.skylb.InternalServerError(w, r, err)
+			return
+		}
+		wanted, problems := skylab.ParseEvaluationPairsCSV(strings.NewReader(r.FormValue("csv")), teams)
+		if len(problems) > 0 {
+			msgs[flash.Error] = []string{"Evaluation pairs not imported: " + strings.Join(problems, "; ")}
+			r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		added, removed := skylab.DiffEvaluationPairs(current, wanted)
+		err = adv.applyEvaluationPairs(added, removed)
+		if err != nil {
+			adv.skylb.InternalServerError(w, r, err)
+			return
+		}
+		msgs[flash.Success] = []string{fmt.Sprintf("%d pairings added, %d pairings removed", len(added), len(removed))}
+		r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (adv Advisers) applyEvaluationPairs(added, removed []skylab.EvaluationPair) error {
+	tx, err := adv.skylb.DB.Begin()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	tp := tables.TEAM_EVALUATION_PAIRS()
+	if len(added) > 0 {
+		var values sq.RowValues
+		for _, pair := range added {
+			values = append(values, []interface{}{pair.Evaluatee.TeamID, pair.Evaluator.TeamID})
+		}
+		_, err = sq.InsertQuery{
+			Log:           adv.skylb.Log,
+			LogFlag:       sq.Lstats,
+			IntoTable:     tp,
+			InsertColumns: []sq.Field{tp.EVALUATEE_TEAM_ID, tp.EVALUATOR_TEAM_ID},
+			RowValues:     values,
+		}.OnConflict().DoNothing().Exec(tx, 0)
+		if err != nil {
+			_ = tx.Rollback()
+			return erro.Wrap(err)
+		}
+	}
+	if len(removed) > 0 {
+		var fields sq.Fields
+		for _, pair := range removed {
+			fields = append(fields, sq.Fieldf("(?, ?)", pair.Evaluatee.TeamID, pair.Evaluator.TeamID))
+		}
+		_, err = sq.WithDefaultLog(sq.Lstats).
+			DeleteFrom(tp).
+			Where(sq.RowValue{tp.EVALUATEE_TEAM_ID, tp.EVALUATOR_TEAM_ID}.In(fields)).
+			Exec(tx, 0)
+		if err != nil {
+			_ = tx.Rollback()
+			return erro.Wrap(err)
+		}
+	}
+	return erro.Wrap(tx.Commit())
+}
@@ -1,13 +1,278 @@
 package advisers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/tables"
 )
 
+// Teams lists the teams of the current cohort advised by the user along with
+// the meetings the user logged with each of them and their status history.
 func (adv Advisers) Teams(w http.ResponseWriter, r *http.Request) {
 	adv.skylb.Log.TraceRequest(r)
 	r = adv.skylb.SetRoleSection(w, r, skylab.RoleAdviser, skylab.AdviserTeams)
-	adv.skylb.Render(w, r, nil, nil, "app/advisers/teams.html")
+	headers.DoNotCache(w)
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	type AdvisedTeam struct {
+		Team          skylab.Team
+		Meetings      []skylab.TeamMeeting
+		NewMeeting    skylab.TeamMeeting // prefills the form for logging a new meeting
+		StatusHistory []skylab.TeamStatusChange
+	}
+	type Data struct {
+		Teams []AdvisedTeam
+	}
+	var data Data
+	// teamIndex maps a teamID to its index in data.Teams
+	teamIndex := make(map[int]int)
+	today := time.Now()
+	t := tables.V_TEAMS()
+	team := &skylab.Team{}
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(t).
+		Where(
+			t.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser]),
+			t.COHORT.EqString(adv.skylb.CurrentCohort()),
+		).
+		OrderBy(t.TEAM_ID).
+		Selectx(team.RowMapper(t), func() {
+			teamIndex[team.TeamID] = len(data.Teams)
+			data.Teams = append(data.Teams, AdvisedTeam{
+				Team:       *team,
+				NewMeeting: skylab.TeamMeeting{Team: *team, MeetingDate: today},
+			})
+		}).
+		Fetch(adv.skylb.DB)
+	if err != nil {
+		adv.skylb.InternalServerError(w, r, err)
+		return
+	}
+	tm := tables.TEAM_MEETINGS()
+	meetings, err := adv.skylb.TeamMeetings(tm.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser]))
+	if err != nil {
+		adv.skylb.InternalServerError(w, r, err)
+		return
+	}
+	for _, meeting := range meetings {
+		i, ok := teamIndex[meeting.Team.TeamID]
+		if !ok {
+			continue // a team of a previous cohort
+		}
+		// The meeting edit form needs the students of the team
+		meeting.Team = data.Teams[i].Team
+		data.Teams[i].Meetings = append(data.Teams[i].Meetings, meeting)
+	}
+	tt := tables.TEAMS()
+	history, err := adv.skylb.TeamStatusHistory(
+		tt.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser]),
+		tt.COHORT.EqString(adv.skylb.CurrentCohort()),
+	)
+	if err != nil {
+		adv.skylb.InternalServerError(w, r, err)
+		return
+	}
+	for _, change := range history {
+		i, ok := teamIndex[change.TeamID]
+		if !ok {
+			continue
+		}
+		data.Teams[i].StatusHistory = append(data.Teams[i].StatusHistory, change)
+	}
+	adv.skylb.Render(w, r, data, nil, "app/advisers/teams.html")
+}
+
+// TeamMeetingSave logs the posted meeting with one of the adviser's teams, or
+// updates it if a team_meeting_id is posted. Students of the team whose
+// user_role_id is among the posted attended values are marked as present.
+func (adv Advisers) TeamMeetingSave(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adv.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		msgs := make(map[string][]string)
+		_ = formutil.ParseForm(r)
+		teamID, err := strconv.Atoi(r.FormValue("team_id"))
+		if err != nil {
+			adv.skylb.BadRequest(w, r, fmt.Sprintf("Invalid team_id: %s", r.FormValue("team_id")))
+			return
+		}
+		var teamMeetingID int
+		if value := r.FormValue("team_meeting_id"); value != "" {
+			teamMeetingID, err = strconv.Atoi(value)
+			if err != nil {
+				adv.skylb.BadRequest(w, r, fmt.Sprintf("Invalid team_meeting_id: %s", value))
+				return
+			}
+		}
+		meetingDate, err := time.ParseInLocation(skylab.TeamMeetingDateLayout, r.FormValue("meeting_date"), time.UTC)
+		if err != nil {
+			msgs[flash.Error] = []string{"Please enter the date of the meeting"}
+			r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		var team skylab.Team
+		t := tables.V_TEAMS()
+		err = sq.WithDefaultLog(sq.Lstats).
+			From(t).
+			Where(
+				t.TEAM_ID.EqInt(teamID),
+				t.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser]),
+			).
+			SelectRowx((&team).RowMapper(t)).
+			Fetch(adv.skylb.DB)
+		if err != nil {
+			adv.skylb.NotAuthorized(w, r)
+			return
+		}
+		meeting := skylab.TeamMeeting{
+			TeamMeetingID:     teamMeetingID,
+			Team:              team,
+			AdviserUserRoleID: user.Roles[skylab.RoleAdviser],
+			MeetingDate:       meetingDate,
+			Notes:             strings.TrimSpace(r.FormValue("notes")),
+			ActionItems:       strings.TrimSpace(r.FormValue("action_items")),
+		}
+		for _, student := range team.Students() {
+			studentUserRoleID := student.Roles[skylab.RoleStudent]
+			meeting.Attendance = append(meeting.Attendance, skylab.MeetingAttendance{
+				StudentUserRoleID: studentUserRoleID,
+				Attended:          skylab.Contains(r.Form["attended"], strconv.Itoa(studentUserRoleID)),
+			})
+		}
+		_, err = adv.skylb.SaveTeamMeeting(meeting)
+		if err != nil {
+			if !erro.Is(err, skylab.ErrTeamMeetingNotExist, skylab.ErrTeamNotAdvised) {
+				adv.skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs[flash.Error] = []string{err.Error()}
+		} else {
+			msgs[flash.Success] = []string{fmt.Sprintf("Meeting with %s on %s saved", team.TeamName, meetingDate.Format("2 Jan 2006"))}
+		}
+		r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TeamMeetingDelete deletes the meeting with the posted team_meeting_id from
+// the adviser's meeting log.
+func (adv Advisers) TeamMeetingDelete(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adv.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		msgs := make(map[string][]string)
+		teamMeetingID, err := strconv.Atoi(r.FormValue("team_meeting_id"))
+		if err != nil {
+			adv.skylb.BadRequest(w, r, fmt.Sprintf("Invalid team_meeting_id: %s", r.FormValue("team_meeting_id")))
+			return
+		}
+		err = adv.skylb.DeleteTeamMeeting(teamMeetingID, user.Roles[skylab.RoleAdviser])
+		if err != nil {
+			if !erro.Is(err, skylab.ErrTeamMeetingNotExist) {
+				adv.skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs[flash.Error] = []string{err.Error()}
+		} else {
+			msgs[flash.Success] = []string{"Meeting deleted"}
+		}
+		r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TeamStatusUpdate sets the status of one of the adviser's teams to the posted
+// status for the posted reason. The admins of the team's cohort are emailed if
+// the team becomes uncontactable.
+func (adv Advisers) TeamStatusUpdate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adv.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		msgs := make(map[string][]string)
+		teamID, err := strconv.Atoi(r.FormValue("team_id"))
+		if err != nil {
+			adv.skylb.BadRequest(w, r, fmt.Sprintf("Invalid team_id: %s", r.FormValue("team_id")))
+			return
+		}
+		var team skylab.Team
+		t := tables.V_TEAMS()
+		err = sq.WithDefaultLog(sq.Lstats).
+			From(t).
+			Where(
+				t.TEAM_ID.EqInt(teamID),
+				t.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser]),
+			).
+			SelectRowx((&team).RowMapper(t)).
+			Fetch(adv.skylb.DB)
+		if err != nil {
+			adv.skylb.NotAuthorized(w, r)
+			return
+		}
+		change := skylab.TeamStatusChange{
+			TeamID:    teamID,
+			NewStatus: r.FormValue("status"),
+			Reason:    strings.TrimSpace(r.FormValue("reason")),
+			ChangedBy: user,
+		}
+		change.OldStatus, err = adv.skylb.SetTeamStatus(teamID, change.NewStatus, change.Reason, user.UserID)
+		if err != nil {
+			if !erro.Is(err, skylab.ErrTeamStatusInvalid, skylab.ErrTeamStatusReasonEmpty) {
+				adv.skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs[flash.Error] = []string{err.Error()}
+			r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if change.OldStatus == change.NewStatus {
+			msgs[flash.Warning] = []string{fmt.Sprintf("%s is already %s", team.TeamName, change.NewStatus)}
+			r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		msgs[flash.Success] = []string{fmt.Sprintf("%s is now %s", team.TeamName, change.NewStatus)}
+		if change.NewStatus == skylab.TeamStatusUncontactable {
+			err = adv.skylb.SendTeamUncontactableEmail(team, change)
+			if err != nil {
+				adv.skylb.Log.Printf("unable to email the admins that team %d is uncontactable: %s", teamID, err)
+				msgs[flash.Warning] = []string{"The admins could not be emailed that the team is uncontactable"}
+			}
+		}
+		r, _ = adv.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TeamMeetingsExport downloads the adviser's meeting log of the current cohort
+// as a CSV file, see skylab.WriteTeamMeetingsCSV.
+func (adv Advisers) TeamMeetingsExport(w http.ResponseWriter, r *http.Request) {
+	adv.skylb.Log.TraceRequest(r)
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	cohort := adv.skylb.CurrentCohort()
+	tm, t := tables.TEAM_MEETINGS(), tables.TEAMS()
+	meetings, err := adv.skylb.TeamMeetings(
+		tm.ADVISER_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleAdviser]),
+		t.COHORT.EqString(cohort),
+	)
+	if err != nil {
+		adv.skylb.InternalServerError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("meetings-%s-%d.csv", cohort, user.UserID)))
+	err = skylab.WriteTeamMeetingsCSV(w, meetings)
+	if err != nil {
+		adv.skylb.Log.Printf("unable to export the meeting log of user %d: %s", user.UserID, err)
+	}
 }
@@ -0,0 +1,227 @@
+// Package api implements the versioned JSON API on Skylab. Every resource
+// lives under the Prefix path (i.e. /api/v1) and always responds with JSON,
+// including errors. See docs/api.md for the list of available resources.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/cookies"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+)
+
+// Prefix is the path prefix that every version 1 API route is mounted on.
+// Breaking changes to any resource should go into a new /api/v2 instead.
+const Prefix = "/api/v1"
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+type API struct {
+	skylb skylab.Skylab
+}
+
+func New(skylb skylab.Skylab) API {
+	return API{skylb: skylb}
+}
+
+// Meta contains the pagination details of a list response.
+type Meta struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	Total   int `json:"total"`
+}
+
+// listResponse is the envelope for every response that returns a list of
+// resources.
+type listResponse struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+// itemResponse is the envelope for every response that returns a single
+// resource.
+type itemResponse struct {
+	Data interface{} `json:"data"`
+}
+
+// errorResponse is the envelope for every error response. Clients should
+// always be able to rely on error.status and error.message being present.
+type errorResponse struct {
+	Error struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// writeJSON marshals v into JSON and writes it out to w with the given status
+// code.
+func (api API) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	headers.DoNotCache(w)
+	b, err := json.Marshal(v)
+	if err != nil {
+		api.Error(w, r, http.StatusInternalServerError, erro.Wrap(err).Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// Error writes out a JSON error body with the given status code and message.
+func (api API) Error(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	var resp errorResponse
+	resp.Error.Status = status
+	resp.Error.Message = msg
+	b, _ := json.Marshal(resp)
+	headers.DoNotCache(w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// BadRequest writes out a 400 JSON error.
+func (api API) BadRequest(w http.ResponseWriter, r *http.Request, msg string) {
+	api.Error(w, r, http.StatusBadRequest, msg)
+}
+
+// InternalServerError logs err and writes out a 500 JSON error. The actual
+// error is only shown to the client in development.
+func (api API) InternalServerError(w http.ResponseWriter, r *http.Request, err error) {
+	api.skylb.Log.Printf("%s %s: %+v", r.Method, r.URL.Path, err)
+	msg := http.StatusText(http.StatusInternalServerError)
+	if !api.skylb.IsProd {
+		msg = err.Error()
+	}
+	api.Error(w, r, http.StatusInternalServerError, msg)
+}
+
+// NotFound writes out a 404 JSON error. It is also used as the catch-all
+// handler for unknown routes under Prefix.
+func (api API) NotFound(w http.ResponseWriter, r *http.Request) {
+	api.Error(w, r, http.StatusNotFound, fmt.Sprintf("%s not found", r.URL.Path))
+}
+
+// EnsureAdmin only lets the request through if the current user (or the
+// currently logged in admin) has the admin role. Requests authenticated with
+// a read-only API token may only use safe methods like GET, and requests
+// authenticated by the session cookie must have passed two-factor
+// authentication. It must be used after skylb.GetSession or skylb.BearerToken.
+func (api API) EnsureAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		admin, _ := r.Context().Value(skylab.ContextAdmin).(skylab.User)
+		apiToken, _ := r.Context().Value(skylab.ContextAPIToken).(skylab.APIToken)
+		if !user.Valid && !admin.Valid {
+			if r.Header.Get("Authorization") != "" {
+				api.Error(w, r, http.StatusUnauthorized, "API token is invalid or has expired")
+				return
+			}
+			api.Error(w, r, http.StatusUnauthorized, "not logged in")
+			return
+		}
+		if apiToken.Valid && apiToken.ReadOnly {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				api.Error(w, r, http.StatusForbidden, "API token is read-only")
+				return
+			}
+		}
+		if user.Roles[skylab.RoleAdmin] == 0 && !admin.Valid {
+			api.Error(w, r, http.StatusForbidden, "admin role required")
+			return
+		}
+		// Requests authenticated by the session cookie must have passed
+		// two-factor authentication, the same as RequireAdminTOTP does for the
+		// admin pages
+		if !apiToken.Valid {
+			cookieName := skylab.SessionCookieName
+			if admin.Valid {
+				cookieName = skylab.AdminSessionCookieName
+			}
+			verified, err := api.skylb.SessionIsTOTPVerified(cookies.GetCookieValue(r, cookieName))
+			if err != nil {
+				api.InternalServerError(w, r, err)
+				return
+			}
+			if !verified {
+				api.Error(w, r, http.StatusForbidden, "two-factor authentication required")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pagination parses the page and per_page query parameters from the request.
+// page starts from 1.
+func pagination(r *http.Request) (meta Meta, err error) {
+	meta.Page, meta.PerPage = 1, defaultPerPage
+	if s := r.FormValue("page"); s != "" {
+		meta.Page, err = strconv.Atoi(s)
+		if err != nil || meta.Page < 1 {
+			return meta, fmt.Errorf("page must be a positive integer, got '%s'", s)
+		}
+	}
+	if s := r.FormValue("per_page"); s != "" {
+		meta.PerPage, err = strconv.Atoi(s)
+		if err != nil || meta.PerPage < 1 || meta.PerPage > maxPerPage {
+			return meta, fmt.Errorf("per_page must be an integer between 1 and %d, got '%s'", maxPerPage, s)
+		}
+	}
+	return meta, nil
+}
+
+func (meta Meta) offset() int {
+	return (meta.Page - 1) * meta.PerPage
+}
+
+// filters parses the cohort and milestone query parameters from the request.
+// Empty values mean no filtering is done for that parameter.
+func (api API) filters(r *http.Request) (cohort, milestone string, err error) {
+	cohort, milestone = r.FormValue("cohort"), r.FormValue("milestone")
+	if cohort != "" && !skylab.Contains(api.skylb.Cohorts(), cohort) {
+		return cohort, milestone, fmt.Errorf("cohort '%s' does not exist", cohort)
+	}
+	if milestone != "" && !skylab.Contains(skylab.Milestones(), milestone) {
+		return cohort, milestone, fmt.Errorf("milestone '%s' is invalid, valid milestones are %v", milestone, skylab.Milestones())
+	}
+	return cohort, milestone, nil
+}
+
+// queryInt parses an optional integer query parameter from the request. ok is
+// false if the parameter is absent.
+func queryInt(r *http.Request, key string) (value int, ok bool, err error) {
+	s := r.FormValue(key)
+	if s == "" {
+		return 0, false, nil
+	}
+	value, err = strconv.Atoi(s)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s must be an integer, got '%s'", key, s)
+	}
+	return value, true, nil
+}
+
+// count returns the total number of rows in the table matching the
+// predicates, used for populating Meta.Total.
+func (api API) count(table sq.Table, predicates []sq.Predicate) (total int, err error) {
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(table).
+		Where(predicates...).
+		SelectRowx(func(row *sq.Row) {
+			total = row.Int(sq.Count())
+		}).
+		Fetch(api.skylb.DB)
+	return total, erro.Wrap(err)
+}
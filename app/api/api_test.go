@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPagination(t *testing.T) {
+	type TT struct {
+		query   string
+		wantErr bool
+		want    Meta
+	}
+	tests := []TT{
+		{query: "", want: Meta{Page: 1, PerPage: defaultPerPage}},
+		{query: "page=3&per_page=20", want: Meta{Page: 3, PerPage: 20}},
+		{query: "page=0", wantErr: true},
+		{query: "page=abc", wantErr: true},
+		{query: "per_page=0", wantErr: true},
+		{query: "per_page=1000", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.query, func(t *testing.T) {
+			is := is.New(t)
+			r := httptest.NewRequest("GET", "/api/v1/teams?"+tt.query, nil)
+			meta, err := pagination(r)
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(meta, tt.want)
+		})
+	}
+}
+
+func TestMeta_offset(t *testing.T) {
+	is := is.New(t)
+	is.Equal(Meta{Page: 1, PerPage: 50}.offset(), 0)
+	is.Equal(Meta{Page: 3, PerPage: 20}.offset(), 40)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ListApplications lists applications, optionally filtered by cohort,
+// project level and status. Deleted applications are excluded unless
+// status=deleted is passed in.
+//
+// GET /api/v1/applications?cohort=&project_level=&status=&page=&per_page=
+func (api API) ListApplications(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, _, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	apps := tables.V_APPLICATIONS()
+	var predicates []sq.Predicate
+	if cohort != "" {
+		predicates = append(predicates, apps.COHORT.EqString(cohort))
+	}
+	if projectLevel := r.FormValue("project_level"); projectLevel != "" {
+		predicates = append(predicates, apps.PROJECT_LEVEL.EqString(projectLevel))
+	}
+	if status := r.FormValue("status"); status != "" {
+		predicates = append(predicates, apps.STATUS.EqString(status))
+	} else {
+		predicates = append(predicates, apps.STATUS.NeString(skylab.ApplicationStatusDeleted))
+	}
+	meta.Total, err = api.count(apps, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	applications := []skylab.Application{}
+	application := &skylab.Application{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(apps).
+		Where(predicates...).
+		OrderBy(apps.APPLICATION_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(application.RowMapper(apps), func() { applications = append(applications, *application) }).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: applications, Meta: meta})
+}
+
+// GetApplication gets a single application.
+//
+// GET /api/v1/applications/{applicationID}
+func (api API) GetApplication(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	applicationID, err := urlparams.Int(r, "applicationID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	apps := tables.V_APPLICATIONS()
+	application := &skylab.Application{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(apps).
+		Where(apps.APPLICATION_ID.EqInt(applicationID)).
+		SelectRowx(application.RowMapper(apps)).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: application})
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+)
+
+// ListCohorts lists every cohort in Skylab, in insertion order.
+//
+// GET /api/v1/cohorts
+func (api API) ListCohorts(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohorts := api.skylb.Cohorts()
+	meta.Total = len(cohorts)
+	start, end := meta.offset(), meta.offset()+meta.PerPage
+	if start > len(cohorts) {
+		start = len(cohorts)
+	}
+	if end > len(cohorts) {
+		end = len(cohorts)
+	}
+	data := make([]string, 0, end-start)
+	data = append(data, cohorts[start:end]...)
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: data, Meta: meta})
+}
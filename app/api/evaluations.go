@@ -0,0 +1,188 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ListTeamEvaluations lists evaluations done by teams on other teams,
+// optionally filtered by cohort, milestone, evaluator team and evaluatee team.
+//
+// GET /api/v1/team-evaluations?cohort=&milestone=&evaluator_team_id=&evaluatee_team_id=&page=&per_page=
+func (api API) ListTeamEvaluations(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, milestone, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	te := tables.V_TEAM_EVALUATIONS()
+	var predicates []sq.Predicate
+	if cohort != "" {
+		predicates = append(predicates, te.COHORT.EqString(cohort))
+	}
+	if milestone != "" {
+		predicates = append(predicates, te.MILESTONE.EqString(milestone))
+	}
+	if teamID, ok, err := queryInt(r, "evaluator_team_id"); err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	} else if ok {
+		predicates = append(predicates, te.EVALUATOR_TEAM_ID.EqInt(teamID))
+	}
+	if teamID, ok, err := queryInt(r, "evaluatee_team_id"); err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	} else if ok {
+		predicates = append(predicates, te.EVALUATEE_TEAM_ID.EqInt(teamID))
+	}
+	meta.Total, err = api.count(te, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	evaluations := []skylab.TeamEvaluation{}
+	evaluation := &skylab.TeamEvaluation{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(te).
+		Where(predicates...).
+		OrderBy(te.TEAM_EVALUATION_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(evaluation.RowMapper(te), func() { evaluations = append(evaluations, *evaluation) }).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: evaluations, Meta: meta})
+}
+
+// GetTeamEvaluation gets a single team evaluation.
+//
+// GET /api/v1/team-evaluations/{teamEvaluationID}
+func (api API) GetTeamEvaluation(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	teamEvaluationID, err := urlparams.Int(r, "teamEvaluationID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	te := tables.V_TEAM_EVALUATIONS()
+	evaluation := &skylab.TeamEvaluation{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(te).
+		Where(te.TEAM_EVALUATION_ID.EqInt(teamEvaluationID)).
+		SelectRowx(evaluation.RowMapper(te)).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: evaluation})
+}
+
+// ListUserEvaluations lists evaluations done by users (advisers and mentors)
+// on teams, optionally filtered by cohort, milestone, evaluator role,
+// evaluator user and evaluatee team.
+//
+// GET /api/v1/user-evaluations?cohort=&milestone=&role=&evaluator_user_id=&evaluatee_team_id=&page=&per_page=
+func (api API) ListUserEvaluations(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, milestone, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	ue := tables.V_USER_EVALUATIONS()
+	var predicates []sq.Predicate
+	if cohort != "" {
+		predicates = append(predicates, ue.COHORT.EqString(cohort))
+	}
+	if milestone != "" {
+		predicates = append(predicates, ue.MILESTONE.EqString(milestone))
+	}
+	if role := r.FormValue("role"); role != "" {
+		predicates = append(predicates, ue.EVALUATOR_ROLE.EqString(role))
+	}
+	if userID, ok, err := queryInt(r, "evaluator_user_id"); err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	} else if ok {
+		predicates = append(predicates, ue.EVALUATOR_USER_ID.EqInt(userID))
+	}
+	if teamID, ok, err := queryInt(r, "evaluatee_team_id"); err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	} else if ok {
+		predicates = append(predicates, ue.EVALUATEE_TEAM_ID.EqInt(teamID))
+	}
+	meta.Total, err = api.count(ue, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	evaluations := []skylab.UserEvaluation{}
+	evaluation := &skylab.UserEvaluation{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ue).
+		Where(predicates...).
+		OrderBy(ue.USER_EVALUATION_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(evaluation.RowMapper(ue), func() { evaluations = append(evaluations, *evaluation) }).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: evaluations, Meta: meta})
+}
+
+// GetUserEvaluation gets a single user evaluation.
+//
+// GET /api/v1/user-evaluations/{userEvaluationID}
+func (api API) GetUserEvaluation(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	userEvaluationID, err := urlparams.Int(r, "userEvaluationID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	ue := tables.V_USER_EVALUATIONS()
+	evaluation := &skylab.UserEvaluation{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ue).
+		Where(ue.USER_EVALUATION_ID.EqInt(userEvaluationID)).
+		SelectRowx(evaluation.RowMapper(ue)).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: evaluation})
+}
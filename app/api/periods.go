@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ListPeriods lists periods, optionally filtered by cohort, stage and
+// milestone.
+//
+// GET /api/v1/periods?cohort=&stage=&milestone=&page=&per_page=
+func (api API) ListPeriods(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, milestone, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	stage := r.FormValue("stage")
+	if stage != "" && !skylab.Contains(skylab.Stages(), stage) {
+		api.BadRequest(w, r, "stage '"+stage+"' is invalid")
+		return
+	}
+	p := tables.PERIODS()
+	var predicates []sq.Predicate
+	if cohort != "" {
+		predicates = append(predicates, p.COHORT.EqString(cohort))
+	}
+	if stage != "" {
+		predicates = append(predicates, p.STAGE.EqString(stage))
+	}
+	if milestone != "" {
+		predicates = append(predicates, p.MILESTONE.EqString(milestone))
+	}
+	meta.Total, err = api.count(p, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	periods := []skylab.Period{}
+	period := &skylab.Period{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(p).
+		Where(predicates...).
+		OrderBy(p.PERIOD_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(period.RowMapper(p), func() { periods = append(periods, *period) }).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: periods, Meta: meta})
+}
+
+// GetPeriod gets a single period.
+//
+// GET /api/v1/periods/{periodID}
+func (api API) GetPeriod(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	periodID, err := urlparams.Int(r, "periodID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	p := tables.PERIODS()
+	period := &skylab.Period{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(p).
+		Where(p.PERIOD_ID.EqInt(periodID)).
+		SelectRowx(period.RowMapper(p)).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: period})
+}
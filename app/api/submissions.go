@@ -0,0 +1,92 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ListSubmissions lists submissions, optionally filtered by cohort,
+// milestone and team.
+//
+// GET /api/v1/submissions?cohort=&milestone=&team_id=&page=&per_page=
+func (api API) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, milestone, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	s := tables.V_SUBMISSIONS()
+	var predicates []sq.Predicate
+	if cohort != "" {
+		predicates = append(predicates, s.COHORT.EqString(cohort))
+	}
+	if milestone != "" {
+		predicates = append(predicates, s.MILESTONE.EqString(milestone))
+	}
+	if teamID, ok, err := queryInt(r, "team_id"); err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	} else if ok {
+		predicates = append(predicates, s.TEAM_ID.EqInt(teamID))
+	}
+	meta.Total, err = api.count(s, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	submissions := []skylab.Submission{}
+	submission := &skylab.Submission{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(s).
+		Where(predicates...).
+		OrderBy(s.SUBMISSION_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(submission.RowMapper(s), func() { submissions = append(submissions, *submission) }).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: submissions, Meta: meta})
+}
+
+// GetSubmission gets a single submission.
+//
+// GET /api/v1/submissions/{submissionID}
+func (api API) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	submissionID, err := urlparams.Int(r, "submissionID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	s := tables.V_SUBMISSIONS()
+	submission := &skylab.Submission{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(s).
+		Where(s.SUBMISSION_ID.EqInt(submissionID)).
+		SelectRowx(submission.RowMapper(s)).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: submission})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ListTeams lists teams, optionally filtered by cohort, project level and
+// status.
+//
+// GET /api/v1/teams?cohort=&project_level=&status=&page=&per_page=
+func (api API) ListTeams(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, _, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	t := tables.V_TEAMS()
+	var predicates []sq.Predicate
+	if cohort != "" {
+		predicates = append(predicates, t.COHORT.EqString(cohort))
+	}
+	if projectLevel := r.FormValue("project_level"); projectLevel != "" {
+		predicates = append(predicates, t.PROJECT_LEVEL.EqString(projectLevel))
+	}
+	if status := r.FormValue("status"); status != "" {
+		predicates = append(predicates, t.STATUS.EqString(status))
+	}
+	meta.Total, err = api.count(t, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	teams := []skylab.Team{}
+	team := &skylab.Team{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(t).
+		Where(predicates...).
+		OrderBy(t.TEAM_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(team.RowMapper(t), func() { teams = append(teams, *team) }).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: teams, Meta: meta})
+}
+
+// GetTeam gets a single team.
+//
+// GET /api/v1/teams/{teamID}
+func (api API) GetTeam(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	teamID, err := urlparams.Int(r, "teamID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	t := tables.V_TEAMS()
+	team := &skylab.Team{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(t).
+		Where(t.TEAM_ID.EqInt(teamID)).
+		SelectRowx(team.RowMapper(t)).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: team})
+}
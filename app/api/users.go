@@ -0,0 +1,164 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ListUsers lists users, optionally filtered by the cohort and role they
+// hold. Each user's Roles only contain the roles matching the filters.
+//
+// GET /api/v1/users?cohort=&role=&page=&per_page=
+func (api API) ListUsers(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	meta, err := pagination(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	cohort, _, err := api.filters(r)
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	role := r.FormValue("role")
+	if role != "" && !skylab.Contains(skylab.Roles(), role) {
+		api.BadRequest(w, r, "role '"+role+"' is invalid")
+		return
+	}
+	u, ur := tables.USERS(), tables.USER_ROLES()
+	var rolePredicates []sq.Predicate
+	rolePredicates = append(rolePredicates, ur.USER_ID.Eq(u.USER_ID))
+	if cohort != "" {
+		rolePredicates = append(rolePredicates, ur.COHORT.EqString(cohort))
+	}
+	if role != "" {
+		rolePredicates = append(rolePredicates, ur.ROLE.EqString(role))
+	}
+	var predicates []sq.Predicate
+	if cohort != "" || role != "" {
+		predicates = append(predicates, sq.Exists(sq.SelectOne().From(ur).Where(rolePredicates...)))
+	}
+	meta.Total, err = api.count(u, predicates)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	users := []skylab.User{}
+	userIndex := make(map[int]int) // maps user.UserID to users index
+	var user skylab.User
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(u).
+		Where(predicates...).
+		OrderBy(u.USER_ID).
+		Limit(meta.PerPage).
+		Offset(meta.offset()).
+		Selectx(func(row *sq.Row) {
+			user.Valid = row.IntValid(u.USER_ID)
+			user.UserID = row.Int(u.USER_ID)
+			user.Displayname = row.String(u.DISPLAYNAME)
+			user.Email = row.String(u.EMAIL)
+			user.Roles = make(map[string]int)
+		}, func() {
+			users = append(users, user)
+			userIndex[user.UserID] = len(users) - 1
+		}).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	if len(users) == 0 {
+		api.writeJSON(w, r, http.StatusOK, listResponse{Data: users, Meta: meta})
+		return
+	}
+	userIDs := make([]int, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+	}
+	rolePredicates = []sq.Predicate{ur.USER_ID.In(userIDs)}
+	if cohort != "" {
+		rolePredicates = append(rolePredicates, ur.COHORT.EqString(cohort))
+	}
+	if role != "" {
+		rolePredicates = append(rolePredicates, ur.ROLE.EqString(role))
+	}
+	var userID, userRoleID int
+	var userRole string
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ur).
+		Where(rolePredicates...).
+		Selectx(func(row *sq.Row) {
+			userID = row.Int(ur.USER_ID)
+			userRoleID = row.Int(ur.USER_ROLE_ID)
+			userRole = row.String(ur.ROLE)
+		}, func() {
+			users[userIndex[userID]].Roles[userRole] = userRoleID
+		}).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, listResponse{Data: users, Meta: meta})
+}
+
+// GetUser gets a single user together with all of their roles.
+//
+// GET /api/v1/users/{userID}
+func (api API) GetUser(w http.ResponseWriter, r *http.Request) {
+	api.skylb.Log.TraceRequest(r)
+	userID, err := urlparams.Int(r, "userID")
+	if err != nil {
+		api.BadRequest(w, r, err.Error())
+		return
+	}
+	user, err := api.getUser(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.NotFound(w, r)
+			return
+		}
+		api.InternalServerError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, itemResponse{Data: user})
+}
+
+func (api API) getUser(userID int) (user skylab.User, err error) {
+	u, ur := tables.USERS(), tables.USER_ROLES()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(u).
+		Where(u.USER_ID.EqInt(userID)).
+		SelectRowx(func(row *sq.Row) {
+			user.Valid = row.IntValid(u.USER_ID)
+			user.UserID = row.Int(u.USER_ID)
+			user.Displayname = row.String(u.DISPLAYNAME)
+			user.Email = row.String(u.EMAIL)
+		}).
+		Fetch(api.skylb.DB)
+	if err != nil {
+		return user, erro.Wrap(err)
+	}
+	user.Roles = make(map[string]int)
+	var userRoleID int
+	var role string
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ur).
+		Where(ur.USER_ID.EqInt(user.UserID)).
+		Selectx(func(row *sq.Row) {
+			userRoleID = row.Int(ur.USER_ROLE_ID)
+			role = row.String(ur.ROLE)
+		}, func() {
+			user.Roles[role] = userRoleID
+		}).
+		Fetch(api.skylb.DB)
+	return user, erro.Wrap(err)
+}
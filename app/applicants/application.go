@@ -2,6 +2,7 @@ package applicants
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -53,6 +54,22 @@ func (apt Applicants) Application(w http.ResponseWriter, r *http.Request) {
 		apt.skylb.InternalServerError(w, r, err)
 		return
 	}
+	data.StatusHistory, err = apt.skylb.ApplicationStatusHistory(data.Application.ApplicationID)
+	if err != nil {
+		apt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	r, data.FormErrors = apt.skylb.GetFormErrors(w, r)
+	if data.FormErrors != nil {
+		// Show the answers the user just posted instead of the saved ones
+		data.Application.ApplicationAnswers = formx.ExtractAnswers(r.Form, data.Application.ApplicationForm.Questions)
+		applicantAnswers := formx.ExtractAnswers(r.Form, data.Application.ApplicantForm.Questions)
+		if user.UserID == data.Application.Applicant2.UserID {
+			data.Application.Applicant2Answers = applicantAnswers
+		} else {
+			data.Application.Applicant1Answers = applicantAnswers
+		}
+	}
 	funcs := template.FuncMap{}
 	funcs = formx.Funcs(funcs, apt.skylb.Policy)
 	apt.skylb.Render(w, r, data, funcs, "app/applicants/application.html", "helpers/formx/render_form.html")
@@ -236,6 +253,32 @@ func (apt Applicants) UpdateApplication(next http.Handler) http.Handler {
 		}
 		// Get applicant Answers
 		applicantAnswers := formx.ExtractAnswers(r.Form, applicantQuestions)
+		// Validate the answers as a draft, unless the application has already
+		// been submitted in which case the answers must stay complete
+		va := tables.V_APPLICATIONS()
+		var submitted bool
+		err = sq.From(va).Where(
+			va.COHORT.EqString(apt.skylb.CurrentCohort()),
+			sq.Int(user.UserID).In(sq.Fields{va.APPLICANT1_USER_ID, va.APPLICANT2_USER_ID}),
+		).SelectRowx(func(row *sq.Row) {
+			submitted = row.Bool(va.SUBMITTED)
+		}).Fetch(apt.skylb.DB)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			apt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		validate := formx.ValidateDraft
+		if submitted {
+			validate = formx.Validate
+		}
+		errs := mergeValidationErrors(
+			validate(applicationQuestions, applicationAnswers),
+			validate(applicantQuestions, applicantAnswers),
+		)
+		if errs != nil {
+			apt.Application(w, skylab.SetFormErrors(r, errs))
+			return
+		}
 		// Upsert application + applicant form data
 		userRoleID := user.Roles[skylab.RoleApplicant]
 		_, err = sq.WithDefaultLog(sq.Lverbose).
@@ -258,30 +301,43 @@ func (apt Applicants) SubmitApplication(next http.Handler) http.Handler {
 		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
 		msgs := make(map[string][]string)
 		a, va := tables.APPLICATIONS(), tables.V_APPLICATIONS()
-		var applicationID int
-		var applicant1UserID, applicant2UserID sql.NullInt64
+		var application skylab.Application
+		var errs formx.ValidationErrors
+		var myAnswers, teammateAnswers formx.Answers
 		err := sq.From(va).Where(
 			sq.Int(user.UserID).In(sq.Fields{
 				va.APPLICANT1_USER_ID,
 				va.APPLICANT2_USER_ID,
 			}),
-		).SelectRowx(func(row *sq.Row) {
-			applicationID = row.Int(va.APPLICATION_ID)
-			applicant1UserID = row.NullInt64(va.APPLICANT1_USER_ID)
-			applicant2UserID = row.NullInt64(va.APPLICANT2_USER_ID)
-		}).Fetch(apt.skylb.DB)
+		).SelectRowx((&application).RowMapper(va)).Fetch(apt.skylb.DB)
 		if err != nil {
 			apt.skylb.InternalServerError(w, r, err)
 			return
 		}
-		if !applicant1UserID.Valid || !applicant2UserID.Valid {
+		if !application.Applicant1.Valid || !application.Applicant2.Valid {
 			msgs[flash.Error] = []string{"You cannot submit your application as you are still missing a second team member"}
 			goto Next
 		}
+		myAnswers, teammateAnswers = application.Applicant1Answers, application.Applicant2Answers
+		if user.UserID == application.Applicant2.UserID {
+			myAnswers, teammateAnswers = application.Applicant2Answers, application.Applicant1Answers
+		}
+		errs = mergeValidationErrors(
+			formx.Validate(application.ApplicationForm.Questions, application.ApplicationAnswers),
+			formx.Validate(application.ApplicantForm.Questions, myAnswers),
+		)
+		if errs != nil {
+			apt.Application(w, skylab.SetFormErrors(r, errs))
+			return
+		}
+		if formx.Validate(application.ApplicantForm.Questions, teammateAnswers) != nil {
+			msgs[flash.Error] = []string{"You cannot submit your application as your team member has not finished answering their questions"}
+			goto Next
+		}
 		_, err = sq.WithDefaultLog(sq.Lstats).
 			Update(a).
 			Set(a.SUBMITTED.SetBool(true)).
-			Where(a.APPLICATION_ID.EqInt(applicationID)).
+			Where(a.APPLICATION_ID.EqInt(application.ApplicationID)).
 			Exec(apt.skylb.DB, sq.ErowsAffected)
 		if err != nil {
 			apt.skylb.InternalServerError(w, r, err)
@@ -293,3 +349,18 @@ func (apt Applicants) SubmitApplication(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// mergeValidationErrors combines the validation errors of the application and
+// applicant forms, which are rendered on the same page.
+func mergeValidationErrors(errsList ...formx.ValidationErrors) formx.ValidationErrors {
+	var merged formx.ValidationErrors
+	for _, errs := range errsList {
+		for name, msg := range errs {
+			if merged == nil {
+				merged = formx.ValidationErrors{}
+			}
+			merged[name] = msg
+		}
+	}
+	return merged
+}
@@ -0,0 +1,272 @@
+package applicants
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+)
+
+// Matchmaking shows an applicant without a partner their matchmaking profile,
+// the partners suggested to them and the requests they sent or received.
+func (apt Applicants) Matchmaking(w http.ResponseWriter, r *http.Request) {
+	apt.skylb.Log.TraceRequest(r)
+	r = apt.skylb.SetRoleSection(w, r, skylab.RoleApplicant, skylab.SectionPreserve)
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	if !user.Valid {
+		apt.skylb.BadRequest(w, r, fmt.Sprintf("Unable to obtain user from context"))
+		return
+	}
+	userRoleID := user.Roles[skylab.RoleApplicant]
+	var data skylab.MatchmakingData
+	var err error
+	data.HasPartner, err = apt.HasPartnerModel(userRoleID)
+	if err != nil {
+		apt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if data.HasPartner {
+		apt.skylb.Render(w, r, data, matchmakingFuncs(), "app/applicants/matchmaking.html")
+		return
+	}
+	data.Profile, err = apt.MatchProfileModel(userRoleID)
+	if err != nil {
+		apt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if !data.Profile.Valid {
+		data.Profile.ProjectLevel = skylab.ProjectLevelGemini
+		apt.skylb.Render(w, r, data, matchmakingFuncs(), "app/applicants/matchmaking.html")
+		return
+	}
+	data.IncomingRequests, data.OutgoingRequests, err = apt.MatchRequestsModel(userRoleID)
+	if err != nil {
+		apt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	pool, err := apt.MatchPoolModel(apt.skylb.CurrentCohort())
+	if err != nil {
+		apt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.Suggestions = skylab.SuggestPartners(data.Profile, pool)
+	for i, suggestion := range data.Suggestions {
+		for _, request := range data.OutgoingRequests {
+			if request.Requestee.UserRoleID == suggestion.Profile.UserRoleID {
+				data.Suggestions[i].Requested = true
+			}
+		}
+	}
+	apt.skylb.Render(w, r, data, matchmakingFuncs(), "app/applicants/matchmaking.html")
+}
+
+func matchmakingFuncs() template.FuncMap {
+	return template.FuncMap{
+		"MatchSkills":         skylab.MatchSkills,
+		"MatchAvailabilities": skylab.MatchAvailabilities,
+		"MatchProjectLevels":  skylab.ProjectLevels,
+		"MatchHas":            skylab.Contains,
+		"MatchJoin": func(items []string) string {
+			return strings.Join(items, ", ")
+		},
+	}
+}
+
+// SaveMatchProfile adds the applicant to the matchmaking pool with the profile
+// they filled in, or updates their profile.
+func (apt Applicants) SaveMatchProfile(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		_ = formutil.ParseForm(r)
+		msgs := make(map[string][]string)
+		profile := skylab.MatchProfile{
+			UserRoleID:   user.Roles[skylab.RoleApplicant],
+			ProjectLevel: r.FormValue("project_level"),
+			Skills:       filterOptions(r.Form["skills"], skylab.MatchSkills()),
+			Availability: filterOptions(r.Form["availability"], skylab.MatchAvailabilities()),
+			About:        strings.TrimSpace(r.FormValue("about")),
+		}
+		if len(filterOptions([]string{profile.ProjectLevel}, skylab.ProjectLevels())) == 0 {
+			msgs[flash.Error] = append(msgs[flash.Error], "Please pick the project level you are aiming for")
+		}
+		if len(profile.Availability) == 0 {
+			msgs[flash.Error] = append(msgs[flash.Error], "Please pick at least one time slot you are available in")
+		}
+		if len(msgs[flash.Error]) > 0 {
+			r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		hasPartner, err := apt.HasPartnerModel(profile.UserRoleID)
+		if err != nil {
+			apt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if hasPartner {
+			msgs[flash.Error] = []string{"You already have a partner"}
+			r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+			return
+		}
+		err = apt.SaveMatchProfileModel(profile)
+		if err != nil {
+			apt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		msgs[flash.Success] = []string{"Matchmaking profile saved"}
+		r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// filterOptions returns the values that are one of the options, dropping the
+// rest.
+func filterOptions(values, options []string) []string {
+	filtered := []string{}
+	for _, option := range options {
+		if skylab.Contains(values, option) {
+			filtered = append(filtered, option)
+		}
+	}
+	return filtered
+}
+
+// LeaveMatchmaking removes the applicant from the matchmaking pool.
+func (apt Applicants) LeaveMatchmaking(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		err := apt.LeaveMatchmakingModel(user.Roles[skylab.RoleApplicant])
+		if err != nil {
+			apt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		msgs := map[string][]string{flash.Success: {"You are no longer looking for a partner"}}
+		r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SendMatchRequest sends a request to partner up to the applicant with the
+// posted user_role_id. If they had already sent the applicant a request, both
+// are joined into one application right away.
+func (apt Applicants) SendMatchRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		requesteeUserRoleID, err := strconv.Atoi(r.FormValue("user_role_id"))
+		if err != nil {
+			apt.skylb.BadRequest(w, r, fmt.Sprintf("Invalid user_role_id: %s", r.FormValue("user_role_id")))
+			return
+		}
+		msgs := make(map[string][]string)
+		accepted, err := apt.SendMatchRequestModel(user.Roles[skylab.RoleApplicant], requesteeUserRoleID)
+		if err != nil {
+			if msg, ok := matchmakingErrorMsg(err); ok {
+				msgs[flash.Error] = []string{msg}
+				r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+				next.ServeHTTP(w, r)
+				return
+			}
+			apt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if accepted {
+			msgs[flash.Success] = []string{"They had already asked to partner up with you, you are now in the same application!"}
+			r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+			http.Redirect(w, r, "/applicant/application", http.StatusMovedPermanently)
+			return
+		}
+		msgs[flash.Success] = []string{"Request sent"}
+		r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AcceptMatchRequest accepts the request with the posted
+// matchmaking_request_id, joining both applicants into one application.
+func (apt Applicants) AcceptMatchRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		matchRequestID, err := strconv.Atoi(r.FormValue("matchmaking_request_id"))
+		if err != nil {
+			apt.skylb.BadRequest(w, r, fmt.Sprintf("Invalid matchmaking_request_id: %s", r.FormValue("matchmaking_request_id")))
+			return
+		}
+		msgs := make(map[string][]string)
+		err = apt.AcceptMatchRequestModel(matchRequestID, user.Roles[skylab.RoleApplicant])
+		if err != nil {
+			if msg, ok := matchmakingErrorMsg(err); ok {
+				msgs[flash.Error] = []string{msg}
+				r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+				http.Redirect(w, r, "/applicant/matchmaking", http.StatusMovedPermanently)
+				return
+			}
+			apt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		msgs[flash.Success] = []string{"Request accepted, you are now in the same application!"}
+		r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DeclineMatchRequest declines the request sent to the applicant with the
+// posted matchmaking_request_id.
+func (apt Applicants) DeclineMatchRequest(next http.Handler) http.Handler {
+	return apt.closeMatchRequestHandler(next, apt.DeclineMatchRequestModel, "Request declined")
+}
+
+// CancelMatchRequest cancels the request sent by the applicant with the posted
+// matchmaking_request_id.
+func (apt Applicants) CancelMatchRequest(next http.Handler) http.Handler {
+	return apt.closeMatchRequestHandler(next, apt.CancelMatchRequestModel, "Request cancelled")
+}
+
+func (apt Applicants) closeMatchRequestHandler(next http.Handler, closeRequest func(matchRequestID, userRoleID int) error, successMsg string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		matchRequestID, err := strconv.Atoi(r.FormValue("matchmaking_request_id"))
+		if err != nil {
+			apt.skylb.BadRequest(w, r, fmt.Sprintf("Invalid matchmaking_request_id: %s", r.FormValue("matchmaking_request_id")))
+			return
+		}
+		msgs := make(map[string][]string)
+		err = closeRequest(matchRequestID, user.Roles[skylab.RoleApplicant])
+		if err != nil {
+			msg, ok := matchmakingErrorMsg(err)
+			if !ok {
+				apt.skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs[flash.Error] = []string{msg}
+		} else {
+			msgs[flash.Success] = []string{successMsg}
+		}
+		r, _ = apt.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchmakingErrorMsg returns the message to show the applicant for errors
+// caused by the state of the matchmaking pool rather than by a bug.
+func matchmakingErrorMsg(err error) (msg string, ok bool) {
+	switch {
+	case errors.Is(err, skylab.ErrNotInMatchmakingPool):
+		return "That applicant is no longer looking for a partner (or you are not in the matchmaking pool)", true
+	case errors.Is(err, skylab.ErrMatchRequestNotExist):
+		return "That request has already been answered or cancelled", true
+	case errors.Is(err, skylab.ErrMatchRequestDeclined):
+		return "That applicant has already declined your request", true
+	}
+	return "", false
+}
@@ -1,7 +1,11 @@
 package applicants
 
 import (
+	"database/sql"
+	"errors"
+
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/lib/pq"
 
 	"github.com/bokwoon95/nusskylabx/helpers/erro"
 )
@@ -17,3 +21,334 @@ func (apt Applicants) LeaveApplicationModel(user skylab.User) (err error) {
 	_, err = apt.skylb.DB.Exec(query, user.UserID)
 	return erro.Wrap(err)
 }
+
+// matchProfileQuery selects the matchmaking profiles of applicants who do not
+// have a partner yet, for the conditions appended to it.
+const matchProfileQuery = `
+SELECT
+	mp.user_role_id, u.user_id, u.displayname, u.email, mp.project_level, mp.skills, mp.availability, mp.about
+FROM
+	matchmaking_profiles AS mp
+	JOIN user_roles AS ur ON ur.user_role_id = mp.user_role_id
+	JOIN users AS u ON u.user_id = ur.user_id
+WHERE
+	ur.role = 'applicant'
+	AND NOT EXISTS (
+		SELECT 1
+		FROM user_roles_applicants AS ura1 JOIN user_roles_applicants AS ura2 ON ura2.application_id = ura1.application_id
+		WHERE ura1.user_role_id = mp.user_role_id AND ura2.user_role_id <> ura1.user_role_id
+	)
+`
+
+func scanMatchProfile(scanner interface{ Scan(...interface{}) error }) (skylab.MatchProfile, error) {
+	var profile skylab.MatchProfile
+	err := scanner.Scan(
+		&profile.UserRoleID,
+		&profile.User.UserID,
+		&profile.User.Displayname,
+		&profile.User.Email,
+		&profile.ProjectLevel,
+		pq.Array(&profile.Skills),
+		pq.Array(&profile.Availability),
+		&profile.About,
+	)
+	profile.Valid = err == nil
+	profile.User.Valid = err == nil
+	return profile, err
+}
+
+// MatchProfileModel returns the matchmaking profile of an applicant. The
+// profile is not Valid if the applicant is not in the matchmaking pool.
+func (apt Applicants) MatchProfileModel(userRoleID int) (skylab.MatchProfile, error) {
+	row := apt.skylb.DB.QueryRow(matchProfileQuery+"AND mp.user_role_id = $1", userRoleID)
+	profile, err := scanMatchProfile(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return skylab.MatchProfile{}, nil
+	}
+	return profile, erro.Wrap(err)
+}
+
+// MatchPoolModel returns the matchmaking profiles of every applicant in the
+// cohort who is looking for a partner.
+func (apt Applicants) MatchPoolModel(cohort string) ([]skylab.MatchProfile, error) {
+	rows, err := apt.skylb.DB.Query(matchProfileQuery+"AND ur.cohort = $1 ORDER BY mp.user_role_id", cohort)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	defer rows.Close()
+	var profiles []skylab.MatchProfile
+	for rows.Next() {
+		profile, err := scanMatchProfile(rows)
+		if err != nil {
+			return nil, erro.Wrap(err)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, erro.Wrap(rows.Err())
+}
+
+// HasPartnerModel reports whether an applicant is already in an application
+// with someone else.
+func (apt Applicants) HasPartnerModel(userRoleID int) (hasPartner bool, err error) {
+	err = apt.skylb.DB.QueryRow(`
+	SELECT EXISTS (
+		SELECT 1
+		FROM user_roles_applicants AS ura1 JOIN user_roles_applicants AS ura2 ON ura2.application_id = ura1.application_id
+		WHERE ura1.user_role_id = $1 AND ura2.user_role_id <> ura1.user_role_id
+	)
+	`, userRoleID).Scan(&hasPartner)
+	return hasPartner, erro.Wrap(err)
+}
+
+// MatchRequestsModel returns the pending matchmaking requests sent to and
+// sent by an applicant.
+func (apt Applicants) MatchRequestsModel(userRoleID int) (incoming, outgoing []skylab.MatchRequest, err error) {
+	rows, err := apt.skylb.DB.Query(`
+	SELECT
+		mr.matchmaking_request_id, mr.status
+		,mr.requester_user_role_id, u1.user_id, u1.displayname, u1.email
+		,mr.requestee_user_role_id, u2.user_id, u2.displayname, u2.email
+	FROM
+		matchmaking_requests AS mr
+		JOIN user_roles AS ur1 ON ur1.user_role_id = mr.requester_user_role_id
+		JOIN users AS u1 ON u1.user_id = ur1.user_id
+		JOIN user_roles AS ur2 ON ur2.user_role_id = mr.requestee_user_role_id
+		JOIN users AS u2 ON u2.user_id = ur2.user_id
+	WHERE
+		mr.status = 'pending'
+		AND $1 IN (mr.requester_user_role_id, mr.requestee_user_role_id)
+	ORDER BY
+		mr.created_at
+	`, userRoleID)
+	if err != nil {
+		return nil, nil, erro.Wrap(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var request skylab.MatchRequest
+		err = rows.Scan(
+			&request.MatchRequestID, &request.Status,
+			&request.Requester.UserRoleID, &request.Requester.User.UserID, &request.Requester.User.Displayname, &request.Requester.User.Email,
+			&request.Requestee.UserRoleID, &request.Requestee.User.UserID, &request.Requestee.User.Displayname, &request.Requestee.User.Email,
+		)
+		if err != nil {
+			return nil, nil, erro.Wrap(err)
+		}
+		if request.Requestee.UserRoleID == userRoleID {
+			incoming = append(incoming, request)
+		} else {
+			outgoing = append(outgoing, request)
+		}
+	}
+	return incoming, outgoing, erro.Wrap(rows.Err())
+}
+
+// SaveMatchProfileModel adds an applicant to the matchmaking pool, or updates
+// their profile if they are already in it.
+func (apt Applicants) SaveMatchProfileModel(profile skylab.MatchProfile) error {
+	_, err := apt.skylb.DB.Exec(`
+	INSERT INTO matchmaking_profiles (user_role_id, project_level, skills, availability, about)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_role_id) DO UPDATE SET
+		project_level = EXCLUDED.project_level
+		,skills = EXCLUDED.skills
+		,availability = EXCLUDED.availability
+		,about = EXCLUDED.about
+	`, profile.UserRoleID, profile.ProjectLevel, pq.Array(profile.Skills), pq.Array(profile.Availability), profile.About)
+	return erro.Wrap(err)
+}
+
+// LeaveMatchmakingModel removes an applicant from the matchmaking pool and
+// cancels the requests they sent or received that are still pending.
+func (apt Applicants) LeaveMatchmakingModel(userRoleID int) error {
+	tx, err := apt.skylb.DB.Begin()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	err = leaveMatchmaking(tx, userRoleID)
+	if err != nil {
+		_ = tx.Rollback()
+		return erro.Wrap(err)
+	}
+	return erro.Wrap(tx.Commit())
+}
+
+func leaveMatchmaking(tx *sql.Tx, userRoleIDs ...int) error {
+	_, err := tx.Exec(`DELETE FROM matchmaking_profiles WHERE user_role_id = ANY($1)`, pq.Array(userRoleIDs))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+	UPDATE matchmaking_requests
+	SET status = 'cancelled'
+	WHERE status = 'pending' AND (requester_user_role_id = ANY($1) OR requestee_user_role_id = ANY($1))
+	`, pq.Array(userRoleIDs))
+	return err
+}
+
+// SendMatchRequestModel sends a request to partner up from one applicant in
+// the matchmaking pool to another. If the other applicant had already sent a
+// request the other way, that request is accepted instead and accepted is
+// true.
+func (apt Applicants) SendMatchRequestModel(requesterUserRoleID, requesteeUserRoleID int) (accepted bool, err error) {
+	for _, userRoleID := range []int{requesterUserRoleID, requesteeUserRoleID} {
+		profile, err := apt.MatchProfileModel(userRoleID)
+		if err != nil {
+			return false, erro.Wrap(err)
+		}
+		if !profile.Valid {
+			return false, erro.Errorf(skylab.ErrNotInMatchmakingPool, userRoleID)
+		}
+	}
+	var reverseRequestID int
+	err = apt.skylb.DB.QueryRow(`
+	SELECT matchmaking_request_id
+	FROM matchmaking_requests
+	WHERE requester_user_role_id = $1 AND requestee_user_role_id = $2 AND status = 'pending'
+	`, requesteeUserRoleID, requesterUserRoleID).Scan(&reverseRequestID)
+	switch {
+	case err == nil:
+		return true, apt.AcceptMatchRequestModel(reverseRequestID, requesterUserRoleID)
+	case !errors.Is(err, sql.ErrNoRows):
+		return false, erro.Wrap(err)
+	}
+	var status string
+	err = apt.skylb.DB.QueryRow(`
+	INSERT INTO matchmaking_requests (requester_user_role_id, requestee_user_role_id)
+	VALUES ($1, $2)
+	ON CONFLICT (requester_user_role_id, requestee_user_role_id) DO UPDATE SET
+		status = CASE WHEN matchmaking_requests.status = 'cancelled' THEN 'pending' ELSE matchmaking_requests.status END
+	RETURNING status
+	`, requesterUserRoleID, requesteeUserRoleID).Scan(&status)
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	if status == skylab.MatchRequestStatusDeclined {
+		return false, erro.Errorf(skylab.ErrMatchRequestDeclined, requesteeUserRoleID)
+	}
+	return false, nil
+}
+
+// DeclineMatchRequestModel declines a pending request sent to the applicant.
+func (apt Applicants) DeclineMatchRequestModel(matchRequestID, requesteeUserRoleID int) error {
+	return apt.closeMatchRequest(matchRequestID, "requestee_user_role_id", requesteeUserRoleID, skylab.MatchRequestStatusDeclined)
+}
+
+// CancelMatchRequestModel cancels a pending request sent by the applicant.
+func (apt Applicants) CancelMatchRequestModel(matchRequestID, requesterUserRoleID int) error {
+	return apt.closeMatchRequest(matchRequestID, "requester_user_role_id", requesterUserRoleID, skylab.MatchRequestStatusCancelled)
+}
+
+func (apt Applicants) closeMatchRequest(matchRequestID int, column string, userRoleID int, status string) error {
+	result, err := apt.skylb.DB.Exec(`
+	UPDATE matchmaking_requests
+	SET status = $1
+	WHERE matchmaking_request_id = $2 AND `+column+` = $3 AND status = 'pending'
+	`, status, matchRequestID, userRoleID)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if rowsAffected == 0 {
+		return erro.Errorf(skylab.ErrMatchRequestNotExist, matchRequestID)
+	}
+	return nil
+}
+
+// AcceptMatchRequestModel accepts a pending request sent to the applicant and
+// joins both applicants into one application with app.join_application. The
+// application of whichever applicant already has one is kept (the accepting
+// applicant's first), otherwise a new one is created for the accepting
+// applicant. Both applicants then leave the matchmaking pool.
+func (apt Applicants) AcceptMatchRequestModel(matchRequestID, requesteeUserRoleID int) error {
+	tx, err := apt.skylb.DB.Begin()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	err = acceptMatchRequest(tx, matchRequestID, requesteeUserRoleID)
+	if err != nil {
+		_ = tx.Rollback()
+		return erro.Wrap(err)
+	}
+	return erro.Wrap(tx.Commit())
+}
+
+func acceptMatchRequest(tx *sql.Tx, matchRequestID, requesteeUserRoleID int) error {
+	var requesterUserRoleID int
+	err := tx.QueryRow(`
+	SELECT requester_user_role_id
+	FROM matchmaking_requests
+	WHERE matchmaking_request_id = $1 AND requestee_user_role_id = $2 AND status = 'pending'
+	FOR UPDATE
+	`, matchRequestID, requesteeUserRoleID).Scan(&requesterUserRoleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return erro.Errorf(skylab.ErrMatchRequestNotExist, matchRequestID)
+	}
+	if err != nil {
+		return err
+	}
+	users := make(map[int]skylab.User)
+	for _, userRoleID := range []int{requesteeUserRoleID, requesterUserRoleID} {
+		var user skylab.User
+		var hasPartner bool
+		err = tx.QueryRow(`
+		SELECT
+			u.user_id, u.displayname, u.email
+			,EXISTS (
+				SELECT 1
+				FROM user_roles_applicants AS ura1 JOIN user_roles_applicants AS ura2 ON ura2.application_id = ura1.application_id
+				WHERE ura1.user_role_id = ur.user_role_id AND ura2.user_role_id <> ura1.user_role_id
+			)
+		FROM user_roles AS ur JOIN users AS u ON u.user_id = ur.user_id
+		WHERE ur.user_role_id = $1
+		`, userRoleID).Scan(&user.UserID, &user.Displayname, &user.Email, &hasPartner)
+		if err != nil {
+			return err
+		}
+		if hasPartner {
+			return erro.Errorf(skylab.ErrNotInMatchmakingPool, userRoleID)
+		}
+		users[userRoleID] = user
+	}
+	// Pick whose application to keep
+	host, guest := requesteeUserRoleID, requesterUserRoleID
+	var magicstring sql.NullString
+	err = tx.QueryRow(`
+	SELECT a.magicstring
+	FROM user_roles_applicants AS ura JOIN applications AS a ON a.application_id = ura.application_id
+	WHERE ura.user_role_id = $1
+	`, host).Scan(&magicstring)
+	if errors.Is(err, sql.ErrNoRows) {
+		host, guest = requesterUserRoleID, requesteeUserRoleID
+		err = tx.QueryRow(`
+		SELECT a.magicstring
+		FROM user_roles_applicants AS ura JOIN applications AS a ON a.application_id = ura.application_id
+		WHERE ura.user_role_id = $1
+		`, host).Scan(&magicstring)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		host, guest = requesteeUserRoleID, requesterUserRoleID
+		err = tx.QueryRow(
+			`SELECT _magicstring FROM app.idempotent_create_application($1, $2)`,
+			users[host].Displayname, users[host].Email,
+		).Scan(&magicstring)
+	}
+	if err != nil {
+		return err
+	}
+	if !magicstring.Valid {
+		return erro.Errorf(skylab.ErrNotInMatchmakingPool, host)
+	}
+	_, err = tx.Exec(`SELECT app.join_application($1, $2, $3)`, users[guest].Displayname, users[guest].Email, magicstring.String)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE matchmaking_requests SET status = 'accepted' WHERE matchmaking_request_id = $1`, matchRequestID)
+	if err != nil {
+		return err
+	}
+	return leaveMatchmaking(tx, requesteeUserRoleID, requesterUserRoleID)
+}
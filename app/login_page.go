@@ -1,10 +1,13 @@
 package app
 
 import (
+	"html/template"
 	"net/http"
+
+	"github.com/bokwoon95/nusskylabx/helpers/auth"
 )
 
 func (ap App) LoginPage(w http.ResponseWriter, r *http.Request) {
 	ap.skylb.Log.TraceRequest(r)
-	ap.skylb.Render(w, r, nil, nil, "app/login_page.html")
+	ap.skylb.Render(w, r, nil, auth.AddConstProvider(template.FuncMap{}), "app/login_page.html")
 }
@@ -2,8 +2,11 @@
 package mentors
 
 import (
+	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/app/db"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
 )
 
 type Mentors struct {
@@ -17,3 +20,32 @@ func New(skylb skylab.Skylab) Mentors {
 		d:     db.New(skylb),
 	}
 }
+
+func milestoneFromSection(section string) (milestone string) {
+	switch section {
+	case skylab.MentorM1Feedback:
+		return skylab.Milestone1
+	case skylab.MentorM2Feedback:
+		return skylab.Milestone2
+	case skylab.MentorM3Feedback:
+		return skylab.Milestone3
+	default:
+		return skylab.MilestoneNull
+	}
+}
+
+// getTeamIDs returns the teams mentored by the user.
+func (mnt Mentors) getTeamIDs(user skylab.User) (teamIDs []int, err error) {
+	t := tables.TEAMS()
+	var teamID int
+	err = sq.WithDefaultLog(sq.Lstats).
+		From(t).
+		Where(t.MENTOR_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleMentor])).
+		Selectx(func(row *sq.Row) {
+			teamID = row.Int(t.TEAM_ID)
+		}, func() {
+			teamIDs = append(teamIDs, teamID)
+		}).
+		Fetch(mnt.skylb.DB)
+	return teamIDs, erro.Wrap(err)
+}
@@ -0,0 +1,56 @@
+package mentors
+
+import (
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/helpers/timeutil"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// MilestoneFeedback lists the teams the user mentors together with the
+// feedback they have given on each team's submission for the milestone of the
+// section.
+func (mnt Mentors) MilestoneFeedback(section string) http.HandlerFunc {
+	milestone := milestoneFromSection(section)
+	return func(w http.ResponseWriter, r *http.Request) {
+		mnt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		r = mnt.skylb.SetRoleSection(w, r, skylab.RoleMentor, section)
+		headers.DoNotCache(w)
+		type Data struct {
+			Milestone   string
+			Evaluations []skylab.UserEvaluation
+			Period      skylab.Period
+		}
+		var data Data
+		data.Milestone = milestone
+		ue := tables.V_USER_EVALUATIONS()
+		userEvaluation := &skylab.UserEvaluation{}
+		err := sq.WithDefaultLog(sq.Lstats).
+			From(ue).
+			Where(
+				ue.EVALUATOR_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleMentor]),
+				ue.EVALUATOR_ROLE.EqString(skylab.RoleMentor),
+				ue.COHORT.EqString(mnt.skylb.CurrentCohort()),
+				ue.MILESTONE.EqString(milestone),
+			).
+			OrderBy(ue.EVALUATEE_TEAM_ID).
+			Selectx(
+				userEvaluation.RowMapper(ue),
+				func() { data.Evaluations = append(data.Evaluations, *userEvaluation) },
+			).
+			Fetch(mnt.skylb.DB)
+		if err != nil {
+			mnt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if len(data.Evaluations) > 0 {
+			data.Period = data.Evaluations[0].EvaluationForm.Period
+		}
+		funcs := timeutil.Funcs(nil)
+		mnt.skylb.Render(w, r, data, funcs, "app/mentors/milestone_feedback.html")
+	}
+}
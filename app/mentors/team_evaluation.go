@@ -0,0 +1,54 @@
+package mentors
+
+import (
+	"context"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// CanViewTeamEvaluation lets mentors read the peer evaluations received by the
+// teams they mentor.
+func (mnt Mentors) CanViewTeamEvaluation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mnt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		teamEvaluationID, err := urlparams.Int(r, "teamEvaluationID")
+		if err != nil {
+			mnt.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		mentorTeamIDs, err := mnt.getTeamIDs(user)
+		if err != nil {
+			mnt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if len(mentorTeamIDs) == 0 {
+			mnt.skylb.NotAuthorized(w, r)
+			return
+		}
+		te, s := tables.TEAM_EVALUATIONS(), tables.SUBMISSIONS()
+		rowsAffected, err := sq.WithDefaultLog(sq.Lstats).
+			SelectOne().
+			From(te).
+			Join(s, s.SUBMISSION_ID.Eq(te.EVALUATEE_SUBMISSION_ID)).
+			Where(
+				te.TEAM_EVALUATION_ID.EqInt(teamEvaluationID),
+				s.TEAM_ID.In(mentorTeamIDs),
+			).
+			Exec(mnt.skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			mnt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if rowsAffected == 0 {
+			mnt.skylb.NotAuthorized(w, r)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), skylab.ContextCanViewEvaluation, true))
+		next.ServeHTTP(w, r)
+	})
+}
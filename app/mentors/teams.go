@@ -0,0 +1,88 @@
+package mentors
+
+import (
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// Teams lists the teams of the current cohort mentored by the user, along with
+// their submissions and the peer evaluations they received.
+func (mnt Mentors) Teams(w http.ResponseWriter, r *http.Request) {
+	mnt.skylb.Log.TraceRequest(r)
+	r = mnt.skylb.SetRoleSection(w, r, skylab.RoleMentor, skylab.MentorTeams)
+	headers.DoNotCache(w)
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	type MentoredTeam struct {
+		Team        skylab.Team
+		Submissions []skylab.Submission
+		Evaluations []skylab.TeamEvaluation // submitted peer evaluations of the team's submissions
+	}
+	type Data struct {
+		Teams []MentoredTeam
+	}
+	var data Data
+	// teamIndex maps a teamID to its index in data.Teams
+	teamIndex := make(map[int]int)
+	var teamIDs []int
+	t := tables.V_TEAMS()
+	team := &skylab.Team{}
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(t).
+		Where(
+			t.MENTOR_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleMentor]),
+			t.COHORT.EqString(mnt.skylb.CurrentCohort()),
+		).
+		OrderBy(t.TEAM_ID).
+		Selectx(team.RowMapper(t), func() {
+			teamIndex[team.TeamID] = len(data.Teams)
+			teamIDs = append(teamIDs, team.TeamID)
+			data.Teams = append(data.Teams, MentoredTeam{Team: *team})
+		}).
+		Fetch(mnt.skylb.DB)
+	if err != nil {
+		mnt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if len(teamIDs) == 0 {
+		mnt.skylb.Render(w, r, data, nil, "app/mentors/teams.html")
+		return
+	}
+	s := tables.V_SUBMISSIONS()
+	submission := &skylab.Submission{}
+	err = sq.WithDefaultLog(sq.Lstats).
+		From(s).
+		Where(s.TEAM_ID.In(teamIDs)).
+		OrderBy(s.TEAM_ID, s.MILESTONE).
+		Selectx(submission.RowMapper(s), func() {
+			i := teamIndex[submission.Team.TeamID]
+			data.Teams[i].Submissions = append(data.Teams[i].Submissions, *submission)
+		}).
+		Fetch(mnt.skylb.DB)
+	if err != nil {
+		mnt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	te := tables.V_TEAM_EVALUATIONS()
+	evaluation := &skylab.TeamEvaluation{}
+	err = sq.WithDefaultLog(sq.Lstats).
+		From(te).
+		Where(
+			te.EVALUATEE_TEAM_ID.In(teamIDs),
+			te.EVALUATION_SUBMITTED,
+		).
+		OrderBy(te.EVALUATEE_TEAM_ID, te.MILESTONE, te.EVALUATOR_TEAM_ID).
+		Selectx(evaluation.RowMapper(te), func() {
+			i := teamIndex[evaluation.Evaluatee.Team.TeamID]
+			data.Teams[i].Evaluations = append(data.Teams[i].Evaluations, *evaluation)
+		}).
+		Fetch(mnt.skylb.DB)
+	if err != nil {
+		mnt.skylb.InternalServerError(w, r, err)
+		return
+	}
+	mnt.skylb.Render(w, r, data, nil, "app/mentors/teams.html")
+}
@@ -0,0 +1,76 @@
+package mentors
+
+import (
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// CanEditUserEvaluation only lets mentors view and edit the feedback they gave
+// themselves.
+func (mnt Mentors) CanEditUserEvaluation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mnt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		userEvaluationID, err := urlparams.Int(r, "userEvaluationID")
+		if err != nil {
+			mnt.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		ue := tables.USER_EVALUATIONS()
+		rowsAffected, err := sq.WithDefaultLog(sq.Lstats).
+			SelectOne().
+			From(ue).
+			Where(
+				ue.USER_EVALUATION_ID.EqInt(userEvaluationID),
+				ue.EVALUATOR_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleMentor]),
+			).
+			Exec(mnt.skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			mnt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if rowsAffected == 0 {
+			mnt.skylb.NotAuthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CanCreateUserEvaluation only lets mentors give feedback on the submissions of
+// the teams they mentor.
+func (mnt Mentors) CanCreateUserEvaluation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mnt.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		submissionID, err := formutil.Int(r, "submissionID")
+		if err != nil {
+			mnt.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		t, s := tables.TEAMS(), tables.SUBMISSIONS()
+		rowsAffected, err := sq.WithDefaultLog(sq.Lstats).
+			SelectOne().
+			From(t).
+			Join(s, s.TEAM_ID.Eq(t.TEAM_ID)).
+			Where(
+				s.SUBMISSION_ID.EqInt(submissionID),
+				t.MENTOR_USER_ROLE_ID.EqInt(user.Roles[skylab.RoleMentor]),
+			).
+			Exec(mnt.skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			mnt.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if rowsAffected == 0 {
+			mnt.skylb.NotAuthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
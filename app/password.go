@@ -0,0 +1,115 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+func (ap App) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	ap.skylb.Render(w, r, nil, nil, "app/forgot_password.html")
+}
+
+// ForgotPasswordPost emails a password reset link to the user if they are
+// allowed to use password login. The same message is shown regardless, so
+// that the form cannot be used to find out which emails have accounts.
+func (ap App) ForgotPasswordPost(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	email := strings.TrimSpace(r.FormValue("email"))
+	var user skylab.User
+	u := tables.USERS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(u).
+		Where(u.EMAIL.EqString(email)).
+		SelectRowx(func(row *sq.Row) {
+			user.Valid = row.IntValid(u.USER_ID)
+			user.UserID = row.Int(u.USER_ID)
+			user.Displayname = row.String(u.DISPLAYNAME)
+			user.Email = row.String(u.EMAIL)
+		}).
+		Fetch(ap.skylb.DB)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if user.Valid {
+		allowed, err := ap.skylb.PasswordLoginAllowed(user.UserID)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if allowed {
+			err = ap.skylb.SendPasswordEmail(user, skylab.PasswordTokenPurposeReset)
+			if err != nil {
+				ap.skylb.InternalServerError(w, r, err)
+				return
+			}
+		}
+	}
+	msgs := map[string][]string{flash.Success: {"If " + email + " is allowed to login with a password, a link to reset the password has been sent to it"}}
+	_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+	http.Redirect(w, r, "/password/forgot", http.StatusMovedPermanently)
+}
+
+func (ap App) SetPassword(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	headers.DoNotCache(w)
+	type Data struct {
+		Token             string
+		User              skylab.User
+		PasswordMinLength int
+	}
+	data := Data{
+		Token:             r.FormValue("token"),
+		PasswordMinLength: skylab.PasswordMinLength,
+	}
+	var err error
+	data.User, err = ap.skylb.GetUserFromPasswordToken(data.Token)
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	ap.skylb.Render(w, r, data, nil, "app/set_password.html")
+}
+
+func (ap App) SetPasswordPost(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	token := r.FormValue("token")
+	password := r.FormValue("password")
+	fail := func(msg string) {
+		_, _ = ap.skylb.SetFlashMsgs(w, r, map[string][]string{flash.Error: {msg}})
+		http.Redirect(w, r, "/password/set?token="+url.QueryEscape(token), http.StatusMovedPermanently)
+	}
+	if password != r.FormValue("confirm") {
+		fail("Passwords do not match")
+		return
+	}
+	_, err := ap.skylb.UsePasswordToken(token, password)
+	if err != nil {
+		switch {
+		case erro.Is(err, skylab.ErrPasswordTooShort):
+			fail(fmt.Sprintf("Password must be at least %d characters long", skylab.PasswordMinLength))
+		case erro.Is(err, skylab.ErrPasswordTooLong):
+			fail(fmt.Sprintf("Password must be at most %d characters long", skylab.PasswordMaxLength))
+		case erro.Is(err, skylab.ErrPasswordTokenInvalid):
+			fail("This link is invalid, has expired or has already been used")
+		default:
+			ap.skylb.InternalServerError(w, r, err)
+		}
+		return
+	}
+	msgs := map[string][]string{flash.Success: {"Your password has been set, you can now login with your email and password"}}
+	_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+	http.Redirect(w, r, "/login-page", http.StatusMovedPermanently)
+}
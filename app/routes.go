@@ -8,6 +8,7 @@ import (
 
 	"github.com/bokwoon95/nusskylabx/app/admins"
 	"github.com/bokwoon95/nusskylabx/app/advisers"
+	"github.com/bokwoon95/nusskylabx/app/api"
 	"github.com/bokwoon95/nusskylabx/app/applicants"
 	"github.com/bokwoon95/nusskylabx/app/mentors"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
@@ -48,6 +49,7 @@ func AllRoutes(skylb skylab.Skylab) {
 	AdviserRoutes(skylb)
 	MentorRoutes(skylb)
 	AdminRoutes(skylb)
+	APIRoutes(skylb)
 }
 
 func Routes(skylb skylab.Skylab) {
@@ -74,11 +76,36 @@ func Routes(skylb skylab.Skylab) {
 
 	// /login/callback
 	sessionMux.With(
+		skylb.RateLimit("login", skylab.RateLimitLogin),
 		auth.Authenticate(callbackURL, skylb.InternalServerError),
 		skylb.EnsureIsUser,
 		skylb.SetSession,
 	).Get("/login/callback", skylb.RedirectUserrole)
 
+	// /login/password
+	sessionMux.With(
+		skylb.RateLimit("login", skylab.RateLimitLogin),
+		skylb.PasswordLogin,
+		skylb.SetSession,
+	).Post("/login/password", skylb.RedirectUserrole)
+
+	// /login/totp
+	sessionMux.Get("/login/totp", ap.LoginTOTP)
+	sessionMux.With(
+		skylb.RateLimit("login", skylab.RateLimitLogin),
+		ap.LoginTOTPVerify,
+	).Post("/login/totp", skylb.RedirectUserrole)
+
+	// /password/forgot
+	sessionMux.Get("/password/forgot", ap.ForgotPassword)
+	sessionMux.With(
+		skylb.RateLimit("forgot-password", skylab.RateLimitForgotPassword),
+	).Post("/password/forgot", ap.ForgotPasswordPost)
+
+	// /password/set
+	sessionMux.Get("/password/set", ap.SetPassword)
+	sessionMux.Post("/password/set", ap.SetPasswordPost)
+
 	// /logout
 	sessionMux.With(
 		skylb.RevokeSession,
@@ -95,6 +122,33 @@ func Routes(skylb skylab.Skylab) {
 
 	// /user/update/{userID}
 	sessionMux.Post("/user/update/{userID}", ap.UserUpdate)
+
+	// /user/api-token/create
+	sessionMux.Post("/user/api-token/create", ap.UserAPITokenCreate)
+
+	// /user/api-token/{apiTokenID}/revoke
+	sessionMux.Post(`/user/api-token/{apiTokenID:\d+}/revoke`, ap.UserAPITokenRevoke)
+
+	// /user/session/{sessionID}/revoke
+	sessionMux.Post(`/user/session/{sessionID:\d+}/revoke`, ap.UserSessionRevoke)
+
+	// /user/totp/setup
+	sessionMux.Post("/user/totp/setup", ap.UserTOTPSetup)
+
+	// /user/totp/enable
+	sessionMux.With(
+		skylb.RateLimit("totp", skylab.RateLimitLogin),
+	).Post("/user/totp/enable", ap.UserTOTPEnable)
+
+	// /user/totp/recovery-codes
+	sessionMux.With(
+		skylb.RateLimit("totp", skylab.RateLimitLogin),
+	).Post("/user/totp/recovery-codes", ap.UserTOTPRecoveryCodes)
+
+	// /user/totp/disable
+	sessionMux.With(
+		skylb.RateLimit("totp", skylab.RateLimitLogin),
+	).Post("/user/totp/disable", ap.UserTOTPDisable)
 }
 
 func SkylabRoutes(skylb skylab.Skylab) {
@@ -114,6 +168,7 @@ func ApplicantRoutes(skylb skylab.Skylab) {
 	// /applicant/application/join
 	skylb.Mux.With(
 		skylb.GetSession,
+		skylb.RateLimit("join", skylab.RateLimitJoin),
 		apt.CheckIfOpen,
 		apt.MagicstringVerifier,
 		apt.JoinIfLoggedin,
@@ -125,6 +180,7 @@ func ApplicantRoutes(skylb skylab.Skylab) {
 
 	// /applicant/application/join/callback
 	skylb.Mux.With(
+		skylb.RateLimit("join", skylab.RateLimitJoin),
 		openid.Authenticate(openid.ProviderNUS, skylb.InternalServerError),
 		apt.CheckIfOpen,
 		apt.MagicstringVerifier,
@@ -172,10 +228,44 @@ func ApplicantRoutes(skylb skylab.Skylab) {
 
 	// /applucant/application/submit
 	applicantsMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
 		apt.UpdateApplication,
 		flashMessager.UnsetFlashMsgsHandler(flash.Success),
 		apt.SubmitApplication,
 	).Post("/applicant/application/submit", skylb.Redirect("/applicant/application"))
+
+	// /applicant/matchmaking
+	applicantsMux.Get("/applicant/matchmaking", apt.Matchmaking)
+
+	// /applicant/matchmaking/profile
+	applicantsMux.With(
+		apt.SaveMatchProfile,
+	).Post("/applicant/matchmaking/profile", skylb.Redirect("/applicant/matchmaking"))
+
+	// /applicant/matchmaking/leave
+	applicantsMux.With(
+		apt.LeaveMatchmaking,
+	).Post("/applicant/matchmaking/leave", skylb.Redirect("/applicant/matchmaking"))
+
+	// /applicant/matchmaking/request
+	applicantsMux.With(
+		apt.SendMatchRequest,
+	).Post("/applicant/matchmaking/request", skylb.Redirect("/applicant/matchmaking"))
+
+	// /applicant/matchmaking/accept
+	applicantsMux.With(
+		apt.AcceptMatchRequest,
+	).Post("/applicant/matchmaking/accept", skylb.Redirect("/applicant/application"))
+
+	// /applicant/matchmaking/decline
+	applicantsMux.With(
+		apt.DeclineMatchRequest,
+	).Post("/applicant/matchmaking/decline", skylb.Redirect("/applicant/matchmaking"))
+
+	// /applicant/matchmaking/cancel
+	applicantsMux.With(
+		apt.CancelMatchRequest,
+	).Post("/applicant/matchmaking/cancel", skylb.Redirect("/applicant/matchmaking"))
 }
 
 func StudentRoutes(skylb skylab.Skylab) {
@@ -213,6 +303,17 @@ func StudentRoutes(skylb skylab.Skylab) {
 		stu.CanViewSubmission,
 	).Get(skylab.StudentSubmission+`/{submissionID:\d+}`, skylb.SubmissionView(skylab.RoleStudent))
 
+	// /student/submission/{submissionID}/history
+	studentsMux.With(
+		stu.CanViewSubmission,
+	).Get(skylab.StudentSubmission+`/{submissionID:\d+}/history`, skylb.SubmissionHistory(skylab.RoleStudent))
+
+	// /student/submission/{submissionID}/restore
+	studentsMux.With(
+		stu.CanEditSubmission,
+		stu.SubmissionRestore,
+	).Post(skylab.StudentSubmission+`/{submissionID:\d+}/restore`, skylb.Redirect(skylab.StudentSubmission+`/{submissionID}/history`))
+
 	// /student/submission/{submissionID}/edit
 	studentsMux.With(
 		stu.CanEditSubmission,
@@ -256,6 +357,7 @@ func StudentRoutes(skylb skylab.Skylab) {
 
 	// /student/submission/{submissionID}/submit
 	studentsMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
 		stu.CanEditSubmission,
 		stu.SubmissionUpdate,
 		flashMessager.UnsetFlashMsgsHandler(flash.Success),
@@ -310,6 +412,7 @@ func StudentRoutes(skylb skylab.Skylab) {
 
 	// /student/team-evaluation/{teamEvaluationID}/submit
 	studentsMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
 		stu.CanEditTeamEvaluation,
 		stu.TeamEvaluationUpdate,
 		flashMessager.UnsetFlashMsgsHandler(flash.Success),
@@ -323,6 +426,49 @@ func StudentRoutes(skylb skylab.Skylab) {
 	studentsMux.With(
 		stu.CanEditTeamFeedback,
 	).Get(skylab.StudentTeamFeedback+`/{feedbackIDOnTeam:\d+}/edit`, stu.TeamFeedbackEdit)
+
+	// Redirects to /student/feedback/user/{feedbackIDOnUser}
+	redirectUserFeedbackView := skylb.Redirect(skylab.StudentUserFeedback + "/{feedbackIDOnUser}")
+
+	// Redirects to /student/feedback/user/{feedbackIDOnUser}/edit
+	redirectUserFeedbackEdit := skylb.Redirect(skylab.StudentUserFeedback + "/{feedbackIDOnUser}/edit")
+
+	// /student/feedback/user/create
+	studentsMux.With(
+		stu.UserFeedbackCreate,
+	).Post(skylab.StudentUserFeedback+"/create", redirectUserFeedbackEdit)
+
+	// /student/feedback/user/{feedbackIDOnUser}
+	studentsMux.With(
+		stu.CanEditUserFeedback,
+	).Get(skylab.StudentUserFeedback+`/{feedbackIDOnUser:\d+}`, skylb.UserFeedbackView(skylab.RoleStudent))
+
+	// /student/feedback/user/{feedbackIDOnUser}/edit
+	studentsMux.With(
+		stu.CanEditUserFeedback,
+	).Get(skylab.StudentUserFeedback+`/{feedbackIDOnUser:\d+}/edit`, stu.UserFeedbackEdit)
+
+	// /student/feedback/user/{feedbackIDOnUser}/preview
+	studentsMux.With(
+		stu.CanEditUserFeedback,
+		stu.UserFeedbackUpdate,
+		flashMessager.UnsetFlashMsgsHandler(flash.Success),
+	).Post(skylab.StudentUserFeedback+`/{feedbackIDOnUser:\d+}/preview`, redirectUserFeedbackView)
+
+	// /student/feedback/user/{feedbackIDOnUser}/update
+	studentsMux.With(
+		stu.CanEditUserFeedback,
+		stu.UserFeedbackUpdate,
+	).Post(skylab.StudentUserFeedback+`/{feedbackIDOnUser:\d+}/update`, redirectUserFeedbackEdit)
+
+	// /student/feedback/user/{feedbackIDOnUser}/submit
+	studentsMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
+		stu.CanEditUserFeedback,
+		stu.UserFeedbackUpdate,
+		flashMessager.UnsetFlashMsgsHandler(flash.Success),
+		stu.UserFeedbackSubmit,
+	).Post(skylab.StudentUserFeedback+`/{feedbackIDOnUser:\d+}/submit`, redirectUserFeedbackEdit)
 }
 
 func addMilestoneCtxHandler(milestone string) func(http.Handler) http.Handler {
@@ -356,12 +502,41 @@ func AdviserRoutes(skylb skylab.Skylab) {
 	// /adviser/teams
 	advisersMux.Get(skylab.AdviserTeams, adv.Teams)
 
+	// /adviser/teams/meetings/save
+	advisersMux.With(
+		adv.TeamMeetingSave,
+	).Post(skylab.AdviserTeams+"/meetings/save", skylb.Redirect(skylab.AdviserTeams))
+
+	// /adviser/teams/meetings/delete
+	advisersMux.With(
+		adv.TeamMeetingDelete,
+	).Post(skylab.AdviserTeams+"/meetings/delete", skylb.Redirect(skylab.AdviserTeams))
+
+	// /adviser/teams/status
+	advisersMux.With(
+		adv.TeamStatusUpdate,
+	).Post(skylab.AdviserTeams+"/status", skylb.Redirect(skylab.AdviserTeams))
+
+	// /adviser/teams/meetings.csv
+	advisersMux.Get(skylab.AdviserTeams+"/meetings.csv", adv.TeamMeetingsExport)
+
 	// /adviser/evaluatee-evaluators
 	advisersMux.Get(skylab.AdviserEvaluateeEvaluators, adv.EvaluateeEvaluators)
 
 	// /adviser/evaluatee-evaluators/update
 	advisersMux.Post(skylab.AdviserEvaluateeEvaluators+"/update", adv.EvaluateeEvaluatorsUpdate)
 
+	// /adviser/evaluatee-evaluators/pairs.csv
+	advisersMux.Get(skylab.AdviserEvaluateeEvaluators+"/pairs.csv", adv.EvaluationPairsExport)
+
+	// /adviser/evaluatee-evaluators/import
+	advisersMux.Post(skylab.AdviserEvaluateeEvaluators+"/import", adv.EvaluationPairsImport)
+
+	// /adviser/evaluatee-evaluators/import/apply
+	advisersMux.With(
+		adv.EvaluationPairsImportApply,
+	).Post(skylab.AdviserEvaluateeEvaluators+"/import/apply", skylb.Redirect(skylab.AdviserEvaluateeEvaluators))
+
 	// /adviser/evaluator-evaluatees
 	advisersMux.Get(skylab.AdviserEvaluatorEvaluatees, adv.EvaluatorEvaluatees)
 
@@ -387,6 +562,11 @@ func AdviserRoutes(skylb skylab.Skylab) {
 		adv.CanViewSubmission,
 	).Get(skylab.AdviserSubmission+`/{submissionID:\d+}`, skylb.SubmissionView(skylab.RoleAdviser))
 
+	// /adviser/submission/{submissionID}/history
+	advisersMux.With(
+		adv.CanViewSubmission,
+	).Get(skylab.AdviserSubmission+`/{submissionID:\d+}/history`, skylb.SubmissionHistory(skylab.RoleAdviser))
+
 	// /adviser/user-evaluation/{userEvaluationID}
 	advisersMux.With(
 		adv.CanViewUserEvaluation,
@@ -412,6 +592,7 @@ func AdviserRoutes(skylb skylab.Skylab) {
 
 	// /adviser/user-evaluation/{userEvaluationID}/submit
 	advisersMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
 		adv.CanEditUserEvaluation,
 		adv.UserEvaluationUpdate,
 		flashMessager.UnsetFlashMsgsHandler(flash.Success),
@@ -431,10 +612,28 @@ func AdviserRoutes(skylb skylab.Skylab) {
 	advisersMux.With(
 		adv.CanViewTeamEvaluation,
 	).Get(skylab.AdviserTeamEvaluation+`/{teamEvaluationID:\d+}`, skylb.TeamEvaluationView(skylab.RoleAdviser))
+
+	// /adviser/application-reviews
+	advisersMux.Get(skylab.AdviserApplicationReviews, skylb.ListApplicationReviews(skylab.RoleAdviser))
+
+	// /adviser/application-review/{applicationID}
+	advisersMux.Get(skylab.AdviserApplicationReview+`/{applicationID:\d+}`, skylb.ApplicationReviewEdit(skylab.RoleAdviser))
+
+	// /adviser/application-review/{applicationID}/update
+	advisersMux.With(
+		skylb.ApplicationReviewUpdate(skylab.RoleAdviser, false),
+	).Post(skylab.AdviserApplicationReview+`/{applicationID:\d+}/update`, skylb.Redirect(skylab.AdviserApplicationReview+`/{applicationID}`))
+
+	// /adviser/application-review/{applicationID}/submit
+	advisersMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
+		skylb.ApplicationReviewUpdate(skylab.RoleAdviser, true),
+	).Post(skylab.AdviserApplicationReview+`/{applicationID:\d+}/submit`, skylb.Redirect(skylab.AdviserApplicationReview+`/{applicationID}`))
 }
 
 func MentorRoutes(skylb skylab.Skylab) {
 	mnt := mentors.New(skylb)
+	flashMessager := flash.NewEncoder(skylb.SecretKey)
 
 	// mentorsMux ensures all users who access the routes are Advisers
 	mentorsMux := skylb.Mux.With(
@@ -448,12 +647,83 @@ func MentorRoutes(skylb skylab.Skylab) {
 
 	// Dashboard //
 	mentorsMux.Get(skylab.MentorDashboard, mnt.Dashboard)
+
+	// Redirects to /mentor/user-evaluation/{userEvaluationID}
+	redirectEvaluationView := skylb.Redirect(skylab.MentorUserEvaluation + "/{userEvaluationID}")
+
+	// Redirects to /mentor/user-evaluation/{userEvaluationID}/edit
+	redirectEvaluationEdit := skylb.Redirect(skylab.MentorUserEvaluation + "/{userEvaluationID}/edit")
+
+	// /mentor/teams
+	mentorsMux.Get(skylab.MentorTeams, mnt.Teams)
+
+	// /mentor/milestone1/feedback
+	mentorsMux.Get(skylab.MentorM1Feedback, mnt.MilestoneFeedback(skylab.MentorM1Feedback))
+
+	// /mentor/milestone2/feedback
+	mentorsMux.Get(skylab.MentorM2Feedback, mnt.MilestoneFeedback(skylab.MentorM2Feedback))
+
+	// /mentor/milestone3/feedback
+	mentorsMux.Get(skylab.MentorM3Feedback, mnt.MilestoneFeedback(skylab.MentorM3Feedback))
+
+	// /mentor/submission/{submissionID}
+	mentorsMux.With(
+		mnt.CanViewSubmission,
+	).Get(skylab.MentorSubmission+`/{submissionID:\d+}`, skylb.SubmissionView(skylab.RoleMentor))
+
+	// /mentor/submission/{submissionID}/history
+	mentorsMux.With(
+		mnt.CanViewSubmission,
+	).Get(skylab.MentorSubmission+`/{submissionID:\d+}/history`, skylb.SubmissionHistory(skylab.RoleMentor))
+
+	// /mentor/team-evaluation/{teamEvaluationID}
+	mentorsMux.With(
+		mnt.CanViewTeamEvaluation,
+	).Get(skylab.MentorTeamEvaluation+`/{teamEvaluationID:\d+}`, skylb.TeamEvaluationView(skylab.RoleMentor))
+
+	// /mentor/user-evaluation/create
+	mentorsMux.With(
+		mnt.CanCreateUserEvaluation,
+		skylb.UserEvaluationCreate(skylab.RoleMentor),
+	).Post(skylab.MentorUserEvaluation+"/create", redirectEvaluationEdit)
+
+	// /mentor/user-evaluation/{userEvaluationID}
+	mentorsMux.With(
+		mnt.CanEditUserEvaluation,
+	).Get(skylab.MentorUserEvaluation+`/{userEvaluationID:\d+}`, skylb.UserEvaluationView(skylab.RoleMentor))
+
+	// /mentor/user-evaluation/{userEvaluationID}/edit
+	mentorsMux.With(
+		mnt.CanEditUserEvaluation,
+	).Get(skylab.MentorUserEvaluation+`/{userEvaluationID:\d+}/edit`, skylb.UserEvaluationEdit(skylab.RoleMentor))
+
+	// /mentor/user-evaluation/{userEvaluationID}/preview
+	mentorsMux.With(
+		mnt.CanEditUserEvaluation,
+		skylb.UserEvaluationUpdate(skylab.RoleMentor),
+		flashMessager.UnsetFlashMsgsHandler(flash.Success),
+	).Post(skylab.MentorUserEvaluation+`/{userEvaluationID:\d+}/preview`, redirectEvaluationView)
+
+	// /mentor/user-evaluation/{userEvaluationID}/update
+	mentorsMux.With(
+		mnt.CanEditUserEvaluation,
+		skylb.UserEvaluationUpdate(skylab.RoleMentor),
+	).Post(skylab.MentorUserEvaluation+`/{userEvaluationID:\d+}/update`, redirectEvaluationEdit)
+
+	// /mentor/user-evaluation/{userEvaluationID}/submit
+	mentorsMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
+		mnt.CanEditUserEvaluation,
+		skylb.UserEvaluationUpdate(skylab.RoleMentor),
+		flashMessager.UnsetFlashMsgsHandler(flash.Success),
+		skylb.UserEvaluationSubmit(skylab.RoleMentor),
+	).Post(skylab.MentorUserEvaluation+`/{userEvaluationID:\d+}/submit`, redirectEvaluationEdit)
 }
 
 func AdminRoutes(skylb skylab.Skylab) {
 	adm := admins.New(skylb)
 
-	adminsMux := skylb.Mux.With(skylb.GetSession, skylb.AllowIfDevelopment)
+	adminsMux := skylb.Mux.With(skylb.GetSession, skylb.RequireAdminTOTP, skylb.AllowIfDevelopment)
 	adminsMux.With(skylb.RedirectToLastSection(skylab.RoleAdmin)).Get("/admin", adm.Dashboard)
 	flashMessager := flash.NewEncoder(skylb.SecretKey)
 
@@ -502,6 +772,24 @@ func AdminRoutes(skylb skylab.Skylab) {
 		adm.UserPreviewAs,
 	).Post(skylab.AdminUser+`/{userID:\d+}/preview`, skylb.Redirect(skylab.AdminUser+`/{userID:\d+}`))
 
+	// /admin/user/{userID}/revoke-sessions
+	adminsMux.With(
+		adm.UserRevokeSessions,
+	).Post(skylab.AdminUser+`/{userID:\d+}/revoke-sessions`, skylb.Redirect(skylab.AdminUser+`/{userID}`))
+
+	// /admin/user/{userID}/send-password-email
+	adminsMux.With(
+		adm.UserSendPasswordEmail,
+	).Post(skylab.AdminUser+`/{userID:\d+}/send-password-email`, skylb.Redirect(skylab.AdminUser+`/{userID}`))
+
+	// /admin/password-login
+	adminsMux.Get(skylab.AdminPasswordLogin, adm.PasswordLogin)
+
+	// /admin/password-login/update
+	adminsMux.With(
+		adm.PasswordLoginUpdate,
+	).Post(skylab.AdminPasswordLogin+`/update`, skylb.Redirect(skylab.AdminPasswordLogin))
+
 	// /admin/periods/{cohort}
 	adminsMux.Get(skylab.AdminListPeriods, adm.ListPeriods)
 	adminsMux.Get(skylab.AdminListPeriods+`/{cohort}`, adm.ListPeriods)
@@ -521,6 +809,14 @@ func AdminRoutes(skylb skylab.Skylab) {
 		adm.ListPeriodsDuplicate,
 	).Post(skylab.AdminListPeriods+`/duplicate`, skylb.Redirect(skylab.AdminListPeriods+`/{cohort}`))
 
+	// /admin/periods/{periodID}/peer-review
+	adminsMux.Get(skylab.AdminListPeriods+`/{periodID:\d+}/peer-review`, adm.PeerReviewSettings)
+
+	// /admin/periods/{periodID}/peer-review/update
+	adminsMux.With(
+		adm.PeerReviewSettingsUpdate,
+	).Post(skylab.AdminListPeriods+`/{periodID:\d+}/peer-review/update`, skylb.Redirect(skylab.AdminListPeriods+`/{periodID}/peer-review`))
+
 	// /admin/forms/{cohort}
 	adminsMux.Get(skylab.AdminListForms, adm.ListForms)
 	adminsMux.Get(skylab.AdminListForms+`/{cohort}`, adm.ListForms)
@@ -530,6 +826,11 @@ func AdminRoutes(skylb skylab.Skylab) {
 		adm.ListFormsCreate,
 	).Post(skylab.AdminListForms+`/create`, skylb.Redirect(skylab.AdminForm+`/{formID}/edit`))
 
+	// /admin/forms/import
+	adminsMux.With(
+		adm.ListFormsImport,
+	).Post(skylab.AdminListForms+`/import`, skylb.Redirect(skylab.AdminForm+`/{formID}/edit`))
+
 	// /admin/forms/duplicate
 	adminsMux.With(
 		adm.ListFormsDuplicate,
@@ -546,6 +847,12 @@ func AdminRoutes(skylb skylab.Skylab) {
 	// /admin/form/{formID}/edit
 	adminsMux.Get(skylab.AdminForm+`/{formID:\d+}/edit`, adm.FormEdit)
 
+	// /admin/form/{formID}/analytics
+	adminsMux.Get(skylab.AdminForm+`/{formID:\d+}/analytics`, adm.FormAnalytics)
+
+	// /admin/form/{formID}/export
+	adminsMux.Get(skylab.AdminForm+`/{formID:\d+}/export`, adm.FormExport)
+
 	// /admin/form/{formID}/update
 	adminsMux.With(
 		adm.FormUpdate,
@@ -568,11 +875,83 @@ func AdminRoutes(skylb skylab.Skylab) {
 	adminsMux.Get(skylab.AdminListApplications, adm.ListApplications)
 	adminsMux.Get(skylab.AdminListApplications+`/{cohort}`, adm.ListApplications)
 
-	// AdminApplication //
-	// TODO?
+	// /admin/applications/{cohort}/stage
+	adminsMux.With(
+		adm.ListApplicationsStage,
+	).Post(skylab.AdminListApplications+`/{cohort}/stage`, skylb.Redirect(skylab.AdminListApplications+`/{cohort}`))
+
+	// /admin/applications/{cohort}/publish
+	adminsMux.With(
+		adm.ListApplicationsPublish,
+	).Post(skylab.AdminListApplications+`/{cohort}/publish`, skylb.Redirect(skylab.AdminListApplications+`/{cohort}`))
+
+	// /admin/application/{applicationID}
+	adminsMux.Get(skylab.AdminApplication+`/{applicationID:\d+}`, adm.ApplicationView)
+
+	// /admin/application/{applicationID}/assign
+	adminsMux.With(
+		adm.ApplicationReviewerAssign,
+	).Post(skylab.AdminApplication+`/{applicationID:\d+}/assign`, skylb.Redirect(skylab.AdminApplication+`/{applicationID}`))
+
+	// /admin/application/{applicationID}/unassign
+	adminsMux.With(
+		adm.ApplicationReviewerUnassign,
+	).Post(skylab.AdminApplication+`/{applicationID:\d+}/unassign`, skylb.Redirect(skylab.AdminApplication+`/{applicationID}`))
+
+	// /admin/application-reviews
+	adminsMux.Get(skylab.AdminApplicationReviews, skylb.ListApplicationReviews(skylab.RoleAdmin))
+
+	// /admin/application-review/{applicationID}
+	adminsMux.Get(skylab.AdminApplicationReview+`/{applicationID:\d+}`, skylb.ApplicationReviewEdit(skylab.RoleAdmin))
+
+	// /admin/application-review/{applicationID}/update
+	adminsMux.With(
+		skylb.ApplicationReviewUpdate(skylab.RoleAdmin, false),
+	).Post(skylab.AdminApplicationReview+`/{applicationID:\d+}/update`, skylb.Redirect(skylab.AdminApplicationReview+`/{applicationID}`))
+
+	// /admin/application-review/{applicationID}/submit
+	adminsMux.With(
+		skylb.RateLimit("submit", skylab.RateLimitSubmit),
+		skylb.ApplicationReviewUpdate(skylab.RoleAdmin, true),
+	).Post(skylab.AdminApplicationReview+`/{applicationID:\d+}/submit`, skylb.Redirect(skylab.AdminApplicationReview+`/{applicationID}`))
 
 	// /admin/feedbacks
 	adminsMux.Get(skylab.AdminListFeedbacks, adm.ListFeedbacks)
+	adminsMux.Get(skylab.AdminListFeedbacks+`/{cohort}`, adm.ListFeedbacks)
+
+	// /admin/feedbacks/user/{feedbackIDOnUser}
+	adminsMux.Get(skylab.AdminListFeedbacks+`/user/{feedbackIDOnUser:\d+}`, skylb.UserFeedbackView(skylab.RoleAdmin))
+
+	// /admin/webhooks
+	adminsMux.Get(skylab.AdminListWebhooks, adm.ListWebhooks)
+
+	// /admin/webhooks/create
+	adminsMux.With(
+		adm.ListWebhooksCreate,
+	).Post(skylab.AdminListWebhooks+`/create`, skylb.Redirect(skylab.AdminListWebhooks))
+
+	// /admin/webhooks/{webhookID}/delete
+	adminsMux.With(
+		adm.ListWebhooksDelete,
+	).Post(skylab.AdminListWebhooks+`/{webhookID:\d+}/delete`, skylb.Redirect(skylab.AdminListWebhooks))
+
+	// /admin/webhooks/{webhookID}/toggle
+	adminsMux.With(
+		adm.ListWebhooksToggle,
+	).Post(skylab.AdminListWebhooks+`/{webhookID:\d+}/toggle`, skylb.Redirect(skylab.AdminListWebhooks))
+
+	// /admin/webhooks/{webhookID}/test
+	adminsMux.With(
+		adm.ListWebhooksTest,
+	).Post(skylab.AdminListWebhooks+`/{webhookID:\d+}/test`, skylb.Redirect(skylab.AdminListWebhooks))
+
+	// /admin/webhook/{webhookID}
+	adminsMux.Get(skylab.AdminWebhook+`/{webhookID:\d+}`, adm.WebhookView)
+
+	// /admin/webhook/{webhookID}/delivery/{deliveryID}/retry
+	adminsMux.With(
+		adm.WebhookRetry,
+	).Post(skylab.AdminWebhook+`/{webhookID:\d+}/delivery/{deliveryID:\d+}/retry`, skylb.Redirect(skylab.AdminWebhook+`/{webhookID}`))
 
 	// /admin/dump-json
 	adminsMux.Get(skylab.AdminDumpJson, adm.DumpJson)
@@ -584,3 +963,60 @@ func AdminRoutes(skylb skylab.Skylab) {
 	adminsMux.Get(skylab.AdminTestmail, adm.Testmail)
 	adminsMux.Post(skylab.AdminTestmail, adm.TestmailPost)
 }
+
+func APIRoutes(skylb skylab.Skylab) {
+	v1 := api.New(skylb)
+
+	// apiMux ensures user (authenticated by either an API token or the
+	// session cookie) is an admin before passing through. Unlike the other
+	// muxes, every error is returned as JSON instead of an HTML page
+	apiMux := skylb.Mux.With(skylb.BearerToken, v1.EnsureAdmin)
+
+	// /api/v1/cohorts
+	apiMux.Get(api.Prefix+"/cohorts", v1.ListCohorts)
+
+	// /api/v1/periods
+	apiMux.Get(api.Prefix+"/periods", v1.ListPeriods)
+
+	// /api/v1/periods/{periodID}
+	apiMux.Get(api.Prefix+`/periods/{periodID:\d+}`, v1.GetPeriod)
+
+	// /api/v1/users
+	apiMux.Get(api.Prefix+"/users", v1.ListUsers)
+
+	// /api/v1/users/{userID}
+	apiMux.Get(api.Prefix+`/users/{userID:\d+}`, v1.GetUser)
+
+	// /api/v1/teams
+	apiMux.Get(api.Prefix+"/teams", v1.ListTeams)
+
+	// /api/v1/teams/{teamID}
+	apiMux.Get(api.Prefix+`/teams/{teamID:\d+}`, v1.GetTeam)
+
+	// /api/v1/applications
+	apiMux.Get(api.Prefix+"/applications", v1.ListApplications)
+
+	// /api/v1/applications/{applicationID}
+	apiMux.Get(api.Prefix+`/applications/{applicationID:\d+}`, v1.GetApplication)
+
+	// /api/v1/submissions
+	apiMux.Get(api.Prefix+"/submissions", v1.ListSubmissions)
+
+	// /api/v1/submissions/{submissionID}
+	apiMux.Get(api.Prefix+`/submissions/{submissionID:\d+}`, v1.GetSubmission)
+
+	// /api/v1/team-evaluations
+	apiMux.Get(api.Prefix+"/team-evaluations", v1.ListTeamEvaluations)
+
+	// /api/v1/team-evaluations/{teamEvaluationID}
+	apiMux.Get(api.Prefix+`/team-evaluations/{teamEvaluationID:\d+}`, v1.GetTeamEvaluation)
+
+	// /api/v1/user-evaluations
+	apiMux.Get(api.Prefix+"/user-evaluations", v1.ListUserEvaluations)
+
+	// /api/v1/user-evaluations/{userEvaluationID}
+	apiMux.Get(api.Prefix+`/user-evaluations/{userEvaluationID:\d+}`, v1.GetUserEvaluation)
+
+	// Any other /api/v1/* path gets a JSON 404 instead of the HTML one
+	apiMux.HandleFunc(api.Prefix+"/*", v1.NotFound)
+}
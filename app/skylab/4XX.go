@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"runtime"
+	"time"
 
 	"github.com/bokwoon95/nusskylabx/helpers/formutil"
 	"github.com/bokwoon95/nusskylabx/helpers/logutil"
@@ -141,3 +142,16 @@ func (skylb Skylab) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusMethodNotAllowed)
 	skylb.Render(w, r, data, nil, "app/skylab/405.html")
 }
+
+// TooManyRequests renders the 429 page for a client that has exceeded a rate
+// limit, telling them how long to wait before trying again.
+func (skylb Skylab) TooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	skylb.Log.TraceRequest(r)
+	type Data struct {
+		URL        string
+		RetryAfter string
+	}
+	data := Data{URL: r.URL.String(), RetryAfter: retryAfter.Round(time.Second).String()}
+	w.WriteHeader(http.StatusTooManyRequests)
+	skylb.Render(w, r, data, nil, "app/skylab/429.html")
+}
@@ -4,8 +4,10 @@ import (
 	"crypto/sha256"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/bokwoon95/nusskylabx/helpers/random"
+	"github.com/bokwoon95/nusskylabx/helpers/ratelimit"
 	"github.com/bokwoon95/nusskylabx/helpers/testutil"
 	"github.com/gorilla/csrf"
 	"github.com/matryer/is"
@@ -104,3 +106,21 @@ func TestSkylab_MethodNotAllowed(t *testing.T) {
 	is.True(testutil.HasBody(w))
 	is.Equal(w.Code, http.StatusMethodNotAllowed)
 }
+
+func TestSkylab_TooManyRequests(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	skylb := NewTestDefault(t)
+	path := random.URL()
+	skylb.Mux.With(
+		skylb.RateLimit(path, ratelimit.Limit{Requests: 1, Window: time.Hour}),
+	).Get(path, func(w http.ResponseWriter, r *http.Request) {})
+	w, r := testutil.NewGet(path, nil)
+	skylb.Mux.ServeHTTP(w, r)
+	is.Equal(w.Code, http.StatusOK)
+	w, r = testutil.NewGet(path, nil)
+	skylb.Mux.ServeHTTP(w, r)
+	is.True(testutil.HasBody(w))
+	is.Equal(w.Code, http.StatusTooManyRequests)
+	is.True(w.Header().Get("Retry-After") != "")
+}
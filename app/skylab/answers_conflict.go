@@ -0,0 +1,104 @@
+package skylab
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+)
+
+// Fields posted alongside the answers of a form that is shared between the
+// members of a team (submissions, team evaluations and feedback), so that two
+// members editing it at the same time do not silently overwrite each other.
+const (
+	// UpdatedAtField holds the updated_at of the answers when the form was
+	// loaded, see UpdatedAtValue
+	UpdatedAtField = "updated_at"
+	// KeepFieldPrefix + <question name> is set to "theirs" on the conflict page
+	// to keep the saved answer to a question instead of the user's own
+	KeepFieldPrefix = "keep."
+)
+
+// AnswersConflict is returned when someone else saved different answers since
+// the user loaded the form. Answers are the user's answers that were not
+// saved, and UpdatedAt is when the answers that are saved were saved.
+type AnswersConflict struct {
+	UpdatedAt time.Time
+	Answers   formx.Answers
+	Conflicts []formx.Conflict
+}
+
+func (c AnswersConflict) Error() string {
+	return fmt.Sprintf("%d answer(s) were changed by someone else since the form was loaded", len(c.Conflicts))
+}
+
+// UpdatedAtValue formats an updated_at for UpdatedAtField.
+func UpdatedAtValue(updatedAt sql.NullTime) string {
+	if !updatedAt.Valid {
+		return ""
+	}
+	return updatedAt.Time.Format(time.RFC3339Nano)
+}
+
+// CheckAnswersConflict checks the answers posted in form against the saved
+// answers, which were last updated at updatedAt. It first applies the choices
+// made on the conflict page (see KeepFieldPrefix). If the saved answers have
+// been updated since the form was loaded and they differ from the posted
+// answers, an AnswersConflict is returned. Forms posted without an
+// UpdatedAtField are not checked.
+func CheckAnswersConflict(form url.Values, questions formx.Questions, answers, saved formx.Answers, updatedAt time.Time) (formx.Answers, error) {
+	saved = questions.Normalize(saved)
+	for field, values := range form {
+		if strings.HasPrefix(field, KeepFieldPrefix) && len(values) > 0 && values[0] == "theirs" {
+			name := strings.TrimPrefix(field, KeepFieldPrefix)
+			if _, ok := answers[name]; ok {
+				answers[name] = saved[name]
+			}
+		}
+	}
+	value := form.Get(UpdatedAtField)
+	if value == "" {
+		return answers, nil
+	}
+	loadedAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return answers, erro.Wrap(err)
+	}
+	if loadedAt.Equal(updatedAt) {
+		return answers, nil
+	}
+	conflicts := formx.Conflicts(questions, answers, saved)
+	if len(conflicts) == 0 {
+		return answers, nil
+	}
+	return answers, AnswersConflict{UpdatedAt: updatedAt, Answers: answers, Conflicts: conflicts}
+}
+
+// AnswersConflictPage shows the user their answers side by side with the saved
+// answers for every question in the conflict, and lets them pick which one to
+// keep for each. The page posts the merged answers back to updateURL.
+func (skylb Skylab) AnswersConflictPage(w http.ResponseWriter, r *http.Request, conflict AnswersConflict, title, updateURL, editURL string) {
+	var data AnswersConflictData
+	data.Title = title
+	data.UpdateURL = updateURL
+	data.EditURL = editURL
+	data.UpdatedAt = UpdatedAtValue(sql.NullTime{Time: conflict.UpdatedAt, Valid: true})
+	data.Answers = conflict.Answers
+	data.Conflicts = conflict.Conflicts
+	funcs := formx.Funcs(nil, skylb.Policy)
+	funcs["keepField"] = func(name string) string { return KeepFieldPrefix + name }
+	funcs["richText"] = isRichText
+	w.WriteHeader(http.StatusConflict)
+	skylb.Render(w, r, data, funcs, "app/skylab/answers_conflict.html")
+}
+
+// isRichText reports whether the answers to a question are HTML written in the
+// rich text editor.
+func isRichText(questionType string) bool {
+	return questionType == formx.QuestionTypeLongtext
+}
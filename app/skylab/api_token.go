@@ -0,0 +1,232 @@
+package skylab
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/auth"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
+	"github.com/lib/pq"
+)
+
+// APITokenPrefix is prepended to every generated API token so that tokens
+// are easily recognizable (e.g. by secret scanners) when they are leaked.
+const APITokenPrefix = "skylab_"
+
+// APITokenMaxDays is the maximum number of days an API token can be valid for.
+const APITokenMaxDays = 365
+
+// APIToken is a personal access token that lets scripts authenticate as a
+// User without going through the OAuth/OpenID login flow. Only the hash of
+// the token is stored in the database, the plaintext token is shown to the
+// user exactly once when it is created.
+type APIToken struct {
+	Valid      bool
+	APITokenID int
+	UserID     int
+	Name       string
+	ReadOnly   bool
+	Roles      []string // an empty Roles means the token has all of the user's roles
+	ExpiresAt  time.Time
+	LastUsedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+func (tok *APIToken) RowMapper(tbl tables.TABLE_API_TOKENS) func(*sq.Row) {
+	return func(row *sq.Row) {
+		*tok = APIToken{
+			Valid:      row.IntValid(tbl.API_TOKEN_ID),
+			APITokenID: row.Int(tbl.API_TOKEN_ID),
+			UserID:     row.Int(tbl.USER_ID),
+			Name:       row.String(tbl.NAME),
+			ReadOnly:   row.Bool(tbl.READ_ONLY),
+			ExpiresAt:  row.Time(tbl.EXPIRES_AT),
+			LastUsedAt: row.NullTime(tbl.LAST_USED_AT),
+			CreatedAt:  row.Time(tbl.CREATED_AT),
+		}
+		row.ScanArray(&tok.Roles, tbl.ROLES)
+	}
+}
+
+// Expired reports whether the token has expired.
+func (tok APIToken) Expired() bool {
+	return !tok.ExpiresAt.After(time.Now())
+}
+
+// ScopeRoles narrows down a user's roles to the roles that the token is
+// scoped to.
+func (tok APIToken) ScopeRoles(roles map[string]int) map[string]int {
+	if len(tok.Roles) == 0 {
+		return roles
+	}
+	scoped := make(map[string]int)
+	for _, role := range tok.Roles {
+		if userRoleID, ok := roles[role]; ok {
+			scoped[role] = userRoleID
+		}
+	}
+	return scoped
+}
+
+// CreateAPIToken creates a new API token for the user and returns the
+// plaintext token. The roles must be a subset of the user's roles.
+func (skylb Skylab) CreateAPIToken(user User, name string, readOnly bool, roles []string, expiresAt time.Time) (token string, err error) {
+	for _, role := range roles {
+		if user.Roles[role] == 0 {
+			return "", erro.Wrap(erro.Errorf(ErrNotARole, user, role))
+		}
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	random, err := auth.GenerateRandomString()
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	token = APITokenPrefix + random
+	tok := tables.API_TOKENS()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		InsertInto(tok).
+		Columns(tok.USER_ID, tok.NAME, tok.HASH, tok.READ_ONLY, tok.ROLES, tok.EXPIRES_AT).
+		Values(user.UserID, name, skylb.Hash([]byte(token)), readOnly, pq.Array(roles), expiresAt).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	return token, nil
+}
+
+// ListAPITokens lists all API tokens belonging to a user, newest first.
+func (skylb Skylab) ListAPITokens(userID int) (tokens []APIToken, err error) {
+	tok := tables.API_TOKENS()
+	token := &APIToken{}
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(tok).
+		Where(tok.USER_ID.EqInt(userID)).
+		OrderBy(tok.CREATED_AT.Desc()).
+		Selectx(token.RowMapper(tok), func() { tokens = append(tokens, *token) }).
+		Fetch(skylb.DB)
+	return tokens, erro.Wrap(err)
+}
+
+// RevokeAPIToken deletes an API token. The userID is required so that users
+// can only revoke their own tokens.
+func (skylb Skylab) RevokeAPIToken(userID, apiTokenID int) error {
+	tok := tables.API_TOKENS()
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(tok).
+		Where(
+			tok.API_TOKEN_ID.EqInt(apiTokenID),
+			tok.USER_ID.EqInt(userID),
+		).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// GetUserFromAPIToken gets the user that the API token belongs to. The user's
+// roles are narrowed down to the roles that the token is scoped to. If the
+// token does not exist or has expired, the returned user and token will not
+// be valid (but err will still be nil).
+func (skylb Skylab) GetUserFromAPIToken(token string) (user User, apiToken APIToken, err error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return user, apiToken, nil
+	}
+	tok := tables.API_TOKENS()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(tok).
+		Where(tok.HASH.EqString(skylb.Hash([]byte(token)))).
+		SelectRowx(apiToken.RowMapper(tok)).
+		Fetch(skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, APIToken{}, nil
+		}
+		return user, apiToken, erro.Wrap(err)
+	}
+	if apiToken.Expired() {
+		return user, APIToken{}, nil
+	}
+	// Keep track of when the token was last used so that users can identify
+	// (and revoke) unused tokens
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		Update(tok).
+		Set(tok.LAST_USED_AT.SetTime(time.Now())).
+		Where(tok.API_TOKEN_ID.EqInt(apiToken.APITokenID)).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return user, apiToken, erro.Wrap(err)
+	}
+	// Get the user
+	u, ur := tables.USERS(), tables.USER_ROLES()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(u).
+		Where(u.USER_ID.EqInt(apiToken.UserID)).
+		SelectRowx(func(row *sq.Row) {
+			user.Valid = row.IntValid(u.USER_ID)
+			user.UserID = row.Int(u.USER_ID)
+			user.Displayname = row.String(u.DISPLAYNAME)
+			user.Email = row.String(u.EMAIL)
+		}).
+		Fetch(skylb.DB)
+	if err != nil {
+		return user, apiToken, erro.Wrap(err)
+	}
+	// Get the user roles
+	user.Roles = make(map[string]int)
+	var userRoleID int
+	var role string
+	err = sq.From(ur).Where(ur.USER_ID.EqInt(user.UserID)).Selectx(func(row *sq.Row) {
+		userRoleID = row.Int(ur.USER_ROLE_ID)
+		role = row.String(ur.ROLE)
+	}, func() {
+		user.Roles[role] = userRoleID
+	}).Fetch(skylb.DB)
+	if err != nil {
+		return user, apiToken, erro.Wrap(err)
+	}
+	user.Roles = apiToken.ScopeRoles(user.Roles)
+	return user, apiToken, nil
+}
+
+// BearerToken authenticates the request using the API token found in the
+// "Authorization: Bearer <token>" header, and injects the token's User (and
+// Admin, if the token is scoped to the admin role) into the current context
+// the same way GetSession does. The APIToken itself is injected under
+// ContextAPIToken so that handlers can check if the token is read-only.
+//
+// If the request has no Authorization header, BearerToken falls back on
+// GetSession instead.
+func (skylb Skylab) BearerToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" {
+			skylb.GetSession(next).ServeHTTP(w, r)
+			return
+		}
+		skylb.Log.StartRequest(r)
+		skylb.Log.TraceRequest(r)
+		var user User
+		var apiToken APIToken
+		if token := strings.TrimPrefix(authorization, "Bearer "); token != authorization {
+			var err error
+			user, apiToken, err = skylb.GetUserFromAPIToken(strings.TrimSpace(token))
+			if err != nil {
+				skylb.InternalServerError(w, r, err)
+				return
+			}
+		}
+		admin := user
+		admin.Valid = user.Valid && user.Roles[RoleAdmin] != 0
+		skylb.Log.RequestPrintf(r, "api token user: %+v", user)
+		r = r.WithContext(context.WithValue(r.Context(), ContextUser, user))
+		r = r.WithContext(context.WithValue(r.Context(), ContextAdmin, admin))
+		r = r.WithContext(context.WithValue(r.Context(), ContextAPIToken, apiToken))
+		next.ServeHTTP(w, r)
+	})
+}
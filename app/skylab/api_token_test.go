@@ -0,0 +1,22 @@
+package skylab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestAPIToken_ScopeRoles(t *testing.T) {
+	is := is.New(t)
+	roles := map[string]int{RoleStudent: 1, RoleAdviser: 2, RoleAdmin: 3}
+	is.Equal(APIToken{}.ScopeRoles(roles), roles)
+	is.Equal(APIToken{Roles: []string{RoleAdmin}}.ScopeRoles(roles), map[string]int{RoleAdmin: 3})
+	is.Equal(APIToken{Roles: []string{RoleMentor}}.ScopeRoles(roles), map[string]int{})
+}
+
+func TestAPIToken_Expired(t *testing.T) {
+	is := is.New(t)
+	is.True(APIToken{ExpiresAt: time.Now().Add(-time.Minute)}.Expired())
+	is.True(!APIToken{ExpiresAt: time.Now().Add(time.Hour)}.Expired())
+}
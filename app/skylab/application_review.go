@@ -0,0 +1,219 @@
+package skylab
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ApplicationReview is an admin's or adviser's review of an application,
+// scored with the review form of the application period (see
+// ApplicationSubsectionReview).
+type ApplicationReview struct {
+	Valid               bool
+	ApplicationReviewID int
+	Application         Application // only the ApplicationID, Cohort, ProjectLevel and Status
+	ReviewerUserRoleID  int
+	ReviewerRole        string
+	Reviewer            User
+	Answers             formx.Answers
+	Score               sql.NullFloat64 // the total of the answers, see formx.Score
+	Submitted           bool
+	UpdatedAt           sql.NullTime
+}
+
+// ApplicationScore sums up the submitted reviews of an application.
+type ApplicationScore struct {
+	ApplicationID int
+	Assigned      int     // number of reviewers assigned
+	Reviews       int     // number of submitted reviews with a score
+	Mean          float64 // mean of the scores
+	Normalized    float64 // mean of the scores as standard scores of each reviewer's own scores
+	Rank          int     // 1 for the best application, 0 if the application has no reviews yet
+}
+
+// RankApplications computes the score of every application in reviews.
+// Reviewers who score everything high (or low) would otherwise decide the
+// ranking, so each score is first normalized against the other scores given
+// by the same reviewer, and applications are ranked by the mean of their
+// normalized scores.
+func RankApplications(reviews []ApplicationReview) map[int]ApplicationScore {
+	byReviewer := make(map[int][]float64)
+	for _, review := range reviews {
+		if review.Submitted && review.Score.Valid {
+			byReviewer[review.ReviewerUserRoleID] = append(byReviewer[review.ReviewerUserRoleID], review.Score.Float64)
+		}
+	}
+	type stats struct{ mean, stddev float64 }
+	reviewerStats := make(map[int]stats)
+	for reviewer, scores := range byReviewer {
+		var sum, squares float64
+		for _, score := range scores {
+			sum += score
+		}
+		mean := sum / float64(len(scores))
+		for _, score := range scores {
+			squares += (score - mean) * (score - mean)
+		}
+		reviewerStats[reviewer] = stats{mean: mean, stddev: math.Sqrt(squares / float64(len(scores)))}
+	}
+	scores := make(map[int]ApplicationScore)
+	for _, review := range reviews {
+		score := scores[review.Application.ApplicationID]
+		score.ApplicationID = review.Application.ApplicationID
+		score.Assigned++
+		if review.Submitted && review.Score.Valid {
+			score.Reviews++
+			score.Mean += review.Score.Float64
+			if s := reviewerStats[review.ReviewerUserRoleID]; s.stddev > 0 {
+				score.Normalized += (review.Score.Float64 - s.mean) / s.stddev
+			}
+		}
+		scores[review.Application.ApplicationID] = score
+	}
+	var ranked []ApplicationScore
+	for applicationID, score := range scores {
+		if score.Reviews == 0 {
+			continue
+		}
+		score.Mean /= float64(score.Reviews)
+		score.Normalized /= float64(score.Reviews)
+		scores[applicationID] = score
+		ranked = append(ranked, score)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Normalized != ranked[j].Normalized {
+			return ranked[i].Normalized > ranked[j].Normalized
+		}
+		if ranked[i].Mean != ranked[j].Mean {
+			return ranked[i].Mean > ranked[j].Mean
+		}
+		return ranked[i].ApplicationID < ranked[j].ApplicationID
+	})
+	for i, score := range ranked {
+		score.Rank = i + 1
+		scores[score.ApplicationID] = score
+	}
+	return scores
+}
+
+// ApplicationReviewForm returns the review form of a cohort's application
+// period.
+func (skylb Skylab) ApplicationReviewForm(cohort string) (Form, error) {
+	var form Form
+	p, f := tables.PERIODS(), tables.FORMS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(f).
+		Join(p, p.PERIOD_ID.Eq(f.PERIOD_ID)).
+		Where(
+			p.COHORT.EqString(cohort),
+			p.STAGE.EqString(StageApplication),
+			p.MILESTONE.EqString(MilestoneNull),
+			f.NAME.EqString(""),
+			f.SUBSECTION.EqString(ApplicationSubsectionReview),
+		).
+		SelectRowx(func(row *sq.Row) {
+			form = Form{
+				Valid:      row.IntValid(f.FORM_ID),
+				FormID:     row.Int(f.FORM_ID),
+				Subsection: row.String(f.SUBSECTION),
+			}
+			row.ScanInto(&form.Questions, f.QUESTIONS)
+		}).
+		Fetch(skylb.DB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return form, erro.Errorf(ErrApplicationReviewFormNotExist, cohort)
+	}
+	return form, erro.Wrap(err)
+}
+
+// ApplicationReviews returns the application reviews that match the
+// predicates, ordered by application and then reviewer.
+func (skylb Skylab) ApplicationReviews(predicates ...sq.Predicate) ([]ApplicationReview, error) {
+	var reviews []ApplicationReview
+	var review ApplicationReview
+	ar, a := tables.APPLICATION_REVIEWS(), tables.APPLICATIONS()
+	ur, u := tables.USER_ROLES(), tables.USERS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(ar).
+		Join(a, a.APPLICATION_ID.Eq(ar.APPLICATION_ID)).
+		Join(ur, ur.USER_ROLE_ID.Eq(ar.REVIEWER_USER_ROLE_ID)).
+		Join(u, u.USER_ID.Eq(ur.USER_ID)).
+		Where(predicates...).
+		OrderBy(ar.APPLICATION_ID, u.DISPLAYNAME).
+		Selectx(func(row *sq.Row) {
+			review = ApplicationReview{
+				Valid:               row.IntValid(ar.APPLICATION_REVIEW_ID),
+				ApplicationReviewID: row.Int(ar.APPLICATION_REVIEW_ID),
+				Application: Application{
+					Valid:         row.IntValid(a.APPLICATION_ID),
+					ApplicationID: row.Int(a.APPLICATION_ID),
+					Cohort:        row.String(a.COHORT),
+					ProjectLevel:  row.String(a.PROJECT_LEVEL),
+					Status:        row.String(a.STATUS),
+				},
+				ReviewerUserRoleID: row.Int(ar.REVIEWER_USER_ROLE_ID),
+				ReviewerRole:       row.String(ur.ROLE),
+				Reviewer: User{
+					Valid:       row.IntValid(u.USER_ID),
+					UserID:      row.Int(u.USER_ID),
+					Displayname: row.String(u.DISPLAYNAME),
+					Email:       row.String(u.EMAIL),
+				},
+				Score:     row.NullFloat64(ar.SCORE),
+				Submitted: row.Bool(ar.SUBMITTED),
+				UpdatedAt: row.NullTime(ar.UPDATED_AT),
+			}
+			row.ScanInto(&review.Answers, ar.REVIEW_DATA)
+		}, func() {
+			reviews = append(reviews, review)
+		}).
+		Fetch(skylb.DB)
+	return reviews, erro.Wrap(err)
+}
+
+// MyApplicationReview returns the review of an application assigned to the
+// user in the role.
+func (skylb Skylab) MyApplicationReview(applicationID int, user User, role string) (ApplicationReview, error) {
+	ar, ur := tables.APPLICATION_REVIEWS(), tables.USER_ROLES()
+	reviews, err := skylb.ApplicationReviews(
+		ar.APPLICATION_ID.EqInt(applicationID),
+		ur.USER_ID.EqInt(user.UserID),
+		ur.ROLE.EqString(role),
+	)
+	if err != nil {
+		return ApplicationReview{}, erro.Wrap(err)
+	}
+	if len(reviews) == 0 {
+		return ApplicationReview{}, erro.Errorf(ErrApplicationReviewNotExist, applicationID, user.UserID)
+	}
+	return reviews[0], nil
+}
+
+// SaveApplicationReview saves the answers of a review to the review form and
+// its score. A submitted review stays submitted.
+func (skylb Skylab) SaveApplicationReview(review ApplicationReview, form Form, submit bool) error {
+	ar := tables.APPLICATION_REVIEWS()
+	score, scored := formx.Score(form.Questions, review.Answers)
+	setScore := ar.SCORE.Set(nil)
+	if scored {
+		setScore = ar.SCORE.SetFloat64(score)
+	}
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		Update(ar).
+		Set(
+			ar.REVIEW_FORM_ID.SetInt(form.FormID),
+			ar.REVIEW_DATA.Set(review.Answers),
+			setScore,
+			ar.SUBMITTED.SetBool(review.Submitted || submit),
+		).
+		Where(ar.APPLICATION_REVIEW_ID.EqInt(review.ApplicationReviewID)).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
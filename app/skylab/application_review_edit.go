@@ -0,0 +1,162 @@
+package skylab
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// applicationReviewSections returns the list and review sections of the roles
+// that can review applications.
+func applicationReviewSections(role string) (list, review string) {
+	switch role {
+	case RoleAdmin:
+		return AdminApplicationReviews, AdminApplicationReview
+	case RoleAdviser:
+		return AdviserApplicationReviews, AdviserApplicationReview
+	}
+	return "", ""
+}
+
+// ListApplicationReviews lists the applications of the current cohort that
+// the user has been assigned to review in the role.
+func (skylb Skylab) ListApplicationReviews(role string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		skylb.Log.TraceRequest(r)
+		listSection, reviewSection := applicationReviewSections(role)
+		if listSection == "" {
+			skylb.InternalServerError(w, r, fmt.Errorf("%s cannot review applications", role))
+			return
+		}
+		r = skylb.SetRoleSection(w, r, role, listSection)
+		user, _ := r.Context().Value(ContextUser).(User)
+		var data ApplicationReviewListData
+		data.ReviewURL = reviewSection
+		var err error
+		a, ur := tables.APPLICATIONS(), tables.USER_ROLES()
+		data.Reviews, err = skylb.ApplicationReviews(
+			a.COHORT.EqString(skylb.CurrentCohort()),
+			ur.USER_ID.EqInt(user.UserID),
+			ur.ROLE.EqString(role),
+		)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		skylb.Render(w, r, data, nil, "app/skylab/application_reviews.html")
+	}
+}
+
+// ApplicationReviewEdit shows the answers of an application next to the
+// review form, for the reviewer assigned to it in the role.
+func (skylb Skylab) ApplicationReviewEdit(role string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		skylb.Log.TraceRequest(r)
+		listSection, reviewSection := applicationReviewSections(role)
+		r = skylb.SetRoleSection(w, r, role, listSection)
+		user, _ := r.Context().Value(ContextUser).(User)
+		applicationID, err := urlparams.Int(r, "applicationID")
+		if err != nil {
+			skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		var data ApplicationReviewEditData
+		data.ListURL = listSection
+		data.UpdateURL = fmt.Sprintf("%s/%d/update", reviewSection, applicationID)
+		data.SubmitURL = fmt.Sprintf("%s/%d/submit", reviewSection, applicationID)
+		data.Review, err = skylb.MyApplicationReview(applicationID, user, role)
+		if err != nil {
+			if errors.Is(err, ErrApplicationReviewNotExist) {
+				skylb.NotAuthorized(w, r)
+				return
+			}
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		va := tables.V_APPLICATIONS()
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(va).
+			Where(va.APPLICATION_ID.EqInt(applicationID)).
+			SelectRowx((&data.Application).RowMapper(va)).
+			Fetch(skylb.DB)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		data.ReviewForm, err = skylb.ApplicationReviewForm(data.Application.Cohort)
+		if err != nil && !errors.Is(err, ErrApplicationReviewFormNotExist) {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		r, data.FormErrors = skylb.GetFormErrors(w, r)
+		if data.FormErrors != nil {
+			// Show the answers the user just posted instead of the saved ones
+			data.Review.Answers = formx.ExtractAnswers(r.Form, data.ReviewForm.Questions)
+		}
+		funcs := formx.Funcs(nil, skylb.Policy)
+		skylb.Render(w, r, data, funcs, "app/skylab/application_review_edit.html",
+			"helpers/formx/render_form.html", "helpers/formx/render_form_results.html")
+	}
+}
+
+// ApplicationReviewUpdate saves the review of an application posted by the
+// reviewer assigned to it in the role. If submit is true, the review must be
+// complete and is submitted, after which it counts towards the ranking of the
+// application.
+func (skylb Skylab) ApplicationReviewUpdate(role string, submit bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			skylb.Log.TraceRequest(r)
+			user, _ := r.Context().Value(ContextUser).(User)
+			_ = formutil.ParseForm(r)
+			applicationID, err := urlparams.Int(r, "applicationID")
+			if err != nil {
+				skylb.BadRequest(w, r, err.Error())
+				return
+			}
+			review, err := skylb.MyApplicationReview(applicationID, user, role)
+			if err != nil {
+				if errors.Is(err, ErrApplicationReviewNotExist) {
+					skylb.NotAuthorized(w, r)
+					return
+				}
+				skylb.InternalServerError(w, r, err)
+				return
+			}
+			form, err := skylb.ApplicationReviewForm(review.Application.Cohort)
+			if err != nil {
+				skylb.InternalServerError(w, r, err)
+				return
+			}
+			review.Answers = formx.ExtractAnswers(r.Form, form.Questions)
+			validate := formx.ValidateDraft
+			if submit || review.Submitted {
+				validate = formx.Validate
+			}
+			if errs := validate(form.Questions, review.Answers); errs != nil {
+				skylb.ApplicationReviewEdit(role)(w, SetFormErrors(r, errs))
+				return
+			}
+			err = skylb.SaveApplicationReview(review, form, submit)
+			if err != nil {
+				skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs := make(map[string][]string)
+			if submit {
+				msgs[flash.Success] = []string{"Review submitted"}
+			} else {
+				msgs[flash.Success] = []string{"Review saved"}
+			}
+			r, _ = skylb.SetFlashMsgs(w, r, msgs)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package skylab
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRankApplications(t *testing.T) {
+	is := is.New(t)
+	review := func(applicationID, reviewer int, score float64) ApplicationReview {
+		return ApplicationReview{
+			Application:        Application{ApplicationID: applicationID},
+			ReviewerUserRoleID: reviewer,
+			Score:              sql.NullFloat64{Float64: score, Valid: true},
+			Submitted:          true,
+		}
+	}
+	reviews := []ApplicationReview{
+		// reviewer 1 scores everything high, reviewer 2 everything low
+		review(1, 1, 10), review(2, 1, 8),
+		review(2, 2, 4), review(3, 2, 2),
+		// unsubmitted reviews do not count
+		{Application: Application{ApplicationID: 3}, ReviewerUserRoleID: 1, Score: sql.NullFloat64{Float64: 100, Valid: true}},
+		{Application: Application{ApplicationID: 4}, ReviewerUserRoleID: 1},
+	}
+	scores := RankApplications(reviews)
+	is.Equal(len(scores), 4)
+
+	is.Equal(scores[1].Rank, 1)
+	is.Equal(scores[1].Mean, 10.0)
+	is.Equal(scores[1].Normalized, 1.0)
+
+	is.Equal(scores[2].Rank, 2)
+	is.Equal(scores[2].Reviews, 2)
+	is.Equal(scores[2].Mean, 6.0)
+	is.Equal(scores[2].Normalized, 0.0)
+
+	// application 3's raw score of 2 is the best its reviewer gave
+	is.Equal(scores[3].Rank, 3)
+	is.Equal(scores[3].Assigned, 2)
+	is.Equal(scores[3].Reviews, 1)
+	is.Equal(scores[3].Normalized, -1.0)
+
+	is.Equal(scores[4].Rank, 0) // no reviews yet
+	is.Equal(scores[4].Assigned, 1)
+}
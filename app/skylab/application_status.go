@@ -0,0 +1,94 @@
+package skylab
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// ApplicationStatusChange is a status transition of an application, recorded
+// by trg.application_status_history (see
+// sql/triggers/application_status_history.sql).
+type ApplicationStatusChange struct {
+	OldStatus string // empty for the status the application was created with
+	NewStatus string
+	ChangedBy User
+	CreatedAt sql.NullTime
+}
+
+// ApplicationStatusHistory returns the status transitions of an application,
+// oldest first.
+func (skylb Skylab) ApplicationStatusHistory(applicationID int) ([]ApplicationStatusChange, error) {
+	var history []ApplicationStatusChange
+	var change ApplicationStatusChange
+	ash, u := tables.APPLICATION_STATUS_HISTORY(), tables.USERS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(ash).
+		LeftJoin(u, u.USER_ID.Eq(ash.CHANGED_BY)).
+		Where(ash.APPLICATION_ID.EqInt(applicationID)).
+		OrderBy(ash.CREATED_AT, ash.APPLICATION_STATUS_HISTORY_ID).
+		Selectx(func(row *sq.Row) {
+			change = ApplicationStatusChange{
+				OldStatus: row.String(ash.OLD_STATUS),
+				NewStatus: row.String(ash.NEW_STATUS),
+				ChangedBy: User{
+					Valid:       row.IntValid(u.USER_ID),
+					UserID:      row.Int(u.USER_ID),
+					Displayname: row.String(u.DISPLAYNAME),
+					Email:       row.String(u.EMAIL),
+				},
+				CreatedAt: row.NullTime(ash.CREATED_AT),
+			}
+		}, func() {
+			history = append(history, change)
+		}).
+		Fetch(skylb.DB)
+	return history, erro.Wrap(err)
+}
+
+// SendApplicationDecisionEmail emails both applicants of an application the
+// decision that was published on it.
+func (skylb Skylab) SendApplicationDecisionEmail(application Application) error {
+	var to []string
+	var names []string
+	for _, applicant := range []User{application.Applicant1, application.Applicant2} {
+		if applicant.Valid {
+			to = append(to, applicant.Email)
+			names = append(names, html.EscapeString(applicant.Displayname))
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	var decision string
+	switch application.Status {
+	case ApplicationStatusAccepted:
+		decision = "Congratulations, your application has been <b>accepted</b>! You can now log in to Skylab as a student."
+	case ApplicationStatusWaitlisted:
+		decision = "Your application has been <b>waitlisted</b>. We will let you know if a place opens up for your team."
+	case ApplicationStatusRejected:
+		decision = "We regret to inform you that your application has <b>not been accepted</b>."
+	default:
+		decision = fmt.Sprintf("The status of your application is now <b>%s</b>.", application.Status)
+	}
+	link := skylb.BaseURLWithProtocol() + "/applicant/application"
+	subject := fmt.Sprintf("Your Orbital %s application", application.Cohort)
+	message := fmt.Sprintf(`<p>Hi %s,</p>
+<p>%s</p>
+<p>You can see the history of your application on <a href="%s">your application page</a>.</p>`, joinNames(names), decision, link)
+	return erro.Wrap(skylb.SendMail(to, subject, message))
+}
+
+func joinNames(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	}
+	return fmt.Sprintf("%s and %s", names[0], names[1])
+}
@@ -121,9 +121,11 @@ func addRole(data map[string]interface{}) map[string]interface{} {
 // ApplicationStatus consts correspond to the statuses present inside the
 // applications_status_enum table in the database
 const (
-	ApplicationStatusPending  = "pending"
-	ApplicationStatusAccepted = "accepted"
-	ApplicationStatusDeleted  = "deleted"
+	ApplicationStatusPending    = "pending"
+	ApplicationStatusAccepted   = "accepted"
+	ApplicationStatusDeleted    = "deleted"
+	ApplicationStatusRejected   = "rejected"
+	ApplicationStatusWaitlisted = "waitlisted"
 )
 
 func ApplicationStatuses() []string {
@@ -131,6 +133,19 @@ func ApplicationStatuses() []string {
 		ApplicationStatusPending,
 		ApplicationStatusAccepted,
 		ApplicationStatusDeleted,
+		ApplicationStatusRejected,
+		ApplicationStatusWaitlisted,
+	}
+}
+
+// ApplicationDecisions returns the statuses an admin can stage as the decision
+// on an application.
+func ApplicationDecisions() []string {
+	return []string{
+		ApplicationStatusAccepted,
+		ApplicationStatusWaitlisted,
+		ApplicationStatusRejected,
+		ApplicationStatusPending,
 	}
 }
 
@@ -143,6 +158,8 @@ func addConstApplicationStatus(funcs template.FuncMap) template.FuncMap {
 	funcs["ApplicationStatusPending"] = func() string { return ApplicationStatusPending }
 	funcs["ApplicationStatusAccepted"] = func() string { return ApplicationStatusAccepted }
 	funcs["ApplicationStatusDeleted"] = func() string { return ApplicationStatusDeleted }
+	funcs["ApplicationStatusRejected"] = func() string { return ApplicationStatusRejected }
+	funcs["ApplicationStatusWaitlisted"] = func() string { return ApplicationStatusWaitlisted }
 	return funcs
 }
 
@@ -154,6 +171,8 @@ func addApplicationStatus(data map[string]interface{}) map[string]interface{} {
 	data["ApplicationStatusPending"] = ApplicationStatusPending
 	data["ApplicationStatusAccepted"] = ApplicationStatusAccepted
 	data["ApplicationStatusDeleted"] = ApplicationStatusDeleted
+	data["ApplicationStatusRejected"] = ApplicationStatusRejected
+	data["ApplicationStatusWaitlisted"] = ApplicationStatusWaitlisted
 	return data
 }
 
@@ -305,12 +324,14 @@ func addMilestone(data map[string]interface{}) map[string]interface{} {
 const (
 	ApplicationSubsectionApplication = "application"
 	ApplicationSubsectionApplicant   = "applicant"
+	ApplicationSubsectionReview      = "review" // the rubric reviewers score applications with
 )
 
 func ApplicationSubsections() []string {
 	return []string{
 		ApplicationSubsectionApplication,
 		ApplicationSubsectionApplicant,
+		ApplicationSubsectionReview,
 	}
 }
 
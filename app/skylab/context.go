@@ -14,6 +14,7 @@ const (
 	ContextCurrentMilestone skylabContext = "ContextCurrentMilestone" // string
 	ContextDumpJson         skylabContext = "ContextDumpJson"         // bool
 	ContextIsProd           skylabContext = "ContextIsProd"           // bool
+	ContextAPIToken         skylabContext = "ContextAPIToken"         // skylab.APIToken
 
 	// Submission
 	ContextCanViewSubmission skylabContext = "ContextCanViewSubmission" // bool
@@ -34,6 +35,7 @@ const (
 	// Form
 	ContextCanViewForm skylabContext = "ContextCanViewForm" // bool
 	ContextCanEditForm skylabContext = "ContextCanEditForm" // bool
+	ContextFormErrors  skylabContext = "ContextFormErrors"  // formx.ValidationErrors
 
 	// Application
 	ContextCanViewApplication skylabContext = "ContextCanViewApplication" // bool
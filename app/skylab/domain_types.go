@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/helpers/formx"
@@ -28,6 +29,32 @@ type Period struct {
 	EndAt     sql.NullTime `db:"end_at"`
 }
 
+func (p *Period) RowMapper(tbl tables.TABLE_PERIODS) func(*sq.Row) {
+	return func(row *sq.Row) {
+		*p = Period{
+			Valid:     row.IntValid(tbl.PERIOD_ID),
+			PeriodID:  row.Int(tbl.PERIOD_ID),
+			Cohort:    row.String(tbl.COHORT),
+			Stage:     row.String(tbl.STAGE),
+			Milestone: row.String(tbl.MILESTONE),
+			StartAt:   row.NullTime(tbl.START_AT),
+			EndAt:     row.NullTime(tbl.END_AT),
+		}
+	}
+}
+
+// Open reports whether now falls within the period. A period without a start
+// (or end) is open from (or until) any time.
+func (p Period) Open(now time.Time) bool {
+	if p.StartAt.Valid && now.Before(p.StartAt.Time) {
+		return false
+	}
+	if p.EndAt.Valid && !now.Before(p.EndAt.Time) {
+		return false
+	}
+	return true
+}
+
 type Team struct {
 	Valid        bool
 	TeamID       int
@@ -54,18 +81,22 @@ func (t *Team) RowMapper(tbl tables.VIEW_V_TEAMS) func(*sq.Row) {
 		t.Student1.Valid = row.IntValid(tbl.STUDENT1_USER_ID)
 		t.Student1.UserID = row.Int(tbl.STUDENT1_USER_ID)
 		t.Student1.Displayname = row.String(tbl.STUDENT1_DISPLAYNAME)
+		t.Student1.Roles = map[string]int{RoleStudent: row.Int(tbl.STUDENT1_USER_ROLE_ID)}
 		// Student2
 		t.Student2.Valid = row.IntValid(tbl.STUDENT2_USER_ID)
 		t.Student2.UserID = row.Int(tbl.STUDENT2_USER_ID)
 		t.Student2.Displayname = row.String(tbl.STUDENT2_DISPLAYNAME)
+		t.Student2.Roles = map[string]int{RoleStudent: row.Int(tbl.STUDENT2_USER_ROLE_ID)}
 		// Adviser
 		t.Adviser.Valid = row.IntValid(tbl.ADVISER_USER_ID)
 		t.Adviser.UserID = row.Int(tbl.ADVISER_USER_ID)
 		t.Adviser.Displayname = row.String(tbl.ADVISER_DISPLAYNAME)
+		t.Adviser.Roles = map[string]int{RoleAdviser: row.Int(tbl.ADVISER_USER_ROLE_ID)}
 		// Mentor
 		t.Mentor.Valid = row.IntValid(tbl.MENTOR_USER_ID)
 		t.Mentor.UserID = row.Int(tbl.MENTOR_USER_ID)
 		t.Mentor.Displayname = row.String(tbl.MENTOR_DISPLAYNAME)
+		t.Mentor.Roles = map[string]int{RoleMentor: row.Int(tbl.MENTOR_USER_ROLE_ID)}
 	}
 }
 
@@ -76,6 +107,7 @@ type Form struct {
 	Name       string
 	Subsection string
 	Questions  formx.Questions
+	Version    int // bumped every time the questions change
 }
 
 func (fs Form) Title() string {
@@ -211,6 +243,12 @@ func (s *Submission) RowMapper(tbl tables.VIEW_V_SUBMISSIONS) func(*sq.Row) {
 	}
 }
 
+// Open reports whether the submission can still be changed, either because
+// its submission period is not over or because an admin has reopened it.
+func (s Submission) Open() bool {
+	return s.OverrideOpen || s.SubmissionForm.Period.Open(time.Now())
+}
+
 // A TeamEvaluation is carried out by a Team on a Team
 type TeamEvaluation struct {
 	Valid             bool
@@ -231,7 +269,7 @@ func (e *TeamEvaluation) RowMapper(tbl tables.VIEW_V_TEAM_EVALUATIONS) func(*sq.
 			TeamEvaluationID: row.Int(tbl.TEAM_EVALUATION_ID),
 			Evaluator: Team{
 				Valid:        row.IntValid(tbl.EVALUATOR_TEAM_ID),
-				TeamID:       row.Int(tbl.EVALUATEE_TEAM_ID),
+				TeamID:       row.Int(tbl.EVALUATOR_TEAM_ID),
 				TeamName:     row.String(tbl.EVALUATOR_TEAM_NAME),
 				ProjectLevel: row.String(tbl.EVALUATOR_PROJECT_LEVEL),
 			},
@@ -374,4 +412,5 @@ type UserFeedback struct {
 	FeedbackAnswers  formx.Answers
 	Submitted        bool
 	OverrideOpen     bool
+	UpdatedAt        sql.NullTime
 }
@@ -18,6 +18,18 @@ const (
 	ErrNotAnAdviser   erro.BaseError = "OKDRA User %+v is not an adviser"
 	ErrNotAnAdmin     erro.BaseError = "OD6HR User %+v is not an admin"
 	ErrNoPeerTeams    erro.BaseError = "OO6BX Team %+v has no peer teams"
+	ErrNotARole       erro.BaseError = "OLAQR User %+v does not have the role '%s'"
+
+	// Passwords
+	ErrPasswordTooShort     erro.BaseError = "OLAPS Password must be at least %d characters long"
+	ErrPasswordTooLong      erro.BaseError = "OLAPL Password must be at most %d characters long"
+	ErrPasswordTokenInvalid erro.BaseError = "OLAPT Password link is invalid, has expired or has already been used"
+
+	// Two-factor authentication
+	ErrTOTPCodeInvalid    erro.BaseError = "OLATQ Two-factor authentication code is invalid or has already been used"
+	ErrTOTPNotSetUp       erro.BaseError = "OLATU Two-factor authentication has not been set up"
+	ErrTOTPAlreadyEnabled erro.BaseError = "OLATE Two-factor authentication is already enabled"
+	ErrTOTPRequired       erro.BaseError = "OLATR Two-factor authentication is required for admins and cannot be disabled"
 
 	// Skylab Enums
 	ErrCohortInvalid       erro.BaseError = "OLALE Cohort '%s' is not a valid Skylab cohort"
@@ -25,10 +37,13 @@ const (
 	ErrMilestoneInvalid    erro.BaseError = "OLADN Milestone '%s' is not a valid Skylab milestone"
 	ErrRoleInvalid         erro.BaseError = "OLAZN Role '%s' is not a valid Skylab role"
 	ErrProjectLevelInvalid erro.BaseError = "OEHGC Project Level '%s' is not a valid Skylab Project Level"
+	ErrTeamStatusInvalid   erro.BaseError = "OEHTS Team Status '%s' is not a valid Skylab team status"
 
 	// Forms
 	ErrSubmissionFormNotExist  erro.BaseError = "OYOE8 Submission form doesn't exist"
 	ErrApplicationFormNotExist erro.BaseError = "OC8BK Application Form doesn't exist"
+	ErrFormFileFormat          erro.BaseError = "OYOEF Form file '%s' is not a .json, .yaml or .yml file"
+	ErrFormOrphansAnswers      erro.BaseError = "OYOEA Importing the form would orphan existing answers to %s, list the old names under PreviousNames of the renamed questions or choose to discard the answers"
 
 	// APPLICATION C8
 	ErrApplicationNotExist                  erro.BaseError = "OC8U9 Application doesn't exist"
@@ -41,12 +56,23 @@ const (
 	ErrApplicationDeleted                   erro.BaseError = "OC8W6 Application {application_id:%d} already accepted/deleted"
 	ErrApplicationIncomplete                erro.BaseError = "OC8KH Tried accepting an incomplete application"
 	ErrApplicationNoTeam                    erro.BaseError = "OC8R1 Tried un-accepting an application that had never been accepted"
+	ErrApplicationReviewFormNotExist        erro.BaseError = "OC8RF Cohort {cohort:%s} has no application review form"
+	ErrApplicationReviewNotExist            erro.BaseError = "OC8RV Application {application_id:%d} is not assigned to reviewer {user_id:%d}"
+	ErrApplicationReviewerInvalid           erro.BaseError = "OC8RI User role {user_role_id:%d} cannot review application {application_id:%d}"
+	ErrNotInMatchmakingPool                 erro.BaseError = "OC8MP Applicant {user_role_id:%d} is not looking for a partner"
+	ErrMatchRequestNotExist                 erro.BaseError = "OC8MR Matchmaking request {matchmaking_request_id:%d} does not exist or has already been answered"
+	ErrMatchRequestDeclined                 erro.BaseError = "OC8MD Applicant {user_role_id:%d} has already declined a request from you"
+	ErrTeamMeetingNotExist                  erro.BaseError = "OC8TM Team meeting {team_meeting_id:%d} does not exist or was not logged by adviser {user_role_id:%d}"
+	ErrTeamNotAdvised                       erro.BaseError = "OC8TA Team {team_id:%d} is not advised by adviser {user_role_id:%d}"
 
 	// Misc
 	ErrStudentNoTeam           erro.BaseError = "ONXDI Student {uid:%d} does not belong to any team"
 	ErrTeamMoreThanTwoStudents erro.BaseError = "OYSGQ Team {tid:%d} has more than two students"
 	ErrEmailNotAuthorized      erro.BaseError = "OLALP Email '%s' is not authorized to signup for any role"
 	ErrEmailEmpty              erro.BaseError = "OLAR9 Email must be non-empty"
+	ErrSubmissionClosed        erro.BaseError = "OQ7WC Submission {submission_id:%d} can no longer be changed as its submission period is over"
+	ErrSubmissionChanged       erro.BaseError = "OQ7WS Submission {submission_id:%d} was changed by someone else since the page was loaded, please reload it and try again"
+	ErrTeamStatusReasonEmpty   erro.BaseError = "OWHZR A reason must be given for changing the status of team {team_id:%d}"
 
 	// Not exist
 	ErrUserNotExist           erro.BaseError = "OLAMC User does not exist: %s"
@@ -59,4 +85,5 @@ const (
 	ErrUserFeedbackNotExist   erro.BaseError = "OXZW4 User Feedback does not exist: %s"
 	ErrFormNotExist           erro.BaseError = "OLAJX Form does not exist: %s"
 	ErrPeriodNotExist         erro.BaseError = "OLAEE Period does not exist: %s"
+	ErrRevisionNotExist       erro.BaseError = "OQ7WR Submission {submission_id:%d} has no revision %d"
 )
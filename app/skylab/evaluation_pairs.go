@@ -0,0 +1,150 @@
+package skylab
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+)
+
+// EvaluationPair is a row of team_evaluation_pairs: the Evaluator team peer
+// evaluates the Evaluatee team.
+type EvaluationPair struct {
+	Evaluatee Team
+	Evaluator Team
+}
+
+// evaluationPairsHeader is the header row of the evaluation pairs CSV.
+var evaluationPairsHeader = []string{"Evaluatee", "Evaluator"}
+
+// WriteEvaluationPairsCSV writes the pairs as CSV, one pair per row. Teams are
+// identified by their team names so that the file can be edited by hand and
+// read back with ParseEvaluationPairsCSV.
+func WriteEvaluationPairsCSV(w io.Writer, pairs []EvaluationPair) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(evaluationPairsHeader)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	for _, pair := range pairs {
+		err = cw.Write([]string{pair.Evaluatee.TeamName, pair.Evaluator.TeamName})
+		if err != nil {
+			return erro.Wrap(err)
+		}
+	}
+	cw.Flush()
+	return erro.Wrap(cw.Error())
+}
+
+// ParseEvaluationPairsCSV reads the team name pairs written by
+// WriteEvaluationPairsCSV and looks the team names up (case insensitively) in
+// teams. The header row is optional and duplicate pairs are dropped.
+//
+// A row is rejected and described in problems if it does not have exactly two
+// team names, names a team that is not in teams (or a name shared by several
+// of them), pairs a team with itself or pairs teams of different cohorts.
+// Rows are numbered from 1, not counting blank lines. Parsing stops at the
+// first malformed CSV row.
+func ParseEvaluationPairsCSV(r io.Reader, teams []Team) (pairs []EvaluationPair, problems []string) {
+	teamsByName := make(map[string][]Team)
+	for _, team := range teams {
+		name := strings.ToLower(strings.TrimSpace(team.TeamName))
+		teamsByName[name] = append(teamsByName[name], team)
+	}
+	lookup := func(name string) (Team, string) {
+		matches := teamsByName[strings.ToLower(name)]
+		switch len(matches) {
+		case 0:
+			return Team{}, fmt.Sprintf("%q is not one of your teams", name)
+		case 1:
+			return matches[0], ""
+		default:
+			return Team{}, fmt.Sprintf("%q is the name of more than one of your teams", name)
+		}
+	}
+	seen := make(map[[2]int]bool)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			problems = append(problems, err.Error())
+			break
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		if len(record) == 1 && record[0] == "" {
+			continue
+		}
+		if row == 1 && len(record) == 2 &&
+			strings.EqualFold(record[0], evaluationPairsHeader[0]) &&
+			strings.EqualFold(record[1], evaluationPairsHeader[1]) {
+			continue
+		}
+		if len(record) != 2 {
+			problems = append(problems, fmt.Sprintf("row %d: expected 2 team names, got %d", row, len(record)))
+			continue
+		}
+		evaluatee, problem := lookup(record[0])
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("row %d: %s", row, problem))
+			continue
+		}
+		evaluator, problem := lookup(record[1])
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("row %d: %s", row, problem))
+			continue
+		}
+		if evaluatee.TeamID == evaluator.TeamID {
+			problems = append(problems, fmt.Sprintf("row %d: %q cannot evaluate itself", row, record[0]))
+			continue
+		}
+		if evaluatee.Cohort != evaluator.Cohort {
+			problems = append(problems, fmt.Sprintf(
+				"row %d: %q (cohort %s) and %q (cohort %s) are not in the same cohort",
+				row, record[0], evaluatee.Cohort, record[1], evaluator.Cohort,
+			))
+			continue
+		}
+		key := [2]int{evaluatee.TeamID, evaluator.TeamID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		pairs = append(pairs, EvaluationPair{Evaluatee: evaluatee, Evaluator: evaluator})
+	}
+	return pairs, problems
+}
+
+// DiffEvaluationPairs compares the current pairs with the wanted pairs and
+// returns the pairs that have to be added and removed to go from one to the
+// other. Pairs are compared by their team IDs.
+func DiffEvaluationPairs(current, wanted []EvaluationPair) (added, removed []EvaluationPair) {
+	key := func(pair EvaluationPair) [2]int {
+		return [2]int{pair.Evaluatee.TeamID, pair.Evaluator.TeamID}
+	}
+	isCurrent := make(map[[2]int]bool)
+	for _, pair := range current {
+		isCurrent[key(pair)] = true
+	}
+	isWanted := make(map[[2]int]bool)
+	for _, pair := range wanted {
+		if !isCurrent[key(pair)] && !isWanted[key(pair)] {
+			added = append(added, pair)
+		}
+		isWanted[key(pair)] = true
+	}
+	for _, pair := range current {
+		if !isWanted[key(pair)] {
+			removed = append(removed, pair)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,67 @@
+package skylab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestEvaluationPairsCSV(t *testing.T) {
+	is := is.New(t)
+	rocket := Team{TeamID: 1, Cohort: "2020", TeamName: "Rocket"}
+	lander := Team{TeamID: 2, Cohort: "2020", TeamName: "Lander, Mk II"}
+	rover := Team{TeamID: 3, Cohort: "2020", TeamName: "Rover"}
+	probe := Team{TeamID: 4, Cohort: "2019", TeamName: "Probe"}
+	teams := []Team{rocket, lander, rover, probe}
+
+	// What is exported can be imported back
+	var b strings.Builder
+	err := WriteEvaluationPairsCSV(&b, []EvaluationPair{
+		{Evaluatee: rocket, Evaluator: lander},
+		{Evaluatee: lander, Evaluator: rocket},
+	})
+	is.NoErr(err)
+	is.Equal(b.String(), "Evaluatee,Evaluator\nRocket,\"Lander, Mk II\"\n\"Lander, Mk II\",Rocket\n")
+	pairs, problems := ParseEvaluationPairsCSV(strings.NewReader(b.String()), teams)
+	is.Equal(len(problems), 0)
+	is.Equal(pairs, []EvaluationPair{
+		{Evaluatee: rocket, Evaluator: lander},
+		{Evaluatee: lander, Evaluator: rocket},
+	})
+
+	// Invalid rows are reported and skipped
+	pairs, problems = ParseEvaluationPairsCSV(strings.NewReader(
+		"rocket, rover\n"+
+			"Rocket,Rover\n"+
+			"\n"+
+			"Rocket,Rocket\n"+
+			"Rocket,Probe\n"+
+			"Rocket,Comet\n"+
+			"Rover\n",
+	), teams)
+	is.Equal(pairs, []EvaluationPair{{Evaluatee: rocket, Evaluator: rover}})
+	is.Equal(problems, []string{
+		`row 3: "Rocket" cannot evaluate itself`,
+		`row 4: "Rocket" (cohort 2020) and "Probe" (cohort 2019) are not in the same cohort`,
+		`row 5: "Comet" is not one of your teams`,
+		`row 6: expected 2 team names, got 1`,
+	})
+}
+
+func TestDiffEvaluationPairs(t *testing.T) {
+	is := is.New(t)
+	a, b, c := Team{TeamID: 1}, Team{TeamID: 2}, Team{TeamID: 3}
+	current := []EvaluationPair{
+		{Evaluatee: a, Evaluator: b},
+		{Evaluatee: b, Evaluator: a},
+	}
+	wanted := []EvaluationPair{
+		{Evaluatee: a, Evaluator: b},
+		{Evaluatee: c, Evaluator: a},
+		{Evaluatee: c, Evaluator: a},
+	}
+	added, removed := DiffEvaluationPairs(current, wanted)
+	is.Equal(added, []EvaluationPair{{Evaluatee: c, Evaluator: a}})
+	is.Equal(removed, []EvaluationPair{{Evaluatee: b, Evaluator: a}})
+}
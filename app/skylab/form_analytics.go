@@ -0,0 +1,81 @@
+package skylab
+
+import (
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+)
+
+// ResponseFilter narrows down the responses to a form that are analyzed. Zero
+// values mean no filtering.
+type ResponseFilter struct {
+	Cohort       string
+	ProjectLevel string
+	// IncludeDrafts includes the responses that have not been submitted yet
+	IncludeDrafts bool
+}
+
+// FormResponses returns every response to a form, regardless of which table
+// it is stored in. The project level and cohort of a response are those of
+// the application or team it belongs to: the team being evaluated for
+// evaluations and feedback on teams, the team giving the feedback for feedback
+// on users, and the application being reviewed for application reviews.
+func (skylb Skylab) FormResponses(formID int, filter ResponseFilter) ([]formx.Answers, error) {
+	rows, err := skylb.DB.Query(`
+	SELECT responses.data
+	FROM (
+		SELECT a.application_data AS data, a.submitted, a.project_level, a.cohort
+		FROM applications AS a
+		WHERE a.application_form_id = $1
+		UNION ALL
+		SELECT ura.applicant_data, COALESCE(a.submitted, FALSE), a.project_level, a.cohort
+		FROM user_roles_applicants AS ura LEFT JOIN applications AS a ON a.application_id = ura.application_id
+		WHERE ura.applicant_form_id = $1
+		UNION ALL
+		SELECT s.submission_data, s.submitted, t.project_level, t.cohort
+		FROM submissions AS s JOIN teams AS t ON t.team_id = s.team_id
+		WHERE s.submission_form_id = $1
+		UNION ALL
+		SELECT te.evaluation_data, te.submitted, t.project_level, t.cohort
+		FROM team_evaluations AS te
+		JOIN submissions AS s ON s.submission_id = te.evaluatee_submission_id
+		JOIN teams AS t ON t.team_id = s.team_id
+		WHERE te.evaluation_form_id = $1
+		UNION ALL
+		SELECT ue.evaluation_data, ue.submitted, t.project_level, t.cohort
+		FROM user_evaluations AS ue
+		JOIN submissions AS s ON s.submission_id = ue.evaluatee_submission_id
+		JOIN teams AS t ON t.team_id = s.team_id
+		WHERE ue.evaluation_form_id = $1
+		UNION ALL
+		SELECT fot.feedback_data, fot.submitted, t.project_level, t.cohort
+		FROM feedback_on_teams AS fot JOIN teams AS t ON t.team_id = fot.evaluatee_team_id
+		WHERE fot.feedback_form_id = $1
+		UNION ALL
+		SELECT fou.feedback_data, fou.submitted, t.project_level, t.cohort
+		FROM feedback_on_users AS fou JOIN teams AS t ON t.team_id = fou.evaluator_team_id
+		WHERE fou.feedback_form_id = $1
+		UNION ALL
+		SELECT ar.review_data, ar.submitted, a.project_level, a.cohort
+		FROM application_reviews AS ar JOIN applications AS a ON a.application_id = ar.application_id
+		WHERE ar.review_form_id = $1
+	) AS responses
+	WHERE responses.data IS NOT NULL
+		AND ($2 OR responses.submitted)
+		AND ($3 = '' OR responses.project_level = $3)
+		AND ($4 = '' OR responses.cohort = $4)
+	`, formID, filter.IncludeDrafts, filter.ProjectLevel, filter.Cohort)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	defer rows.Close()
+	var responses []formx.Answers
+	for rows.Next() {
+		var answers formx.Answers
+		err = rows.Scan(&answers)
+		if err != nil {
+			return responses, erro.Wrap(err)
+		}
+		responses = append(responses, answers)
+	}
+	return responses, erro.Wrap(rows.Err())
+}
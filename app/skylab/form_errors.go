@@ -0,0 +1,32 @@
+package skylab
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+)
+
+// FormErrorsMsg is the flash message shown above a form that failed
+// validation, the errors for each question are shown under the question itself.
+const FormErrorsMsg = "Some answers need fixing before they can be saved, see the messages below"
+
+// SetFormErrors stores the validation errors of a posted form in the request
+// context. The form's edit page is then called directly (instead of
+// redirecting to it) so that it can re-render the form using the user's input
+// in r.Form together with the errors, see GetFormErrors.
+func SetFormErrors(r *http.Request, errs formx.ValidationErrors) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ContextFormErrors, errs))
+}
+
+// GetFormErrors gets the validation errors set by SetFormErrors. If there are
+// any, it also adds the FormErrorsMsg flash message to the request.
+func (skylb Skylab) GetFormErrors(w http.ResponseWriter, r *http.Request) (*http.Request, formx.ValidationErrors) {
+	errs, _ := r.Context().Value(ContextFormErrors).(formx.ValidationErrors)
+	if len(errs) == 0 {
+		return r, nil
+	}
+	r, _ = skylb.SetFlashMsgs(w, r, map[string][]string{flash.Error: {FormErrorsMsg}})
+	return r, errs
+}
@@ -0,0 +1,266 @@
+package skylab
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/tables"
+	"gopkg.in/yaml.v3"
+)
+
+// FormFile is the definition of a form as a file, so that forms can be kept in
+// version control and moved between databases. A form is identified by its
+// period (Cohort, Stage, Milestone), Name and Subsection.
+type FormFile struct {
+	Cohort          string          `json:"Cohort"`
+	Stage           string          `json:"Stage"`
+	Milestone       string          `json:"Milestone"`
+	Name            string          `json:"Name"`
+	Subsection      string          `json:"Subsection"`
+	AuthorizedRoles []string        `json:"AuthorizedRoles"`
+	Questions       formx.Questions `json:"Questions"`
+}
+
+// FormFileFormat consts are the file formats a FormFile can be written in
+const (
+	FormFileFormatJSON = "json"
+	FormFileFormatYAML = "yaml"
+)
+
+// FormFileFormatOf returns the FormFileFormat of a file based on its extension.
+func FormFileFormatOf(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormFileFormatJSON, nil
+	case ".yaml", ".yml":
+		return FormFileFormatYAML, nil
+	}
+	return "", erro.Errorf(ErrFormFileFormat, filepath.Base(filename))
+}
+
+// Filename returns a filename for the form file that is unique among the forms
+// of every cohort e.g. 2020_submission_milestone1.yaml
+func (ff FormFile) Filename(format string) string {
+	var parts []string
+	for _, part := range []string{ff.Cohort, ff.Stage, ff.Milestone, ff.Name, ff.Subsection} {
+		if part != "" {
+			parts = append(parts, strings.Map(func(r rune) rune {
+				if strings.ContainsRune(`/\:*?"<>| `, r) {
+					return '_'
+				}
+				return r
+			}, part))
+		}
+	}
+	return strings.Join(parts, "_") + "." + format
+}
+
+// MarshalFormFile writes the form file in the given FormFileFormat. The YAML
+// uses the same keys as the JSON.
+func MarshalFormFile(ff FormFile, format string) ([]byte, error) {
+	b, err := json.MarshalIndent(ff, "", "  ")
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	if format == FormFileFormatJSON {
+		return b, nil
+	}
+	// JSON is valid YAML, so parsing it as YAML and re-marshalling it in the
+	// block style converts it while keeping the order of the keys
+	var node yaml.Node
+	err = yaml.Unmarshal(b, &node)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	var resetStyle func(*yaml.Node)
+	resetStyle = func(node *yaml.Node) {
+		node.Style = 0
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		for _, child := range node.Content {
+			resetStyle(child)
+		}
+	}
+	resetStyle(&node)
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&node)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	return buf.Bytes(), erro.Wrap(encoder.Close())
+}
+
+// UnmarshalFormFile reads a form file written in the given FormFileFormat.
+// Unknown keys are rejected so that typos do not go unnoticed.
+func UnmarshalFormFile(b []byte, format string) (FormFile, error) {
+	var ff FormFile
+	if format == FormFileFormatYAML {
+		var v interface{}
+		err := yaml.Unmarshal(b, &v)
+		if err != nil {
+			return ff, erro.Wrap(err)
+		}
+		b, err = json.Marshal(v)
+		if err != nil {
+			return ff, erro.Wrap(err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&ff)
+	return ff, erro.Wrap(err)
+}
+
+// Check makes sure the form file refers to a valid period and roles, and that
+// its questions are well formed (see formx.Questions.Check).
+func (ff FormFile) Check(cohorts []string) error {
+	if !Contains(cohorts, ff.Cohort) {
+		return erro.Errorf(ErrCohortInvalid, ff.Cohort)
+	}
+	if !Contains(Stages(), ff.Stage) {
+		return erro.Errorf(ErrStageInvalid, ff.Stage)
+	}
+	if !Contains(Milestones(), ff.Milestone) {
+		return erro.Errorf(ErrMilestoneInvalid, ff.Milestone)
+	}
+	for _, role := range ff.AuthorizedRoles {
+		if !Contains(Roles(), role) {
+			return erro.Errorf(ErrRoleInvalid, role)
+		}
+	}
+	return ff.Questions.Check()
+}
+
+// ExportForm returns the definition of a form as a FormFile.
+func (skylb Skylab) ExportForm(formID int) (FormFile, error) {
+	var ff FormFile
+	f, p, far := tables.FORMS(), tables.PERIODS(), tables.FORMS_AUTHORIZED_ROLES()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(f).
+		Join(p, p.PERIOD_ID.Eq(f.PERIOD_ID)).
+		Where(f.FORM_ID.EqInt(formID)).
+		SelectRowx(func(row *sq.Row) {
+			ff.Cohort = row.String(p.COHORT)
+			ff.Stage = row.String(p.STAGE)
+			ff.Milestone = row.String(p.MILESTONE)
+			ff.Name = row.String(f.NAME)
+			ff.Subsection = row.String(f.SUBSECTION)
+			row.ScanInto(&ff.Questions, f.QUESTIONS)
+		}).
+		Fetch(skylb.DB)
+	if err != nil {
+		return ff, erro.Wrap(err)
+	}
+	var role string
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(far).
+		Where(far.FORM_ID.EqInt(formID)).
+		OrderBy(far.ROLE).
+		Selectx(func(row *sq.Row) {
+			role = row.String(far.ROLE)
+		}, func() {
+			ff.AuthorizedRoles = append(ff.AuthorizedRoles, role)
+		}).
+		Fetch(skylb.DB)
+	if ff.AuthorizedRoles == nil {
+		ff.AuthorizedRoles = []string{}
+	}
+	return ff, erro.Wrap(err)
+}
+
+// ImportForm creates or updates the form described by the form file,
+// replacing its authorized roles and saving its questions as a new version
+// of the form (see SaveFormVersion), all in one transaction. PreviousNames already recorded in the
+// database are kept even if the file leaves them out.
+//
+// If the new questions would orphan existing answers, ImportForm returns an
+// ErrFormOrphansAnswers error without changing anything unless
+// discardOrphans is true.
+func (skylb Skylab) ImportForm(ff FormFile, userID int, discardOrphans bool) (formID int, version int, err error) {
+	err = ff.Check(skylb.Cohorts())
+	if err != nil {
+		return formID, version, err
+	}
+	var oldQuestions formx.Questions
+	f, p := tables.FORMS(), tables.PERIODS()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(f).
+		Join(p, p.PERIOD_ID.Eq(f.PERIOD_ID)).
+		Where(
+			p.COHORT.EqString(ff.Cohort),
+			p.STAGE.EqString(ff.Stage),
+			p.MILESTONE.EqString(ff.Milestone),
+			f.NAME.EqString(ff.Name),
+			f.SUBSECTION.EqString(ff.Subsection),
+		).
+		SelectRowx(func(row *sq.Row) {
+			formID = row.Int(f.FORM_ID)
+			row.ScanInto(&oldQuestions, f.QUESTIONS)
+		}).
+		Fetch(skylb.DB)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return formID, version, erro.Wrap(err)
+	}
+	questions := formx.CarryPreviousNames(oldQuestions, ff.Questions)
+	if formID != 0 && !discardOrphans {
+		counts, err := skylb.CountOrphanedAnswers(formID, formx.Orphans(oldQuestions, questions))
+		if err != nil {
+			return formID, version, erro.Wrap(err)
+		}
+		if len(counts) > 0 {
+			var names []string
+			for name := range counts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return formID, version, erro.Errorf(ErrFormOrphansAnswers, strings.Join(names, ", "))
+		}
+	}
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return formID, version, erro.Wrap(err)
+	}
+	err = tx.QueryRow(`
+	WITH period AS (
+		INSERT INTO periods (cohort, stage, milestone) VALUES ($1, $2, $3)
+		ON CONFLICT (cohort, stage, milestone) DO UPDATE SET cohort = EXCLUDED.cohort
+		RETURNING period_id
+	)
+	INSERT INTO forms (period_id, name, subsection) SELECT period_id, $4, $5 FROM period
+	ON CONFLICT (period_id, name, subsection) DO UPDATE SET name = EXCLUDED.name
+	RETURNING form_id
+	`, ff.Cohort, ff.Stage, ff.Milestone, ff.Name, ff.Subsection).Scan(&formID)
+	if err != nil {
+		_ = tx.Rollback()
+		return formID, version, erro.Wrap(err)
+	}
+	_, err = tx.Exec(`DELETE FROM forms_authorized_roles WHERE form_id = $1`, formID)
+	if err != nil {
+		_ = tx.Rollback()
+		return formID, version, erro.Wrap(err)
+	}
+	for _, role := range ff.AuthorizedRoles {
+		_, err = tx.Exec(`INSERT INTO forms_authorized_roles (form_id, role) VALUES ($1, $2) ON CONFLICT DO NOTHING`, formID, role)
+		if err != nil {
+			_ = tx.Rollback()
+			return formID, version, erro.Wrap(err)
+		}
+	}
+	version, err = saveFormVersion(tx, formID, questions, nil, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return formID, version, erro.Wrap(err)
+	}
+	return formID, version, erro.Wrap(tx.Commit())
+}
@@ -0,0 +1,52 @@
+package skylab
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/matryer/is"
+)
+
+func TestFormFile(t *testing.T) {
+	is := is.New(t)
+	ff := FormFile{
+		Cohort:          "2020",
+		Stage:           StageSubmission,
+		Milestone:       Milestone1,
+		AuthorizedRoles: []string{RoleStudent},
+		Questions: formx.Questions{
+			{Type: formx.QuestionTypeParagraph, Text: "<p>Submit your\nproject</p>"},
+			{Type: formx.QuestionTypeRadio, Name: "level", Options: []formx.Option{{Value: "vostok", Display: "Vostok"}}},
+			{Type: formx.QuestionTypeLongtext, Name: "readme", PreviousNames: []string{"writeup"}},
+		},
+	}
+	is.Equal(ff.Filename(FormFileFormatYAML), "2020_submission_milestone1.yaml")
+	for _, format := range []string{FormFileFormatJSON, FormFileFormatYAML} {
+		b, err := MarshalFormFile(ff, format)
+		is.NoErr(err)
+		got, err := UnmarshalFormFile(b, format)
+		is.NoErr(err)
+		is.Equal(got, ff)
+	}
+
+	b, err := MarshalFormFile(ff, FormFileFormatYAML)
+	is.NoErr(err)
+	is.True(strings.HasPrefix(string(b), "Cohort: \"2020\"\n")) // keys keep their order and names
+
+	_, err = UnmarshalFormFile([]byte("Cohort: '2020'\nQuestionz: []\n"), FormFileFormatYAML)
+	is.True(err != nil) // unknown keys are rejected
+
+	_, err = FormFileFormatOf("form.txt")
+	is.True(errors.Is(err, ErrFormFileFormat))
+	format, err := FormFileFormatOf("FORM.YML")
+	is.NoErr(err)
+	is.Equal(format, FormFileFormatYAML)
+
+	is.NoErr(ff.Check([]string{"2020"}))
+	is.True(errors.Is(ff.Check([]string{"2021"}), ErrCohortInvalid))
+	ff.Questions = append(ff.Questions, formx.Question{Type: formx.QuestionTypeShorttext, Name: "level"})
+	_, ok := ff.Check([]string{"2020"}).(formx.SchemaError)
+	is.True(ok)
+}
@@ -0,0 +1,111 @@
+package skylab
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/lib/pq"
+)
+
+// CountOrphanedAnswers counts, for each of the names, how many answers to the
+// form contain a non-empty answer stored under that name. Names without any
+// answers are left out of the result.
+func (skylb Skylab) CountOrphanedAnswers(formID int, names []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(names) == 0 {
+		return counts, nil
+	}
+	rows, err := skylb.DB.Query(`
+	SELECT kv.key, COUNT(*)
+	FROM (
+		SELECT application_data AS data FROM applications WHERE application_form_id = $1
+		UNION ALL SELECT applicant_data FROM user_roles_applicants WHERE applicant_form_id = $1
+		UNION ALL SELECT submission_data FROM submissions WHERE submission_form_id = $1
+		UNION ALL SELECT evaluation_data FROM team_evaluations WHERE evaluation_form_id = $1
+		UNION ALL SELECT evaluation_data FROM user_evaluations WHERE evaluation_form_id = $1
+		UNION ALL SELECT feedback_data FROM feedback_on_teams WHERE feedback_form_id = $1
+		UNION ALL SELECT feedback_data FROM feedback_on_users WHERE feedback_form_id = $1
+		UNION ALL SELECT review_data FROM application_reviews WHERE review_form_id = $1
+	) AS answers
+	CROSS JOIN LATERAL jsonb_each(answers.data) AS kv
+	WHERE jsonb_typeof(answers.data) = 'object'
+		AND kv.key = ANY($2)
+		AND kv.value NOT IN ('[]'::JSONB, '[""]'::JSONB)
+	GROUP BY kv.key
+	`, formID, pq.Array(names))
+	if err != nil {
+		return counts, erro.Wrap(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var count int
+		err = rows.Scan(&name, &count)
+		if err != nil {
+			return counts, erro.Wrap(err)
+		}
+		counts[name] = count
+	}
+	return counts, erro.Wrap(rows.Err())
+}
+
+// SaveFormVersion replaces the questions of a form, creating a new version of
+// the form if the questions changed. renames (old name => new name) are the
+// question renames made in this version, and userID is the user who made the
+// change (0 if unknown). It returns the version of the form after the change.
+//
+// The version bump and the copy in form_versions are done by the triggers in
+// sql/triggers/form_versions.sql, this only records who made the change and
+// what was renamed.
+func (skylb Skylab) SaveFormVersion(formID int, questions formx.Questions, renames map[string]string, userID int) (version int, err error) {
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return version, erro.Wrap(err)
+	}
+	version, err = saveFormVersion(tx, formID, questions, renames, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return version, erro.Wrap(err)
+	}
+	return version, erro.Wrap(tx.Commit())
+}
+
+// saveFormVersion is SaveFormVersion within tx, so that the new version can be
+// committed together with other changes to the form.
+func saveFormVersion(tx *sql.Tx, formID int, questions formx.Questions, renames map[string]string, userID int) (version int, err error) {
+	if renames == nil {
+		renames = map[string]string{}
+	}
+	renamesJSON, err := json.Marshal(renames)
+	if err != nil {
+		return version, erro.Wrap(err)
+	}
+	var oldVersion int
+	var hadQuestions bool
+	err = tx.QueryRow(
+		`SELECT version, questions IS NOT NULL FROM forms WHERE form_id = $1 FOR UPDATE`,
+		formID,
+	).Scan(&oldVersion, &hadQuestions)
+	if err != nil {
+		return version, erro.Wrap(err)
+	}
+	err = tx.QueryRow(
+		`UPDATE forms SET questions = $1 WHERE form_id = $2 RETURNING version`,
+		questions, formID,
+	).Scan(&version)
+	if err != nil {
+		return version, erro.Wrap(err)
+	}
+	if version != oldVersion || !hadQuestions {
+		_, err = tx.Exec(
+			`UPDATE form_versions SET renames = $1, created_by = $2 WHERE form_id = $3 AND version = $4`,
+			string(renamesJSON), sql.NullInt64{Int64: int64(userID), Valid: userID != 0}, formID, version,
+		)
+		if err != nil {
+			return version, erro.Wrap(err)
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,34 @@
+package skylab
+
+import (
+	"strings"
+
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/mailutil"
+)
+
+// subjectReplacer keeps user supplied values in a subject from adding headers
+// to the email.
+var subjectReplacer = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// SendMail sends an email using the configured SMTP server. If the mailer is
+// not enabled (e.g. in development), the email is logged instead of sent. Line
+// breaks in the subject are replaced with spaces, since it is written as is
+// into the Subject header. Values in the HTML message must be escaped by the
+// caller.
+func (skylb Skylab) SendMail(to []string, subject, message string) error {
+	subject = subjectReplacer.Replace(subject)
+	if !skylb.MailerEnabled {
+		skylb.Log.Printf("mailer disabled, not sending mail\nTo: %s\nSubject: %s\n%s", strings.Join(to, ", "), subject, message)
+		return nil
+	}
+	config := mailutil.Config{
+		SmtpHost:     skylb.SmtpHost,
+		SmtpPort:     skylb.SmtpPort,
+		SmtpUsername: skylb.SmtpUsername,
+		SmtpPassword: skylb.SmtpPassword,
+		From:         skylb.SmtpUsername,
+	}
+	err := mailutil.Send(config, to, subject, message)
+	return erro.Wrap(err)
+}
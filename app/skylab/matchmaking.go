@@ -0,0 +1,126 @@
+package skylab
+
+import (
+	"sort"
+	"strings"
+)
+
+// Statuses of a MatchRequest, see matchmaking_requests_status_enum
+const (
+	MatchRequestStatusPending   = "pending"
+	MatchRequestStatusAccepted  = "accepted"
+	MatchRequestStatusDeclined  = "declined"
+	MatchRequestStatusCancelled = "cancelled"
+)
+
+// MaxMatchSuggestions is the most partners suggested to an applicant at once.
+const MaxMatchSuggestions = 10
+
+// MatchSkills returns the skills an applicant can list on their matchmaking
+// profile.
+func MatchSkills() []string {
+	return []string{
+		"Frontend",
+		"Backend",
+		"Mobile",
+		"UI/UX Design",
+		"Machine Learning",
+		"Game Development",
+		"DevOps",
+	}
+}
+
+// MatchAvailabilities returns the time slots an applicant can say they are
+// available to work on their project on their matchmaking profile.
+func MatchAvailabilities() []string {
+	return []string{
+		"Weekday mornings",
+		"Weekday afternoons",
+		"Weekday evenings",
+		"Weekends",
+	}
+}
+
+// MatchProfile is the profile of an applicant looking for a partner.
+type MatchProfile struct {
+	Valid        bool
+	UserRoleID   int
+	User         User
+	ProjectLevel string
+	Skills       []string
+	Availability []string
+	About        string
+}
+
+// MatchSuggestion is an applicant suggested as a partner, along with why.
+type MatchSuggestion struct {
+	Profile            MatchProfile
+	Score              int
+	SharedAvailability []string // the slots both applicants are available in
+	NewSkills          []string // the partner's skills that the applicant lacks
+	Requested          bool     // whether the applicant already sent them a request
+}
+
+// MatchRequest is a request to partner up sent from one applicant to another.
+type MatchRequest struct {
+	MatchRequestID int
+	Requester      MatchProfile
+	Requestee      MatchProfile
+	Status         string
+}
+
+// SuggestPartners returns the profiles in the pool that are compatible with
+// me, best match first. Partners are compatible if they are aiming for the
+// same project level and share at least one availability slot. Shared slots
+// count for more than skills that complement my own.
+func SuggestPartners(me MatchProfile, pool []MatchProfile) []MatchSuggestion {
+	var suggestions []MatchSuggestion
+	for _, profile := range pool {
+		if profile.UserRoleID == me.UserRoleID || profile.ProjectLevel != me.ProjectLevel {
+			continue
+		}
+		shared := intersect(profile.Availability, me.Availability)
+		if len(shared) == 0 {
+			continue
+		}
+		newSkills := subtract(profile.Skills, me.Skills)
+		suggestions = append(suggestions, MatchSuggestion{
+			Profile:            profile,
+			Score:              2*len(shared) + len(newSkills),
+			SharedAvailability: shared,
+			NewSkills:          newSkills,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return strings.ToLower(suggestions[i].Profile.User.Displayname) < strings.ToLower(suggestions[j].Profile.User.Displayname)
+	})
+	if len(suggestions) > MaxMatchSuggestions {
+		suggestions = suggestions[:MaxMatchSuggestions]
+	}
+	return suggestions
+}
+
+// intersect returns the items of a that are also in b, in the order of a.
+func intersect(a, b []string) []string {
+	var items []string
+	for _, item := range a {
+		if Contains(b, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// subtract returns the items of a that are not in b, in the order of a.
+func subtract(a, b []string) []string {
+	var items []string
+	for _, item := range a {
+		if !Contains(b, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,51 @@
+package skylab
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSuggestPartners(t *testing.T) {
+	is := is.New(t)
+	me := MatchProfile{
+		UserRoleID:   1,
+		ProjectLevel: ProjectLevelGemini,
+		Skills:       []string{"Frontend"},
+		Availability: []string{"Weekday evenings", "Weekends"},
+	}
+	profile := func(userRoleID int, name, projectLevel string, skills, availability []string) MatchProfile {
+		return MatchProfile{
+			UserRoleID:   userRoleID,
+			User:         User{Displayname: name},
+			ProjectLevel: projectLevel,
+			Skills:       skills,
+			Availability: availability,
+		}
+	}
+	pool := []MatchProfile{
+		me,
+		profile(2, "bob", ProjectLevelApollo, []string{"Backend"}, []string{"Weekends"}),
+		profile(3, "carol", ProjectLevelGemini, []string{"Backend"}, []string{"Weekday mornings"}),
+		profile(4, "dave", ProjectLevelGemini, []string{"Frontend"}, []string{"Weekends"}),
+		profile(5, "erin", ProjectLevelGemini, []string{"Backend", "Frontend"}, []string{"Weekends", "Weekday evenings"}),
+		profile(6, "Alice", ProjectLevelGemini, []string{"Backend"}, []string{"Weekends"}),
+	}
+	suggestions := SuggestPartners(me, pool)
+	var names []string
+	for _, suggestion := range suggestions {
+		names = append(names, suggestion.Profile.User.Displayname)
+	}
+	// me, a different project level and no shared availability are left out
+	is.Equal(names, []string{"erin", "Alice", "dave"})
+	is.Equal(suggestions[0].Score, 5)
+	is.Equal(suggestions[0].SharedAvailability, []string{"Weekends", "Weekday evenings"})
+	is.Equal(suggestions[0].NewSkills, []string{"Backend"})
+	is.Equal(suggestions[2].NewSkills, []string(nil))
+
+	var many []MatchProfile
+	for i := 0; i < MaxMatchSuggestions+5; i++ {
+		many = append(many, profile(100+i, "x", ProjectLevelGemini, nil, []string{"Weekends"}))
+	}
+	is.Equal(len(SuggestPartners(me, many)), MaxMatchSuggestions)
+}
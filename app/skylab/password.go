@@ -0,0 +1,336 @@
+package skylab
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/auth"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/ratelimit"
+	"github.com/bokwoon95/nusskylabx/tables"
+	"github.com/lib/pq"
+)
+
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 72 // bcrypt ignores everything after the 72nd byte
+
+	// PasswordTokenPurpose consts correspond to the purposes present inside
+	// the password_token_purpose_enum table in the database
+	PasswordTokenPurposeSet   = "set"   // emailed by an admin to let a user set their first password
+	PasswordTokenPurposeReset = "reset" // emailed to a user who forgot their password
+
+	PasswordSetTokenDuration   = 7 * 24 * time.Hour
+	PasswordResetTokenDuration = time.Hour
+)
+
+// ValidatePassword checks that a password is acceptable before it is hashed
+// and stored.
+func ValidatePassword(password string) error {
+	if len(password) < PasswordMinLength {
+		return erro.Errorf(ErrPasswordTooShort, PasswordMinLength)
+	}
+	if len(password) > PasswordMaxLength {
+		return erro.Errorf(ErrPasswordTooLong, PasswordMaxLength)
+	}
+	return nil
+}
+
+// PasswordLoginRoles returns the roles that are allowed to login with an email
+// and password.
+func (skylb Skylab) PasswordLoginRoles() (roles []string, err error) {
+	plr := tables.PASSWORD_LOGIN_ROLES()
+	var role string
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(plr).
+		Selectx(func(row *sq.Row) {
+			role = row.String(plr.ROLE)
+		}, func() {
+			roles = append(roles, role)
+		}).
+		Fetch(skylb.DB)
+	return roles, erro.Wrap(err)
+}
+
+// SetPasswordLoginRoles replaces the roles that are allowed to login with an
+// email and password.
+func (skylb Skylab) SetPasswordLoginRoles(roles []string) error {
+	for _, role := range roles {
+		if !Contains(Roles(), role) {
+			return erro.Wrap(erro.Errorf(ErrRoleInvalid, role))
+		}
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	_, err = tx.Exec(`DELETE FROM password_login_roles WHERE role <> ALL($1)`, pq.Array(roles))
+	if err != nil {
+		_ = tx.Rollback()
+		return erro.Wrap(err)
+	}
+	_, err = tx.Exec(
+		`INSERT INTO password_login_roles (role) SELECT unnest($1::TEXT[]) ON CONFLICT DO NOTHING`,
+		pq.Array(roles),
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return erro.Wrap(err)
+	}
+	return erro.Wrap(tx.Commit())
+}
+
+// PasswordLoginAllowed reports whether the user has any role that is allowed
+// to login with an email and password.
+func (skylb Skylab) PasswordLoginAllowed(userID int) (allowed bool, err error) {
+	ur, plr := tables.USER_ROLES(), tables.PASSWORD_LOGIN_ROLES()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		From(ur).
+		Join(plr, plr.ROLE.Eq(ur.ROLE)).
+		Where(ur.USER_ID.EqInt(userID)).
+		SelectOne().
+		Exec(skylb.DB, sq.ErowsAffected)
+	return rowsAffected != 0, erro.Wrap(err)
+}
+
+// SetPassword hashes and stores the user's new password.
+func (skylb Skylab) SetPassword(userID int, password string) error {
+	err := ValidatePassword(password)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	u := tables.USERS()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		Update(u).
+		Set(u.PASSWORD.SetString(passwordHash)).
+		Where(u.USER_ID.EqInt(userID)).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// CreatePasswordToken creates a single-use token that lets the user set their
+// password, and returns the plaintext token. Any unused tokens the user
+// previously had are invalidated.
+func (skylb Skylab) CreatePasswordToken(userID int, purpose string) (token string, err error) {
+	var duration time.Duration
+	switch purpose {
+	case PasswordTokenPurposeSet:
+		duration = PasswordSetTokenDuration
+	case PasswordTokenPurposeReset:
+		duration = PasswordResetTokenDuration
+	default:
+		return "", erro.Wrap(fmt.Errorf("invalid password token purpose '%s'", purpose))
+	}
+	token, err = auth.GenerateRandomString()
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	pt := tables.PASSWORD_TOKENS()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(pt).
+		Where(pt.USER_ID.EqInt(userID), pt.USED_AT.IsNull()).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		InsertInto(pt).
+		Columns(pt.USER_ID, pt.PURPOSE, pt.HASH, pt.EXPIRES_AT).
+		Values(userID, purpose, skylb.Hash([]byte(token)), time.Now().Add(duration)).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	return token, nil
+}
+
+// GetUserFromPasswordToken gets the user that an unused, unexpired password
+// token belongs to. If the token is invalid, the returned user will not be
+// valid (but err will still be nil).
+func (skylb Skylab) GetUserFromPasswordToken(token string) (user User, err error) {
+	u, pt := tables.USERS(), tables.PASSWORD_TOKENS()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(pt).
+		Join(u, u.USER_ID.Eq(pt.USER_ID)).
+		Where(
+			pt.HASH.EqString(skylb.Hash([]byte(token))),
+			pt.USED_AT.IsNull(),
+			pt.EXPIRES_AT.GtTime(time.Now()),
+		).
+		SelectRowx(func(row *sq.Row) {
+			user.Valid = row.IntValid(u.USER_ID)
+			user.UserID = row.Int(u.USER_ID)
+			user.Displayname = row.String(u.DISPLAYNAME)
+			user.Email = row.String(u.EMAIL)
+		}).
+		Fetch(skylb.DB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, nil
+	}
+	return user, erro.Wrap(err)
+}
+
+// UsePasswordToken sets the password of the user that the password token
+// belongs to, after which the token can no longer be used. The token is
+// claimed in the same transaction that sets the password, so that it cannot be
+// used twice by concurrent requests. Every session of the user is revoked in
+// that transaction as well.
+func (skylb Skylab) UsePasswordToken(token, password string) (user User, err error) {
+	err = ValidatePassword(password)
+	if err != nil {
+		return user, erro.Wrap(err)
+	}
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return user, erro.Wrap(err)
+	}
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return user, erro.Wrap(err)
+	}
+	err = tx.QueryRow(
+		`UPDATE password_tokens SET used_at = NOW() WHERE hash = $1 AND used_at IS NULL AND expires_at > NOW() RETURNING user_id`,
+		skylb.Hash([]byte(token)),
+	).Scan(&user.UserID)
+	if err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, erro.Wrap(erro.Errorf(ErrPasswordTokenInvalid))
+		}
+		return user, erro.Wrap(err)
+	}
+	err = tx.QueryRow(
+		`UPDATE users SET password = $1 WHERE user_id = $2 RETURNING displayname, email`,
+		passwordHash, user.UserID,
+	).Scan(&user.Displayname, &user.Email)
+	if err != nil {
+		_ = tx.Rollback()
+		return User{}, erro.Wrap(err)
+	}
+	// Whoever knew the old password may still be logged in
+	_, err = revokeAllSessions(tx, user.UserID)
+	if err != nil {
+		_ = tx.Rollback()
+		return User{}, erro.Wrap(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return User{}, erro.Wrap(err)
+	}
+	user.Valid = true
+	return user, nil
+}
+
+// SendPasswordEmail emails the user a link to set (or reset) their password.
+func (skylb Skylab) SendPasswordEmail(user User, purpose string) error {
+	token, err := skylb.CreatePasswordToken(user.UserID, purpose)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	link := skylb.BaseURLWithProtocol() + "/password/set?token=" + url.QueryEscape(token)
+	name, email := html.EscapeString(user.Displayname), html.EscapeString(user.Email)
+	var subject, message string
+	switch purpose {
+	case PasswordTokenPurposeSet:
+		subject = "Set your Skylab password"
+		message = fmt.Sprintf(`<p>Hi %s,</p>
+<p>An account has been created for you on Skylab. Please <a href="%s">set your password</a> to login with your email %s.</p>
+<p>This link expires in %d days.</p>`, name, link, email, int(PasswordSetTokenDuration.Hours()/24))
+	default:
+		subject = "Reset your Skylab password"
+		message = fmt.Sprintf(`<p>Hi %s,</p>
+<p>Someone (hopefully you) asked to reset your Skylab password. <a href="%s">Reset your password</a>.</p>
+<p>This link expires in %d minutes. If you did not ask to reset your password, you can ignore this email.</p>`, name, link, int(PasswordResetTokenDuration.Minutes()))
+	}
+	return erro.Wrap(skylb.SendMail([]string{user.Email}, subject, message))
+}
+
+// dummyPasswordHash is a bcrypt hash (of a password nobody uses) with the same
+// cost as auth.HashPassword.
+const dummyPasswordHash = "$2a$10$eQFv3vWBNz4uOVz.0Jy9XudbHwP1EHDohaIrXPTxAwejW/fSM/8fK"
+
+// PasswordLogin checks the email and password from the login form. If they
+// are correct and the user is allowed to use password login, it injects the
+// user's email and displayname into the context the same way
+// auth.Authenticate does, so that it can be followed by SetSession.
+// Otherwise the user is redirected back to the login page with an error.
+func (skylb Skylab) PasswordLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skylb.Log.TraceRequest(r)
+		fail := func(msg string) {
+			r, _ = skylb.SetFlashMsgs(w, r, map[string][]string{flash.Error: {msg}})
+			http.Redirect(w, r, "/login-page", http.StatusMovedPermanently)
+		}
+		email := strings.TrimSpace(r.FormValue("email"))
+		password := r.FormValue("password")
+		// The login routes are already rate limited per IP address, this
+		// stops one account from being guessed at from many addresses
+		key := "password-login:email:" + strings.ToLower(email)
+		res, err := ratelimit.Check(r.Context(), skylb.RateLimitStore, key, RateLimitPasswordLogin)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !res.Allowed {
+			fail("Too many login attempts for this email, please try again later")
+			return
+		}
+		var userID int
+		var displayname string
+		var passwordHash sql.NullString
+		u := tables.USERS()
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(u).
+			Where(u.EMAIL.EqString(email)).
+			SelectRowx(func(row *sq.Row) {
+				userID = row.Int(u.USER_ID)
+				displayname = row.String(u.DISPLAYNAME)
+				row.ScanInto(&passwordHash, u.PASSWORD)
+			}).
+			Fetch(skylb.DB)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		// Compare against dummyPasswordHash if there is no password, so that
+		// the response takes as long whether the email exists or not
+		if !passwordHash.Valid {
+			_ = auth.CompareHashAndPassword(dummyPasswordHash, password)
+			fail("Incorrect email or password")
+			return
+		}
+		if auth.CompareHashAndPassword(passwordHash.String, password) != nil {
+			fail("Incorrect email or password")
+			return
+		}
+		allowed, err := skylb.PasswordLoginAllowed(userID)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !allowed {
+			fail("Password login is not enabled for your account, please login with NUS or Google instead")
+			return
+		}
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, "email", email)
+		ctx = context.WithValue(ctx, "displayname", displayname)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,17 @@
+package skylab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/matryer/is"
+)
+
+func TestValidatePassword(t *testing.T) {
+	is := is.New(t)
+	is.True(erro.Is(ValidatePassword("short"), ErrPasswordTooShort))
+	is.True(erro.Is(ValidatePassword(strings.Repeat("a", PasswordMaxLength+1)), ErrPasswordTooLong))
+	is.NoErr(ValidatePassword("correct horse battery staple"))
+	is.NoErr(ValidatePassword(strings.Repeat("a", PasswordMinLength)))
+}
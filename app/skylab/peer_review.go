@@ -0,0 +1,147 @@
+package skylab
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// PeerReviewSettings controls what the evaluatee team sees of the evaluations
+// done in an evaluation period, see the peer_review_settings table. The zero
+// value withholds and hides nothing.
+type PeerReviewSettings struct {
+	Valid                 bool
+	PeriodID              int
+	Anonymous             bool // the evaluator is hidden from the evaluatee
+	RevealAfterSubmission bool // the evaluatee must submit its own evaluations first
+	ReleaseAt             sql.NullTime
+}
+
+// PeerReviewSettings returns the peer review settings of the evaluation period
+// of the cohort's milestone. If there is no such period, or the period has no
+// settings, the zero PeerReviewSettings (with the PeriodID of the period, if
+// any) is returned.
+func (skylb Skylab) PeerReviewSettings(cohort, milestone string) (PeerReviewSettings, error) {
+	var periodID int
+	p := tables.PERIODS()
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(p).
+		Where(
+			p.COHORT.EqString(cohort),
+			p.STAGE.EqString(StageEvaluation),
+			p.MILESTONE.EqString(milestone),
+		).
+		SelectRowx(func(row *sq.Row) { periodID = row.Int(p.PERIOD_ID) }).
+		Fetch(skylb.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PeerReviewSettings{}, nil
+		}
+		return PeerReviewSettings{}, erro.Wrap(err)
+	}
+	return skylb.PeerReviewSettingsByPeriod(periodID)
+}
+
+// PeerReviewSettingsByPeriod returns the peer review settings of the period,
+// or the zero PeerReviewSettings with the PeriodID if it has none.
+func (skylb Skylab) PeerReviewSettingsByPeriod(periodID int) (PeerReviewSettings, error) {
+	settings := PeerReviewSettings{PeriodID: periodID}
+	prs := tables.PEER_REVIEW_SETTINGS()
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(prs).
+		Where(prs.PERIOD_ID.EqInt(periodID)).
+		SelectRowx(func(row *sq.Row) {
+			settings = PeerReviewSettings{
+				Valid:                 row.IntValid(prs.PERIOD_ID),
+				PeriodID:              row.Int(prs.PERIOD_ID),
+				Anonymous:             row.Bool(prs.ANONYMOUS),
+				RevealAfterSubmission: row.Bool(prs.REVEAL_AFTER_SUBMISSION),
+				ReleaseAt:             row.NullTime(prs.RELEASE_AT),
+			}
+		}).
+		Fetch(skylb.DB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	return settings, erro.Wrap(err)
+}
+
+// SavePeerReviewSettings creates or replaces the peer review settings of
+// settings.PeriodID.
+func (skylb Skylab) SavePeerReviewSettings(settings PeerReviewSettings) error {
+	prs := tables.PEER_REVIEW_SETTINGS()
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		InsertInto(prs).
+		Columns(prs.PERIOD_ID, prs.ANONYMOUS, prs.REVEAL_AFTER_SUBMISSION, prs.RELEASE_AT).
+		Values(settings.PeriodID, settings.Anonymous, settings.RevealAfterSubmission, settings.ReleaseAt).
+		OnConflict(prs.PERIOD_ID).
+		DoUpdateSet(
+			prs.ANONYMOUS.Set(sq.Excluded(prs.ANONYMOUS)),
+			prs.REVEAL_AFTER_SUBMISSION.Set(sq.Excluded(prs.REVEAL_AFTER_SUBMISSION)),
+			prs.RELEASE_AT.Set(sq.Excluded(prs.RELEASE_AT)),
+		).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// WithheldReason returns why the evaluations are withheld from the evaluatee
+// at time now, or an empty string if they are not. submittedOwnEvaluations is
+// whether the evaluatee team has submitted all of its own evaluations for the
+// period.
+func (settings PeerReviewSettings) WithheldReason(now time.Time, submittedOwnEvaluations bool) string {
+	if settings.ReleaseAt.Valid && now.Before(settings.ReleaseAt.Time) {
+		return fmt.Sprintf("Evaluations of your submission will be released on %s", SGTime(settings.ReleaseAt))
+	}
+	if settings.RevealAfterSubmission && !submittedOwnEvaluations {
+		return "Evaluations of your submission will be shown once your team has submitted all of its own evaluations"
+	}
+	return ""
+}
+
+// EvaluationsWithheld returns the peer review settings of the cohort's
+// milestone together with why the evaluations of the team's submission are
+// withheld from the team, or an empty reason if the team may see them.
+func (skylb Skylab) EvaluationsWithheld(teamID int, cohort, milestone string) (settings PeerReviewSettings, reason string, err error) {
+	settings, err = skylb.PeerReviewSettings(cohort, milestone)
+	if err != nil {
+		return settings, "", erro.Wrap(err)
+	}
+	submittedOwnEvaluations := true
+	if settings.RevealAfterSubmission {
+		// Count the submitted submissions the team is paired to evaluate but
+		// has not submitted an evaluation for
+		te := tables.V_TEAM_EVALUATIONS()
+		pending, err := sq.WithDefaultLog(sq.Lstats).
+			SelectOne().
+			From(te).
+			Where(
+				te.EVALUATOR_TEAM_ID.EqInt(teamID),
+				te.COHORT.EqString(cohort),
+				te.STAGE.EqString(StageEvaluation),
+				te.MILESTONE.EqString(milestone),
+				te.SUBMISSION_SUBMITTED,
+				sq.Predicatef("? IS NOT TRUE", te.EVALUATION_SUBMITTED),
+			).
+			Exec(skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			return settings, "", erro.Wrap(err)
+		}
+		submittedOwnEvaluations = pending == 0
+	}
+	return settings, settings.WithheldReason(time.Now(), submittedOwnEvaluations), nil
+}
+
+// Anonymize hides the evaluator team of the evaluation.
+func (e *TeamEvaluation) Anonymize() {
+	e.Evaluator = Team{Valid: e.Evaluator.Valid}
+}
+
+// Anonymize hides the evaluator of the evaluation, leaving only their role.
+func (e *UserEvaluation) Anonymize() {
+	e.Evaluator = User{Valid: e.Evaluator.Valid}
+}
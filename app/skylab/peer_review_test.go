@@ -0,0 +1,28 @@
+package skylab
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestPeerReviewSettingsWithheldReason(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// The zero settings withhold nothing
+	is.Equal(PeerReviewSettings{}.WithheldReason(now, false), "")
+
+	// Evaluations are withheld until the release date
+	settings := PeerReviewSettings{ReleaseAt: sql.NullTime{Valid: true, Time: now.Add(time.Hour)}}
+	is.Equal(settings.WithheldReason(now, true), "Evaluations of your submission will be released on "+SGTime(settings.ReleaseAt))
+	settings.ReleaseAt.Time = now.Add(-time.Hour)
+	is.Equal(settings.WithheldReason(now, true), "")
+
+	// Evaluations are withheld until the team has done its own evaluations
+	settings.RevealAfterSubmission = true
+	is.Equal(settings.WithheldReason(now, false), "Evaluations of your submission will be shown once your team has submitted all of its own evaluations")
+	is.Equal(settings.WithheldReason(now, true), "")
+}
@@ -0,0 +1,114 @@
+package skylab
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/ratelimit"
+	"github.com/bokwoon95/nusskylabx/tables"
+	"github.com/jmoiron/sqlx"
+)
+
+// Rate limits applied to the public facing endpoints that are most likely to
+// be abused. Each limit is counted separately per IP address and per user.
+var (
+	RateLimitLogin          = ratelimit.Limit{Requests: 10, Window: time.Minute}
+	RateLimitForgotPassword = ratelimit.Limit{Requests: 5, Window: time.Hour}
+	RateLimitJoin           = ratelimit.Limit{Requests: 20, Window: time.Hour}
+	RateLimitSubmit         = ratelimit.Limit{Requests: 30, Window: time.Minute}
+)
+
+// RateLimitTOTP limits the two-factor authentication codes that can be entered
+// for a user, whichever IP address or session they come from. Once it is
+// exceeded the pending session is revoked and the user has to log in again.
+var RateLimitTOTP = ratelimit.Limit{Requests: 5, Window: 15 * time.Minute}
+
+// RateLimitPasswordLogin limits the password logins that can be attempted for
+// an email, whichever IP address they come from.
+var RateLimitPasswordLogin = ratelimit.Limit{Requests: 10, Window: 15 * time.Minute}
+
+// PostgresRateLimitStore is a ratelimit.Store that keeps its counts in the
+// rate_limits table, so that the limits are shared between every instance of
+// skylab connected to the same database.
+type PostgresRateLimitStore struct {
+	DB *sqlx.DB
+}
+
+// Hit implements ratelimit.Store. The count is reset in the same statement
+// that increments it if the key's stored window has ended.
+func (store PostgresRateLimitStore) Hit(ctx context.Context, key string, window time.Duration) (count int, reset time.Time, err error) {
+	windowStart := ratelimit.WindowStart(time.Now(), window)
+	reset = windowStart.Add(window)
+	err = store.DB.QueryRowContext(ctx, `
+INSERT INTO rate_limits AS rl (key, window_start, window_end, count)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (key) DO UPDATE SET
+    count = CASE WHEN rl.window_start = EXCLUDED.window_start THEN rl.count + 1 ELSE 1 END
+    ,window_start = EXCLUDED.window_start
+    ,window_end = EXCLUDED.window_end
+RETURNING count`, key, windowStart, reset).Scan(&count)
+	return count, reset, erro.Wrap(err)
+}
+
+// CleanupRateLimits deletes the rate limit counts whose windows have ended.
+func (skylb Skylab) CleanupRateLimits() (rowsAffected int64, err error) {
+	rl := tables.RATE_LIMITS()
+	rowsAffected, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(rl).
+		Where(rl.WINDOW_END.LeTime(time.Now())).
+		Exec(skylb.DB, sq.ErowsAffected)
+	return rowsAffected, erro.Wrap(err)
+}
+
+// RunRateLimitCleanup calls CleanupRateLimits every interval until ctx is
+// cancelled. It is meant to be run in its own goroutine.
+func (skylb Skylab) RunRateLimitCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := skylb.CleanupRateLimits()
+			if err != nil {
+				skylb.Log.Printf("cleaning up rate limits: %s", err)
+			}
+		}
+	}
+}
+
+// RateLimit returns a middleware that rejects requests with a 429 Too Many
+// Requests page once the client has exceeded limit. Requests are counted per
+// IP address and, if the request has a logged in user (i.e. RateLimit comes
+// after GetSession), per user as well. name identifies the group of endpoints
+// sharing the limit, so that e.g. every login route counts towards the same
+// limit.
+func (skylb Skylab) RateLimit(name string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys := []string{name + ":ip:" + clientIP(r, skylb.TrustedProxies)}
+			if user, _ := r.Context().Value(ContextUser).(User); user.Valid {
+				keys = append(keys, name+":user:"+strconv.Itoa(user.UserID))
+			}
+			for _, key := range keys {
+				res, err := ratelimit.Check(r.Context(), skylb.RateLimitStore, key, limit)
+				if err != nil {
+					// Fail open: a broken rate limiter should not lock everyone out
+					skylb.Log.Printf("rate limit %s: %s", key, err)
+					continue
+				}
+				ratelimit.SetHeaders(w, res)
+				if !res.Allowed {
+					skylb.TooManyRequests(w, r, res.RetryAfter(time.Now()))
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -40,25 +40,39 @@ const (
 	AdviserM2ViewEvaluation    = "/adviser/milestone2/evaluations"
 	AdviserM3MakeEvaluation    = "/adviser/milestone3/evaluation"
 	AdviserM3ViewEvaluation    = "/adviser/milestone3/evaluations"
-
-	MentorDashboard = "/mentor/dashboard"
-
-	AdminDashboard         = "/admin/dashboard"
-	AdminCreateUser        = "/admin/create-user"
-	AdminCreateUserConfirm = "/admin/create-user/confirm"
-	AdminListCohorts       = "/admin/cohorts"
-	AdminListUsers         = "/admin/users"
-	AdminUser              = "/admin/user"
-	AdminListPeriods       = "/admin/periods"
-	AdminListForms         = "/admin/forms"
-	AdminForm              = "/admin/form"
-	AdminListTeams         = "/admin/teams"
-	AdminTeam              = "/admin/team"
-	AdminListApplications  = "/admin/applications"
-	AdminApplication       = "/admin/application"
-	AdminListFeedbacks     = "/admin/feedbacks"
-	AdminDumpJson          = "/dump-json"
-	AdminTestmail          = "/testmail" // experimental
+	AdviserApplicationReviews  = "/adviser/application-reviews"
+	AdviserApplicationReview   = "/adviser/application-review"
+
+	MentorDashboard      = "/mentor/dashboard"
+	MentorTeams          = "/mentor/teams"
+	MentorSubmission     = "/mentor/submission"
+	MentorUserEvaluation = "/mentor/user-evaluation"
+	MentorTeamEvaluation = "/mentor/team-evaluation"
+	MentorM1Feedback     = "/mentor/milestone1/feedback"
+	MentorM2Feedback     = "/mentor/milestone2/feedback"
+	MentorM3Feedback     = "/mentor/milestone3/feedback"
+
+	AdminDashboard          = "/admin/dashboard"
+	AdminCreateUser         = "/admin/create-user"
+	AdminCreateUserConfirm  = "/admin/create-user/confirm"
+	AdminListCohorts        = "/admin/cohorts"
+	AdminListUsers          = "/admin/users"
+	AdminUser               = "/admin/user"
+	AdminListPeriods        = "/admin/periods"
+	AdminListForms          = "/admin/forms"
+	AdminForm               = "/admin/form"
+	AdminListTeams          = "/admin/teams"
+	AdminTeam               = "/admin/team"
+	AdminListApplications   = "/admin/applications"
+	AdminApplication        = "/admin/application"
+	AdminApplicationReviews = "/admin/application-reviews"
+	AdminApplicationReview  = "/admin/application-review"
+	AdminListFeedbacks      = "/admin/feedbacks"
+	AdminPasswordLogin      = "/admin/password-login"
+	AdminListWebhooks       = "/admin/webhooks"
+	AdminWebhook            = "/admin/webhook"
+	AdminDumpJson           = "/dump-json"
+	AdminTestmail           = "/testmail" // experimental
 )
 
 var sectionSymbols = map[string]string{
@@ -90,25 +104,39 @@ var sectionSymbols = map[string]string{
 	AdviserM2ViewEvaluation:    "AdviserM2ViewEvaluation",
 	AdviserM3MakeEvaluation:    "AdviserM3MakeEvaluation",
 	AdviserM3ViewEvaluation:    "AdviserM3ViewEvaluation",
-
-	MentorDashboard: "MentorDashboard",
-
-	AdminDashboard:         "AdminDashboard",
-	AdminCreateUser:        "AdminCreateUser",
-	AdminCreateUserConfirm: "AdminCreateUserConfirm",
-	AdminListCohorts:       "AdminListCohorts",
-	AdminListUsers:         "AdminListUsers",
-	AdminUser:              "AdminUser",
-	AdminListPeriods:       "AdminListPeriods",
-	AdminListForms:         "AdminListForms",
-	AdminForm:              "AdminForm",
-	AdminListTeams:         "AdminListTeams",
-	AdminTeam:              "AdminTeam",
-	AdminListApplications:  "AdminListApplications",
-	AdminApplication:       "AdminApplication",
-	AdminListFeedbacks:     "AdminListFeedbacks",
-	AdminDumpJson:          "AdminDumpJson",
-	AdminTestmail:          "AdminTestmail", // experimental
+	AdviserApplicationReviews:  "AdviserApplicationReviews",
+	AdviserApplicationReview:   "AdviserApplicationReview",
+
+	MentorDashboard:      "MentorDashboard",
+	MentorTeams:          "MentorTeams",
+	MentorSubmission:     "MentorSubmission",
+	MentorUserEvaluation: "MentorUserEvaluation",
+	MentorTeamEvaluation: "MentorTeamEvaluation",
+	MentorM1Feedback:     "MentorM1Feedback",
+	MentorM2Feedback:     "MentorM2Feedback",
+	MentorM3Feedback:     "MentorM3Feedback",
+
+	AdminDashboard:          "AdminDashboard",
+	AdminCreateUser:         "AdminCreateUser",
+	AdminCreateUserConfirm:  "AdminCreateUserConfirm",
+	AdminListCohorts:        "AdminListCohorts",
+	AdminListUsers:          "AdminListUsers",
+	AdminUser:               "AdminUser",
+	AdminListPeriods:        "AdminListPeriods",
+	AdminListForms:          "AdminListForms",
+	AdminForm:               "AdminForm",
+	AdminListTeams:          "AdminListTeams",
+	AdminTeam:               "AdminTeam",
+	AdminListApplications:   "AdminListApplications",
+	AdminApplication:        "AdminApplication",
+	AdminApplicationReviews: "AdminApplicationReviews",
+	AdminApplicationReview:  "AdminApplicationReview",
+	AdminListFeedbacks:      "AdminListFeedbacks",
+	AdminPasswordLogin:      "AdminPasswordLogin",
+	AdminListWebhooks:       "AdminListWebhooks",
+	AdminWebhook:            "AdminWebhook",
+	AdminDumpJson:           "AdminDumpJson",
+	AdminTestmail:           "AdminTestmail", // experimental
 }
 
 func AddSections(funcs template.FuncMap) template.FuncMap {
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/helpers/auth"
@@ -18,6 +21,14 @@ import (
 const (
 	SessionCookieName      = "_skylab_session"       // The name of the cookie that stores the user's session
 	AdminSessionCookieName = "_skylab_session_admin" // The name of the cookie that stores the admin's session
+
+	SessionMaxAge      = 30 * 24 * time.Hour // Sessions expire this long after login, no matter how active they are
+	SessionIdleTimeout = 7 * 24 * time.Hour  // Sessions expire if they have not been seen for this long
+
+	// sessionLastSeenInterval is how stale a session's last_seen_at must be
+	// before it is updated. It means that at most one write is made per
+	// session every interval, instead of one write per request.
+	sessionLastSeenInterval = 5 * time.Minute
 )
 
 // EnsureIsUser ensures that the email from auth.Authenticate is a valid user
@@ -76,7 +87,23 @@ func (skylb Skylab) SetSession(next http.Handler) http.Handler {
 			skylb.InternalServerError(w, r, err)
 			return
 		}
-		cookies.SetCookie(w, SessionCookieName, sessionID)
+		err = skylb.setSessionClient(sessionHash, r)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		cookies.SetCookie(w, SessionCookieName, sessionID, cookies.Duration(SessionMaxAge))
+		// If the user has two-factor authentication enabled, the session
+		// cannot be used until a code has been entered at /login/totp
+		pendingUser, err := skylb.GetPendingTOTPUser(sessionID)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		if pendingUser.Valid {
+			http.Redirect(w, r, "/login/totp", http.StatusMovedPermanently)
+			return
+		}
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, ContextUser, user)
 		isAdmin, err := skylb.SessionIdIsValidRole(sessionID, RoleAdmin)
@@ -85,7 +112,7 @@ func (skylb Skylab) SetSession(next http.Handler) http.Handler {
 			return
 		}
 		if isAdmin {
-			cookies.SetCookie(w, AdminSessionCookieName, sessionID)
+			cookies.SetCookie(w, AdminSessionCookieName, sessionID, cookies.Duration(SessionMaxAge))
 			ctx = context.WithValue(ctx, ContextAdmin, user)
 		}
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -246,17 +273,25 @@ func (skylb Skylab) RedirectAfterLogout(w http.ResponseWriter, r *http.Request)
 }
 
 // SessionIdIsValidRole checks if the given sessionID (hashed into a
-// sessionHash) exists in the database together with the given role
+// sessionHash) exists in the database together with the given role. Admin
+// sessions are only valid once they have passed two-factor authentication.
 func (skylb Skylab) SessionIdIsValidRole(sessionID string, role string) (valid bool, err error) {
 	sessionHash := skylb.Hash([]byte(sessionID))
 	ss, ur := tables.SESSIONS(), tables.USER_ROLES()
+	predicates := []sq.Predicate{
+		ss.HASH.EqString(sessionHash),
+		ss.EXPIRES_AT.GtTime(time.Now()),
+		ss.LAST_SEEN_AT.GtTime(time.Now().Add(-SessionIdleTimeout)),
+		sessionNotPendingTOTP(ss),
+		ur.ROLE.EqString(role),
+	}
+	if role == RoleAdmin {
+		predicates = append(predicates, ss.TOTP_VERIFIED_AT.IsNotNull())
+	}
 	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
 		From(ss).
 		Join(ur, ur.USER_ID.Eq(ss.USER_ID)).
-		Where(
-			ss.HASH.EqString(sessionHash),
-			ur.ROLE.EqString(role),
-		).
+		Where(predicates...).
 		SelectOne().
 		Exec(skylb.DB, sq.ErowsAffected)
 	valid = rowsAffected != 0
@@ -268,14 +303,18 @@ func (skylb Skylab) SessionIdIsValidRole(sessionID string, role string) (valid b
 // SetSessionForUserID if possible, as userID and sessionHash are considered
 // more low level implementation details that are only needed in specific
 // situations
+//
+// Sessions created this way are already marked as having passed two-factor
+// authentication, since only admins (who have passed it themselves) create
+// them when previewing as another user.
 func (skylb Skylab) SetSessionForUserID(userID int) (sessionID string, sessionHash string, err error) {
 	sessionID, err = auth.GenerateRandomString()
 	if err != nil {
 		return sessionID, sessionHash, erro.Wrap(err)
 	}
 	sessionHash = skylb.Hash([]byte(sessionID))
-	query := "INSERT INTO sessions (hash, user_id) VALUES ($1, $2)"
-	_, err = skylb.DB.Exec(query, sessionHash, userID)
+	query := "INSERT INTO sessions (hash, user_id, expires_at, totp_verified_at) VALUES ($1, $2, $3, NOW())"
+	_, err = skylb.DB.Exec(query, sessionHash, userID, time.Now().Add(SessionMaxAge))
 	var e *pq.Error
 	if errors.As(err, &e) {
 		switch e.Code {
@@ -302,15 +341,22 @@ func (skylb Skylab) GetUserFromSessionID(sessionID string) (user User, err error
 	sessionHash := skylb.Hash([]byte(sessionID))
 	// Get the user
 	u, ss := tables.USERS(), tables.SESSIONS()
+	var lastSeenAt time.Time
 	err = sq.
 		From(ss).
 		Join(u, u.USER_ID.Eq(ss.USER_ID)).
-		Where(ss.HASH.EqString(sessionHash)).
+		Where(
+			ss.HASH.EqString(sessionHash),
+			ss.EXPIRES_AT.GtTime(time.Now()),
+			ss.LAST_SEEN_AT.GtTime(time.Now().Add(-SessionIdleTimeout)),
+			sessionNotPendingTOTP(ss),
+		).
 		SelectRowx(func(row *sq.Row) {
 			user.Valid = row.IntValid(u.USER_ID)
 			user.UserID = row.Int(u.USER_ID)
 			user.Displayname = row.String(u.DISPLAYNAME)
 			user.Email = row.String(u.EMAIL)
+			lastSeenAt = row.Time(ss.LAST_SEEN_AT)
 		}).
 		Fetch(skylb.DB)
 	if err != nil {
@@ -319,6 +365,17 @@ func (skylb Skylab) GetUserFromSessionID(sessionID string) (user User, err error
 		}
 		return user, erro.Wrap(err)
 	}
+	// Update last seen
+	if time.Since(lastSeenAt) > sessionLastSeenInterval {
+		_, err = sq.
+			Update(ss).
+			Set(ss.LAST_SEEN_AT.SetTime(time.Now())).
+			Where(ss.HASH.EqString(sessionHash)).
+			Exec(skylb.DB, 0)
+		if err != nil {
+			return user, erro.Wrap(err)
+		}
+	}
 	// Get the user roles
 	user.Roles = make(map[string]int)
 	ur := tables.USER_ROLES()
@@ -335,3 +392,208 @@ func (skylb Skylab) GetUserFromSessionID(sessionID string) (user User, err error
 	}
 	return user, nil
 }
+
+// setSessionClient records the expiry as well as the user agent and IP address
+// that a newly created session was created from, so that users can tell their
+// sessions apart.
+func (skylb Skylab) setSessionClient(sessionHash string, r *http.Request) error {
+	ss := tables.SESSIONS()
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		Update(ss).
+		Set(
+			ss.EXPIRES_AT.SetTime(time.Now().Add(SessionMaxAge)),
+			ss.USER_AGENT.SetString(r.UserAgent()),
+			ss.IP_ADDRESS.SetString(clientIP(r, skylb.TrustedProxies)),
+		).
+		Where(ss.HASH.EqString(sessionHash)).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// clientIP returns the IP address of the client. If the request comes from
+// one of the trustedProxies, the client IP is taken from the X-Forwarded-For
+// header instead: it is the right-most address in the header that is not a
+// trusted proxy, since every address to the left of it could have been made
+// up by the client. The X-Forwarded-For header of a request that does not come
+// from a trusted proxy is ignored.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !ipInNets(ip, trustedProxies) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if net.ParseIP(hop) == nil {
+			break // a malformed header, don't trust anything to its left
+		}
+		ip = hop
+		if !ipInNets(hop, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+// ipInNets reports whether ip is contained in any of nets.
+func ipInNets(ip string, nets []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses a comma separated list of IP addresses and CIDR
+// ranges of the reverse proxies in front of skylab, e.g.
+// "127.0.0.1,10.0.0.0/8".
+func ParseTrustedProxies(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q: %w", value, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Session is a logged in session of a user.
+type Session struct {
+	Valid      bool
+	SessionID  int
+	UserID     int
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	Current    bool // whether this is the session of the current request
+}
+
+// ListSessions lists the unexpired sessions of a user, most recently seen
+// first. The session whose ID is currentSessionID is marked as Current.
+func (skylb Skylab) ListSessions(userID int, currentSessionID string) (sessions []Session, err error) {
+	currentHash := skylb.Hash([]byte(currentSessionID))
+	ss := tables.SESSIONS()
+	var session Session
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ss).
+		Where(
+			ss.USER_ID.EqInt(userID),
+			ss.EXPIRES_AT.GtTime(time.Now()),
+			ss.LAST_SEEN_AT.GtTime(time.Now().Add(-SessionIdleTimeout)),
+		).
+		OrderBy(ss.LAST_SEEN_AT.Desc()).
+		Selectx(func(row *sq.Row) {
+			session = Session{
+				Valid:      row.IntValid(ss.SESSION_ID),
+				SessionID:  row.Int(ss.SESSION_ID),
+				UserID:     row.Int(ss.USER_ID),
+				UserAgent:  row.String(ss.USER_AGENT),
+				IPAddress:  row.String(ss.IP_ADDRESS),
+				CreatedAt:  row.Time(ss.CREATED_AT),
+				LastSeenAt: row.Time(ss.LAST_SEEN_AT),
+				ExpiresAt:  row.Time(ss.EXPIRES_AT),
+				Current:    row.String(ss.HASH) == currentHash,
+			}
+		}, func() {
+			sessions = append(sessions, session)
+		}).
+		Fetch(skylb.DB)
+	return sessions, erro.Wrap(err)
+}
+
+// RevokeSessionByID revokes one of the user's sessions. The userID is
+// required so that users can only revoke their own sessions.
+func (skylb Skylab) RevokeSessionByID(userID, sessionID int) error {
+	ss := tables.SESSIONS()
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(ss).
+		Where(
+			ss.SESSION_ID.EqInt(sessionID),
+			ss.USER_ID.EqInt(userID),
+		).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// RevokeAllSessions revokes every session of the user, logging them out
+// everywhere.
+func (skylb Skylab) RevokeAllSessions(userID int) (revoked int64, err error) {
+	return revokeAllSessions(skylb.DB, userID)
+}
+
+// revokeAllSessions is RevokeAllSessions run on db, which may be a
+// transaction.
+func revokeAllSessions(db sq.DB, userID int) (revoked int64, err error) {
+	ss := tables.SESSIONS()
+	revoked, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(ss).
+		Where(ss.USER_ID.EqInt(userID)).
+		Exec(db, sq.ErowsAffected)
+	return revoked, erro.Wrap(err)
+}
+
+// CleanupSessions deletes the expired sessions from the database. Expired
+// sessions are already rejected by GetUserFromSessionID, this only keeps the
+// sessions table from growing forever.
+func (skylb Skylab) CleanupSessions() (deleted int64, err error) {
+	ss := tables.SESSIONS()
+	deleted, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(ss).
+		Where(sq.Or(
+			ss.EXPIRES_AT.LeTime(time.Now()),
+			ss.LAST_SEEN_AT.LeTime(time.Now().Add(-SessionIdleTimeout)),
+		)).
+		Exec(skylb.DB, sq.ErowsAffected)
+	return deleted, erro.Wrap(err)
+}
+
+// RunSessionCleanup calls CleanupSessions every interval until ctx is
+// cancelled.
+func (skylb Skylab) RunSessionCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := skylb.CleanupSessions()
+		if err != nil {
+			skylb.Log.Printf("session cleanup: %+v", err)
+		} else if deleted > 0 {
+			skylb.Log.Printf("session cleanup: deleted %d expired sessions", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
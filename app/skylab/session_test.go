@@ -0,0 +1,36 @@
+package skylab
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestClientIP(t *testing.T) {
+	is := is.New(t)
+	trustedProxies, err := ParseTrustedProxies("127.0.0.1, 10.0.0.0/8")
+	is.NoErr(err)
+	r := httptest.NewRequest("GET", "/", nil)
+
+	// Requests that do not come from a trusted proxy use the remote address,
+	// whatever their X-Forwarded-For header says
+	r.RemoteAddr = "198.51.100.9:54321"
+	is.Equal(clientIP(r, trustedProxies), "198.51.100.9")
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	is.Equal(clientIP(r, trustedProxies), "198.51.100.9")
+	is.Equal(clientIP(r, nil), "198.51.100.9")
+
+	// Behind trusted proxies the client IP is the right-most untrusted hop, so
+	// the addresses prepended by the client are ignored
+	r.RemoteAddr = "127.0.0.1:54321"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7, 10.0.0.2")
+	is.Equal(clientIP(r, trustedProxies), "203.0.113.7")
+	r.Header.Set("X-Forwarded-For", "not-an-ip, 10.0.0.2")
+	is.Equal(clientIP(r, trustedProxies), "10.0.0.2")
+	r.Header.Del("X-Forwarded-For")
+	is.Equal(clientIP(r, trustedProxies), "127.0.0.1")
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	is.True(err != nil)
+}
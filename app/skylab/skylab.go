@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -30,6 +31,7 @@ import (
 	"github.com/DATA-DOG/go-txdb"
 	"github.com/bokwoon95/nusskylabx/helpers/erro"
 	"github.com/bokwoon95/nusskylabx/helpers/logutil"
+	"github.com/bokwoon95/nusskylabx/helpers/ratelimit"
 	"github.com/bokwoon95/nusskylabx/helpers/testutil"
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
@@ -79,6 +81,15 @@ type Config struct {
 	SecretKey    string // optional
 	DisableCsrf  string // optional
 
+	// TrustedProxies is a comma separated list of the IP addresses and CIDR
+	// ranges of the reverse proxies whose X-Forwarded-For header is trusted,
+	// see ParseTrustedProxies
+	TrustedProxies string // optional
+
+	// OIDCProviders is a JSON array of OpenID Connect providers to offer on
+	// the login page, see oidc.ParseConfigs
+	OIDCProviders string // optional
+
 	// Experimental
 	MailerEnabled string
 	SmtpHost      string
@@ -101,6 +112,15 @@ type Skylab struct {
 	DisableCsrf bool
 	Templates   *template.Template
 
+	// RateLimitStore keeps the counts for the RateLimit middleware. It is
+	// in memory until a database connection is made, after which it is
+	// shared through the database.
+	RateLimitStore ratelimit.Store
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header is
+	// used to find the IP address of the client.
+	TrustedProxies []*net.IPNet
+
 	// Mailer
 	// NOTE: not used
 	MailerEnabled bool
@@ -167,8 +187,22 @@ func NewWithoutDB(config Config) Skylab {
 	// DisableCsrf
 	skylb.DisableCsrf = config.DisableCsrf == "true"
 
+	// RateLimitStore
+	skylb.RateLimitStore = ratelimit.NewMemoryStore()
+
+	// TrustedProxies
+	trustedProxies, err := ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid config.TrustedProxies: %s", err)
+	}
+	skylb.TrustedProxies = trustedProxies
+
+	// OIDCProviders
+	if err := auth.LoadOIDCProviders(config.OIDCProviders); err != nil {
+		log.Fatalf("Invalid config.OIDCProviders: %s", err)
+	}
+
 	// Mailer
-	var err error
 	skylb.MailerEnabled = config.MailerEnabled == "true"
 	skylb.SmtpHost = config.SmtpHost
 	skylb.SmtpPort, err = strconv.Atoi(config.SmtpPort)
@@ -197,6 +231,7 @@ func New(config Config) (Skylab, error) {
 	if err != nil {
 		return skylb, erro.Wrap(fmt.Errorf("Could not ping the database, is the database running? %w", err))
 	}
+	skylb.RateLimitStore = PostgresRateLimitStore{DB: skylb.DB}
 	err = EnsureDatabasePopulated(config.DatabaseURL, config.MigrationDir) // Ensure database is not empty
 	if err != nil {
 		return skylb, erro.Wrap(err)
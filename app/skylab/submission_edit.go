@@ -54,12 +54,19 @@ func (skylb Skylab) SubmissionEdit(role string) http.HandlerFunc {
 			}
 			return
 		}
+		r, data.FormErrors = skylb.GetFormErrors(w, r)
+		if data.FormErrors != nil {
+			// Show the answers the user just posted instead of the saved ones
+			data.Submission.SubmissionAnswers = formx.ExtractAnswers(r.Form, data.Submission.SubmissionForm.Questions)
+		}
 		switch role {
 		case RoleStudent:
 			data.PreviewURL = StudentSubmission + "/" + strconv.Itoa(submissionID) + "/preview"
 			data.UpdateURL = StudentSubmission + "/" + strconv.Itoa(submissionID) + "/update"
 			data.SubmitURL = StudentSubmission + "/" + strconv.Itoa(submissionID) + "/submit"
+			data.HistoryURL = StudentSubmission + "/" + strconv.Itoa(submissionID) + "/history"
 		}
+		data.UpdatedAt = UpdatedAtValue(data.Submission.UpdatedAt)
 
 		// Team evaluations
 		te := tables.V_TEAM_EVALUATIONS()
@@ -95,6 +102,30 @@ func (skylb Skylab) SubmissionEdit(role string) http.HandlerFunc {
 			skylb.InternalServerError(w, r, err)
 			return
 		}
+
+		// The team only sees what the peer review settings allow
+		if role == RoleStudent {
+			period := data.Submission.SubmissionForm.Period
+			var settings PeerReviewSettings
+			settings, data.EvaluationsWithheld, err = skylb.EvaluationsWithheld(data.Submission.Team.TeamID, period.Cohort, period.Milestone)
+			if err != nil {
+				skylb.InternalServerError(w, r, err)
+				return
+			}
+			switch {
+			case data.EvaluationsWithheld != "":
+				data.PeerEvaluations = nil
+				data.AdviserEvaluation = UserEvaluation{}
+				data.MentorEvaluation = UserEvaluation{}
+			case settings.Anonymous:
+				data.AnonymousEvaluators = true
+				for i := range data.PeerEvaluations {
+					data.PeerEvaluations[i].Anonymize()
+				}
+				data.AdviserEvaluation.Anonymize()
+				data.MentorEvaluation.Anonymize()
+			}
+		}
 		render(data, msgs)
 	}
 }
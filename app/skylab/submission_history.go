@@ -0,0 +1,71 @@
+package skylab
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// SubmissionHistory lists the revisions of a submission along with what
+// changed in each. Students who can edit the submission may restore an
+// earlier revision while the submission is still open.
+func (skylb Skylab) SubmissionHistory(role string) http.HandlerFunc {
+	if !Contains(Roles(), role) {
+		panic(fmt.Errorf("%s is not a valid skylab role", role))
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		skylb.Log.TraceRequest(r)
+		headers.DoNotCache(w)
+		var data SubmissionHistoryData
+		submissionID, err := urlparams.Int(r, "submissionID")
+		if err != nil {
+			skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		s := tables.V_SUBMISSIONS()
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(s).
+			Where(s.SUBMISSION_ID.EqInt(submissionID)).
+			SelectRowx((&data.Submission).RowMapper(s)).
+			Fetch(skylb.DB)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				skylb.BadRequest(w, r, fmt.Sprintf("No submission found for submissionID %d", submissionID))
+			default:
+				skylb.InternalServerError(w, r, err)
+			}
+			return
+		}
+		data.Revisions, err = skylb.SubmissionRevisions(submissionID, data.Submission.SubmissionForm.Questions)
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		canEdit, _ := r.Context().Value(ContextCanEditSubmission).(bool)
+		switch role {
+		case RoleStudent:
+			data.ViewURL = StudentSubmission + "/" + strconv.Itoa(submissionID)
+			if canEdit && data.Submission.Open() {
+				data.RestoreURL = StudentSubmission + "/" + strconv.Itoa(submissionID) + "/restore"
+				data.UpdatedAt = UpdatedAtValue(data.Submission.UpdatedAt)
+			}
+		case RoleAdviser:
+			data.ViewURL = AdviserSubmission + "/" + strconv.Itoa(submissionID)
+		case RoleMentor:
+			data.ViewURL = MentorSubmission + "/" + strconv.Itoa(submissionID)
+		}
+		funcs := formx.Funcs(nil, skylb.Policy)
+		funcs["richText"] = isRichText
+		r = skylb.SetRoleSection(w, r, role, skylb.getSectionFromSubmissionID(submissionID, role))
+		skylb.Render(w, r, data, funcs, "app/skylab/submission_history.html")
+	}
+}
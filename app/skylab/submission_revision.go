@@ -0,0 +1,197 @@
+package skylab
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// SubmissionRevision is a copy of the answers of a submission, taken every
+// time they change (see sql/triggers/submission_revisions.sql).
+type SubmissionRevision struct {
+	Revision     int
+	FormVersion  int
+	Answers      formx.Answers
+	RestoredFrom int // 0 if the revision was not restored from another
+	CreatedBy    User
+	CreatedAt    sql.NullTime
+
+	// Changes are the answers that changed from the previous revision
+	Changes []formx.Change
+}
+
+// SubmissionRevisions returns the revisions of a submission, newest first.
+// The Changes of each revision are computed against the given questions
+// (usually the current questions of the submission form).
+func (skylb Skylab) SubmissionRevisions(submissionID int, questions formx.Questions) ([]SubmissionRevision, error) {
+	var revisions []SubmissionRevision
+	var revision SubmissionRevision
+	sr, u := tables.SUBMISSION_REVISIONS(), tables.USERS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(sr).
+		LeftJoin(u, u.USER_ID.Eq(sr.CREATED_BY)).
+		Where(sr.SUBMISSION_ID.EqInt(submissionID)).
+		OrderBy(sr.REVISION).
+		Selectx(func(row *sq.Row) {
+			revision = SubmissionRevision{
+				Revision:     row.Int(sr.REVISION),
+				FormVersion:  row.Int(sr.SUBMISSION_FORM_VERSION),
+				RestoredFrom: row.Int(sr.RESTORED_FROM),
+				CreatedBy: User{
+					Valid:       row.IntValid(u.USER_ID),
+					UserID:      row.Int(u.USER_ID),
+					Displayname: row.String(u.DISPLAYNAME),
+					Email:       row.String(u.EMAIL),
+				},
+				CreatedAt: row.NullTime(sr.CREATED_AT),
+			}
+			row.ScanInto(&revision.Answers, sr.SUBMISSION_DATA)
+		}, func() {
+			var previous formx.Answers
+			if len(revisions) > 0 {
+				previous = revisions[len(revisions)-1].Answers
+			}
+			revision.Changes = formx.Diff(questions, previous, revision.Answers)
+			revisions = append(revisions, revision)
+		}).
+		Fetch(skylb.DB)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, nil
+}
+
+// SaveSubmissionAnswers saves the answers of a submission on behalf of the
+// user, adding a new revision if they changed. The answers are only saved if
+// the submission was last updated at updatedAt, otherwise saved is false and
+// nothing is changed.
+func (skylb Skylab) SaveSubmissionAnswers(submissionID, userID int, answers formx.Answers, updatedAt time.Time) (saved bool, err error) {
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	err = setRevisionVars(tx, userID, 0)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, erro.Wrap(err)
+	}
+	s := tables.SUBMISSIONS()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		Update(s).
+		Set(s.SUBMISSION_DATA.Set(answers)).
+		Where(
+			s.SUBMISSION_ID.EqInt(submissionID),
+			s.UPDATED_AT.EqTime(updatedAt),
+		).
+		Exec(tx, sq.ErowsAffected)
+	if err != nil || rowsAffected == 0 {
+		_ = tx.Rollback()
+		return false, erro.Wrap(err)
+	}
+	return true, erro.Wrap(tx.Commit())
+}
+
+// RestoreSubmissionRevision replaces the answers, readme, poster and video of
+// a submission with those of one of its earlier revisions on behalf of the
+// user. The restored answers become a new revision so that nothing is lost.
+// Like SaveSubmissionAnswers, the submission is only restored if it was last
+// updated at updatedAt, otherwise an ErrSubmissionChanged is returned. If the
+// submission has been submitted and the revision's answers are not valid for
+// the current questions, the formx.ValidationErrors are returned and nothing
+// is restored.
+func (skylb Skylab) RestoreSubmissionRevision(submissionID, revision, userID int, updatedAt time.Time) error {
+	var submission Submission
+	s := tables.V_SUBMISSIONS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(s).
+		Where(s.SUBMISSION_ID.EqInt(submissionID)).
+		SelectRowx((&submission).RowMapper(s)).
+		Fetch(skylb.DB)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if !submission.Open() {
+		return erro.Errorf(ErrSubmissionClosed, submissionID)
+	}
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	err = setRevisionVars(tx, userID, revision)
+	if err != nil {
+		_ = tx.Rollback()
+		return erro.Wrap(err)
+	}
+	var answers formx.Answers
+	var readme, poster, video string
+	err = tx.QueryRow(`
+	SELECT submission_data, readme, poster, video FROM submission_revisions WHERE submission_id = $1 AND revision = $2
+	`, submissionID, revision).Scan(&answers, &readme, &poster, &video)
+	if err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return erro.Errorf(ErrRevisionNotExist, submissionID, revision)
+		}
+		return erro.Wrap(err)
+	}
+	// A submitted submission must stay valid against its current questions,
+	// which may have changed since the revision was saved
+	if submission.Submitted {
+		var questions formx.Questions
+		err = tx.QueryRow(`SELECT questions FROM forms WHERE form_id = $1`, submission.SubmissionForm.FormID).Scan(&questions)
+		if err != nil {
+			_ = tx.Rollback()
+			return erro.Wrap(err)
+		}
+		if errs := formx.Validate(questions, answers); errs != nil {
+			_ = tx.Rollback()
+			return errs
+		}
+	}
+	sub := tables.SUBMISSIONS()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		Update(sub).
+		Set(
+			sub.SUBMISSION_DATA.Set(answers),
+			sub.README.SetString(readme),
+			sub.POSTER.SetString(poster),
+			sub.VIDEO.SetString(video),
+		).
+		Where(
+			sub.SUBMISSION_ID.EqInt(submissionID),
+			sub.UPDATED_AT.EqTime(updatedAt),
+		).
+		Exec(tx, sq.ErowsAffected)
+	if err != nil {
+		_ = tx.Rollback()
+		return erro.Wrap(err)
+	}
+	if rowsAffected == 0 {
+		_ = tx.Rollback()
+		return erro.Errorf(ErrSubmissionChanged, submissionID)
+	}
+	return erro.Wrap(tx.Commit())
+}
+
+// setRevisionVars sets the variables that trg.submission_revisions records
+// in the revision it adds, for the rest of the transaction only.
+func setRevisionVars(tx *sql.Tx, userID, restoredFrom int) error {
+	var userIDValue, restoredFromValue string
+	if userID != 0 {
+		userIDValue = strconv.Itoa(userID)
+	}
+	if restoredFrom != 0 {
+		restoredFromValue = strconv.Itoa(restoredFrom)
+	}
+	_, err := tx.Exec(`SELECT set_config('var.user_id', $1, TRUE), set_config('var.restored_from', $2, TRUE)`, userIDValue, restoredFromValue)
+	return err
+}
@@ -50,6 +50,14 @@ func (skylb Skylab) SubmissionView(role string) http.HandlerFunc {
 			}
 			return
 		}
+		switch role {
+		case RoleStudent:
+			data.HistoryURL = StudentSubmission + "/" + strconv.Itoa(submissionID) + "/history"
+		case RoleAdviser:
+			data.HistoryURL = AdviserSubmission + "/" + strconv.Itoa(submissionID) + "/history"
+		case RoleMentor:
+			data.HistoryURL = MentorSubmission + "/" + strconv.Itoa(submissionID) + "/history"
+		}
 		canEdit, _ := r.Context().Value(ContextCanEditSubmission).(bool)
 		if canEdit {
 			switch role {
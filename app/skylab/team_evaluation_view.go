@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
 	"github.com/bokwoon95/nusskylabx/helpers/formx"
 	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
 	"github.com/bokwoon95/nusskylabx/tables"
@@ -50,8 +51,29 @@ func (skylb Skylab) TeamEvaluationView(role string) http.HandlerFunc {
 				data.EditURL = StudentTeamEvaluation + "/" + strconv.Itoa(teamEvaluationID) + "/edit"
 			}
 			data.SubmissionURL = StudentSubmission + "/" + strconv.Itoa(data.TeamEvaluation.Evaluatee.SubmissionID)
+			if !canEdit {
+				// The user is in the evaluatee team
+				period := data.TeamEvaluation.EvaluationForm.Period
+				settings, reason, err := skylb.EvaluationsWithheld(data.TeamEvaluation.Evaluatee.Team.TeamID, period.Cohort, period.Milestone)
+				if err != nil {
+					skylb.InternalServerError(w, r, err)
+					return
+				}
+				if reason != "" {
+					msgs[flash.Error] = []string{reason}
+					r, _ = skylb.SetFlashMsgs(w, r, msgs)
+					http.Redirect(w, r, data.SubmissionURL, http.StatusSeeOther)
+					return
+				}
+				if settings.Anonymous {
+					data.Anonymous = true
+					data.TeamEvaluation.Anonymize()
+				}
+			}
 		case RoleAdviser:
 			data.SubmissionURL = AdviserSubmission + "/" + strconv.Itoa(data.TeamEvaluation.Evaluatee.SubmissionID)
+		case RoleMentor:
+			data.SubmissionURL = MentorSubmission + "/" + strconv.Itoa(data.TeamEvaluation.Evaluatee.SubmissionID)
 		}
 		funcs := formx.Funcs(nil, skylb.Policy)
 		r, _ = skylb.SetFlashMsgs(w, r, msgs)
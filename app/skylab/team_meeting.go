@@ -0,0 +1,258 @@
+package skylab
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// TeamMeetingDateLayout is the layout of the meeting dates posted by the
+// meeting log form and written in the meeting log export.
+const TeamMeetingDateLayout = "2006-01-02"
+
+// TeamMeeting is a meeting an adviser logged with one of their teams.
+type TeamMeeting struct {
+	Valid             bool
+	TeamMeetingID     int
+	Team              Team // only the TeamID, Cohort, TeamName and ProjectLevel
+	AdviserUserRoleID int
+	Adviser           User
+	MeetingDate       time.Time
+	Notes             string
+	ActionItems       string
+	Attendance        []MeetingAttendance
+	UpdatedAt         sql.NullTime
+}
+
+// MeetingAttendance records whether a student of the team attended a meeting.
+type MeetingAttendance struct {
+	StudentUserRoleID int
+	Student           User
+	Attended          bool
+}
+
+// Attended reports whether the student with the user_role_id attended the
+// meeting.
+func (meeting TeamMeeting) Attended(studentUserRoleID int) bool {
+	for _, attendance := range meeting.Attendance {
+		if attendance.StudentUserRoleID == studentUserRoleID {
+			return attendance.Attended
+		}
+	}
+	return false
+}
+
+// Students returns the valid students of the team, whose user_role_ids are
+// under Roles[RoleStudent] when the team was mapped from V_TEAMS.
+func (t Team) Students() []User {
+	var students []User
+	for _, student := range []User{t.Student1, t.Student2} {
+		if student.Valid {
+			students = append(students, student)
+		}
+	}
+	return students
+}
+
+// TeamMeetings returns the team meetings that match the predicates, latest
+// first.
+func (skylb Skylab) TeamMeetings(predicates ...sq.Predicate) ([]TeamMeeting, error) {
+	var meetings []TeamMeeting
+	var meeting TeamMeeting
+	var attendance MeetingAttendance
+	tm, tma, t := tables.TEAM_MEETINGS(), tables.TEAM_MEETING_ATTENDANCE(), tables.TEAMS()
+	adviser, adviserRole := tables.USERS().As("adviser"), tables.USER_ROLES().As("adviser_role")
+	student, studentRole := tables.USERS().As("student"), tables.USER_ROLES().As("student_role")
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(tm).
+		Join(t, t.TEAM_ID.Eq(tm.TEAM_ID)).
+		Join(adviserRole, adviserRole.USER_ROLE_ID.Eq(tm.ADVISER_USER_ROLE_ID)).
+		Join(adviser, adviser.USER_ID.Eq(adviserRole.USER_ID)).
+		LeftJoin(tma, tma.TEAM_MEETING_ID.Eq(tm.TEAM_MEETING_ID)).
+		LeftJoin(studentRole, studentRole.USER_ROLE_ID.Eq(tma.STUDENT_USER_ROLE_ID)).
+		LeftJoin(student, student.USER_ID.Eq(studentRole.USER_ID)).
+		Where(predicates...).
+		OrderBy(tm.MEETING_DATE.Desc(), tm.TEAM_MEETING_ID.Desc(), student.DISPLAYNAME).
+		Selectx(func(row *sq.Row) {
+			meeting = TeamMeeting{
+				Valid:         row.IntValid(tm.TEAM_MEETING_ID),
+				TeamMeetingID: row.Int(tm.TEAM_MEETING_ID),
+				Team: Team{
+					Valid:        row.IntValid(t.TEAM_ID),
+					TeamID:       row.Int(t.TEAM_ID),
+					Cohort:       row.String(t.COHORT),
+					TeamName:     row.String(t.TEAM_NAME),
+					ProjectLevel: row.String(t.PROJECT_LEVEL),
+				},
+				AdviserUserRoleID: row.Int(tm.ADVISER_USER_ROLE_ID),
+				Adviser: User{
+					Valid:       row.IntValid(adviser.USER_ID),
+					UserID:      row.Int(adviser.USER_ID),
+					Displayname: row.String(adviser.DISPLAYNAME),
+					Email:       row.String(adviser.EMAIL),
+				},
+				MeetingDate: row.Time(tm.MEETING_DATE),
+				Notes:       row.String(tm.NOTES),
+				ActionItems: row.String(tm.ACTION_ITEMS),
+				UpdatedAt:   row.NullTime(tm.UPDATED_AT),
+			}
+			attendance = MeetingAttendance{
+				StudentUserRoleID: row.Int(tma.STUDENT_USER_ROLE_ID),
+				Student: User{
+					Valid:       row.IntValid(student.USER_ID),
+					UserID:      row.Int(student.USER_ID),
+					Displayname: row.String(student.DISPLAYNAME),
+					Email:       row.String(student.EMAIL),
+				},
+				Attended: row.Bool(tma.ATTENDED),
+			}
+		}, func() {
+			// Each row is one student's attendance of a meeting, and the rows
+			// of the same meeting are next to each other
+			if n := len(meetings); n == 0 || meetings[n-1].TeamMeetingID != meeting.TeamMeetingID {
+				meetings = append(meetings, meeting)
+			}
+			if attendance.StudentUserRoleID != 0 {
+				last := &meetings[len(meetings)-1]
+				last.Attendance = append(last.Attendance, attendance)
+			}
+		}).
+		Fetch(skylb.DB)
+	return meetings, erro.Wrap(err)
+}
+
+// SaveTeamMeeting logs a new meeting of an adviser with their team, or updates
+// the meeting with the TeamMeetingID if it is set. The attendance of the
+// meeting is replaced with meeting.Attendance.
+func (skylb Skylab) SaveTeamMeeting(meeting TeamMeeting) (teamMeetingID int, err error) {
+	t := tables.TEAMS()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		SelectOne().
+		From(t).
+		Where(
+			t.TEAM_ID.EqInt(meeting.Team.TeamID),
+			t.ADVISER_USER_ROLE_ID.EqInt(meeting.AdviserUserRoleID),
+		).
+		Exec(skylb.DB, sq.ErowsAffected)
+	if err != nil {
+		return 0, erro.Wrap(err)
+	}
+	if rowsAffected == 0 {
+		return 0, erro.Errorf(ErrTeamNotAdvised, meeting.Team.TeamID, meeting.AdviserUserRoleID)
+	}
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return 0, erro.Wrap(err)
+	}
+	tm, tma := tables.TEAM_MEETINGS(), tables.TEAM_MEETING_ATTENDANCE()
+	teamMeetingID = meeting.TeamMeetingID
+	if teamMeetingID == 0 {
+		err = sq.WithDefaultLog(sq.Lverbose).
+			InsertInto(tm).
+			Columns(tm.TEAM_ID, tm.ADVISER_USER_ROLE_ID, tm.MEETING_DATE, tm.NOTES, tm.ACTION_ITEMS).
+			Values(meeting.Team.TeamID, meeting.AdviserUserRoleID, meeting.MeetingDate, meeting.Notes, meeting.ActionItems).
+			ReturningRowx(func(row *sq.Row) { teamMeetingID = row.Int(tm.TEAM_MEETING_ID) }).
+			Fetch(tx)
+	} else {
+		rowsAffected, err = sq.WithDefaultLog(sq.Lverbose).
+			Update(tm).
+			Set(
+				tm.MEETING_DATE.SetTime(meeting.MeetingDate),
+				tm.NOTES.SetString(meeting.Notes),
+				tm.ACTION_ITEMS.SetString(meeting.ActionItems),
+			).
+			Where(
+				tm.TEAM_MEETING_ID.EqInt(teamMeetingID),
+				tm.TEAM_ID.EqInt(meeting.Team.TeamID),
+				tm.ADVISER_USER_ROLE_ID.EqInt(meeting.AdviserUserRoleID),
+			).
+			Exec(tx, sq.ErowsAffected)
+		if err == nil && rowsAffected == 0 {
+			err = erro.Errorf(ErrTeamMeetingNotExist, teamMeetingID, meeting.AdviserUserRoleID)
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, erro.Wrap(err)
+	}
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(tma).
+		Where(tma.TEAM_MEETING_ID.EqInt(teamMeetingID)).
+		Exec(tx, 0)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, erro.Wrap(err)
+	}
+	for _, attendance := range meeting.Attendance {
+		_, err = sq.WithDefaultLog(sq.Lverbose).
+			InsertInto(tma).
+			Columns(tma.TEAM_MEETING_ID, tma.STUDENT_USER_ROLE_ID, tma.ATTENDED).
+			Values(teamMeetingID, attendance.StudentUserRoleID, attendance.Attended).
+			Exec(tx, 0)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, erro.Wrap(err)
+		}
+	}
+	return teamMeetingID, erro.Wrap(tx.Commit())
+}
+
+// DeleteTeamMeeting deletes a meeting logged by the adviser.
+func (skylb Skylab) DeleteTeamMeeting(teamMeetingID, adviserUserRoleID int) error {
+	tm := tables.TEAM_MEETINGS()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(tm).
+		Where(
+			tm.TEAM_MEETING_ID.EqInt(teamMeetingID),
+			tm.ADVISER_USER_ROLE_ID.EqInt(adviserUserRoleID),
+		).
+		Exec(skylb.DB, sq.ErowsAffected)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if rowsAffected == 0 {
+		return erro.Errorf(ErrTeamMeetingNotExist, teamMeetingID, adviserUserRoleID)
+	}
+	return nil
+}
+
+// WriteTeamMeetingsCSV writes the meetings as CSV, one meeting per row.
+func WriteTeamMeetingsCSV(w io.Writer, meetings []TeamMeeting) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"Date", "Team ID", "Team", "Adviser", "Attended", "Absent", "Notes", "Action Items"})
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	for _, meeting := range meetings {
+		var attended, absent []string
+		for _, attendance := range meeting.Attendance {
+			if attendance.Attended {
+				attended = append(attended, attendance.Student.Displayname)
+			} else {
+				absent = append(absent, attendance.Student.Displayname)
+			}
+		}
+		err = cw.Write([]string{
+			meeting.MeetingDate.Format(TeamMeetingDateLayout),
+			strconv.Itoa(meeting.Team.TeamID),
+			meeting.Team.TeamName,
+			meeting.Adviser.Displayname,
+			strings.Join(attended, "; "),
+			strings.Join(absent, "; "),
+			meeting.Notes,
+			meeting.ActionItems,
+		})
+		if err != nil {
+			return erro.Wrap(err)
+		}
+	}
+	cw.Flush()
+	return erro.Wrap(cw.Error())
+}
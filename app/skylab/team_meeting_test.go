@@ -0,0 +1,40 @@
+package skylab
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWriteTeamMeetingsCSV(t *testing.T) {
+	is := is.New(t)
+	meetings := []TeamMeeting{
+		{
+			Team:        Team{TeamID: 7, TeamName: "Rocket"},
+			Adviser:     User{Displayname: "Alice"},
+			MeetingDate: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+			Notes:       "Went through the poster, \"needs work\"",
+			ActionItems: "Fix the README\nAdd tests",
+			Attendance: []MeetingAttendance{
+				{StudentUserRoleID: 1, Student: User{Displayname: "Bob"}, Attended: true},
+				{StudentUserRoleID: 2, Student: User{Displayname: "Carol"}},
+			},
+		},
+		{
+			Team:        Team{TeamID: 8, TeamName: "Lander"},
+			Adviser:     User{Displayname: "Alice"},
+			MeetingDate: time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	var b strings.Builder
+	err := WriteTeamMeetingsCSV(&b, meetings)
+	is.NoErr(err)
+	is.Equal(b.String(), "Date,Team ID,Team,Adviser,Attended,Absent,Notes,Action Items\n"+
+		"2020-06-01,7,Rocket,Alice,Bob,Carol,\"Went through the poster, \"\"needs work\"\"\",\"Fix the README\nAdd tests\"\n"+
+		"2020-05-20,8,Lander,Alice,,,,\n")
+	is.True(meetings[0].Attended(1))
+	is.True(!meetings[0].Attended(2))
+	is.True(!meetings[1].Attended(1))
+}
@@ -0,0 +1,206 @@
+package skylab
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// TeamStatusChange is a status transition of a team, recorded by
+// trg.team_status_history (see sql/triggers/team_status_history.sql).
+type TeamStatusChange struct {
+	TeamID    int
+	OldStatus string // empty for the status the team was created with
+	NewStatus string
+	Reason    string
+	ChangedBy User
+	CreatedAt sql.NullTime
+}
+
+// TeamStatusHistory returns the status transitions of the teams that match the
+// predicates, latest first.
+func (skylb Skylab) TeamStatusHistory(predicates ...sq.Predicate) ([]TeamStatusChange, error) {
+	var history []TeamStatusChange
+	var change TeamStatusChange
+	tsh, t, u := tables.TEAM_STATUS_HISTORY(), tables.TEAMS(), tables.USERS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(tsh).
+		Join(t, t.TEAM_ID.Eq(tsh.TEAM_ID)).
+		LeftJoin(u, u.USER_ID.Eq(tsh.CHANGED_BY)).
+		Where(predicates...).
+		OrderBy(tsh.CREATED_AT.Desc(), tsh.TEAM_STATUS_HISTORY_ID.Desc()).
+		Selectx(func(row *sq.Row) {
+			change = TeamStatusChange{
+				TeamID:    row.Int(tsh.TEAM_ID),
+				OldStatus: row.String(tsh.OLD_STATUS),
+				NewStatus: row.String(tsh.NEW_STATUS),
+				Reason:    row.String(tsh.REASON),
+				ChangedBy: User{
+					Valid:       row.IntValid(u.USER_ID),
+					UserID:      row.Int(u.USER_ID),
+					Displayname: row.String(u.DISPLAYNAME),
+					Email:       row.String(u.EMAIL),
+				},
+				CreatedAt: row.NullTime(tsh.CREATED_AT),
+			}
+		}, func() {
+			history = append(history, change)
+		}).
+		Fetch(skylb.DB)
+	return history, erro.Wrap(err)
+}
+
+// SetTeamStatus changes the status of a team on behalf of the user, recording
+// the reason in team_status_history. A reason must always be given. It returns
+// the status the team had before, which is the same as status if nothing was
+// changed.
+func (skylb Skylab) SetTeamStatus(teamID int, status, reason string, userID int) (oldStatus string, err error) {
+	if !Contains(TeamStatuses(), status) {
+		return "", erro.Wrap(erro.Errorf(ErrTeamStatusInvalid, status))
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", erro.Wrap(erro.Errorf(ErrTeamStatusReasonEmpty, teamID))
+	}
+	tx, err := skylb.DB.Begin()
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec(`SELECT set_config('var.user_id', $1, TRUE), set_config('var.reason', $2, TRUE)`, strconv.Itoa(userID), reason)
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	err = tx.QueryRow(`SELECT status FROM teams WHERE team_id = $1 FOR UPDATE`, teamID).Scan(&oldStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", erro.Wrap(erro.Errorf(ErrTeamNotExist, strconv.Itoa(teamID)))
+		}
+		return "", erro.Wrap(err)
+	}
+	if oldStatus != status {
+		_, err = tx.Exec(`UPDATE teams SET status = $1 WHERE team_id = $2`, status, teamID)
+		if err != nil {
+			return "", erro.Wrap(err)
+		}
+	}
+	err = tx.Commit()
+	return oldStatus, erro.Wrap(err)
+}
+
+// SendTeamUncontactableEmail emails the admins of the team's cohort that the
+// team has been marked uncontactable.
+func (skylb Skylab) SendTeamUncontactableEmail(team Team, change TeamStatusChange) error {
+	var to []string
+	ur, u := tables.USER_ROLES(), tables.USERS()
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(ur).
+		Join(u, u.USER_ID.Eq(ur.USER_ID)).
+		Where(
+			ur.COHORT.EqString(team.Cohort),
+			ur.ROLE.EqString(RoleAdmin),
+			ur.DELETED_AT.IsNull(),
+		).
+		Selectx(func(row *sq.Row) {
+			if email := row.String(u.EMAIL); email != "" {
+				to = append(to, email)
+			}
+		}, nil).
+		Fetch(skylb.DB)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	link := skylb.BaseURLWithProtocol() + AdminTeam + "/" + strconv.Itoa(team.TeamID)
+	subject := fmt.Sprintf("Team %s has been marked uncontactable", team.TeamName)
+	message := fmt.Sprintf(`<p>%s marked the team <b>%s</b> (cohort %s) as <b>uncontactable</b>.</p>
+<p>Reason: %s</p>
+<p>You can see the status history of the team on <a href="%s">its team page</a>.</p>`,
+		html.EscapeString(change.ChangedBy.Displayname), html.EscapeString(team.TeamName),
+		html.EscapeString(team.Cohort), html.EscapeString(change.Reason), link,
+	)
+	return erro.Wrap(skylb.SendMail(to, subject, message))
+}
+
+// teamStatusRanks orders the team statuses from worst to best.
+var teamStatusRanks = map[string]int{
+	TeamStatusUncontactable: 0,
+	TeamStatusOk:            1,
+	TeamStatusGood:          2,
+}
+
+// AdviserTeamStatusTrend summarises the statuses of an adviser's teams: how
+// many of them are in each status now, and how many status changes in a
+// period were for the better or the worse.
+type AdviserTeamStatusTrend struct {
+	Adviser       User // invalid for the teams without an adviser
+	Teams         int
+	Good          int
+	Ok            int
+	Uncontactable int
+	Improved      int
+	Worsened      int
+}
+
+// TeamStatusTrends groups the teams by their adviser and summarises their
+// statuses, counting the changes in history made at or after since. The
+// teams must have been mapped from V_TEAMS. Advisers are ordered by name, with
+// the teams without an adviser last.
+func TeamStatusTrends(teams []Team, history []TeamStatusChange, since time.Time) []AdviserTeamStatusTrend {
+	var trends []AdviserTeamStatusTrend
+	// trendIndex maps an adviser's user_id to its index in trends, and
+	// teamTrend maps a teamID to the index of its adviser
+	trendIndex := make(map[int]int)
+	teamTrend := make(map[int]int)
+	for _, team := range teams {
+		i, ok := trendIndex[team.Adviser.UserID]
+		if !ok {
+			i = len(trends)
+			trendIndex[team.Adviser.UserID] = i
+			trends = append(trends, AdviserTeamStatusTrend{Adviser: team.Adviser})
+		}
+		teamTrend[team.TeamID] = i
+		trends[i].Teams++
+		switch team.Status {
+		case TeamStatusGood:
+			trends[i].Good++
+		case TeamStatusOk:
+			trends[i].Ok++
+		case TeamStatusUncontactable:
+			trends[i].Uncontactable++
+		}
+	}
+	for _, change := range history {
+		i, ok := teamTrend[change.TeamID]
+		if !ok || change.OldStatus == "" || !change.CreatedAt.Valid || change.CreatedAt.Time.Before(since) {
+			continue
+		}
+		switch oldRank, newRank := teamStatusRanks[change.OldStatus], teamStatusRanks[change.NewStatus]; {
+		case newRank > oldRank:
+			trends[i].Improved++
+		case newRank < oldRank:
+			trends[i].Worsened++
+		}
+	}
+	sort.SliceStable(trends, func(i, j int) bool {
+		if trends[i].Adviser.Valid != trends[j].Adviser.Valid {
+			return trends[i].Adviser.Valid
+		}
+		return trends[i].Adviser.Displayname < trends[j].Adviser.Displayname
+	})
+	return trends
+}
@@ -0,0 +1,37 @@
+package skylab
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTeamStatusTrends(t *testing.T) {
+	is := is.New(t)
+	since := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) sql.NullTime { return sql.NullTime{Valid: true, Time: since.Add(d)} }
+	alice := User{Valid: true, UserID: 1, Displayname: "Alice"}
+	bob := User{Valid: true, UserID: 2, Displayname: "Bob"}
+	teams := []Team{
+		{TeamID: 1, Adviser: bob, Status: TeamStatusGood},
+		{TeamID: 2, Adviser: alice, Status: TeamStatusUncontactable},
+		{TeamID: 3, Adviser: alice, Status: TeamStatusGood},
+		{TeamID: 4, Status: TeamStatusOk},
+	}
+	history := []TeamStatusChange{
+		{TeamID: 3, OldStatus: TeamStatusUncontactable, NewStatus: TeamStatusGood, CreatedAt: at(2 * time.Hour)},
+		{TeamID: 2, OldStatus: TeamStatusOk, NewStatus: TeamStatusUncontactable, CreatedAt: at(time.Hour)},
+		// Changes before since, and the status a team was created with, are not counted
+		{TeamID: 1, OldStatus: TeamStatusOk, NewStatus: TeamStatusGood, CreatedAt: at(-time.Hour)},
+		{TeamID: 1, NewStatus: TeamStatusOk, CreatedAt: at(time.Hour)},
+		// Changes of teams that are not in teams are ignored
+		{TeamID: 5, OldStatus: TeamStatusOk, NewStatus: TeamStatusGood, CreatedAt: at(time.Hour)},
+	}
+	trends := TeamStatusTrends(teams, history, since)
+	is.Equal(len(trends), 3)
+	is.Equal(trends[0], AdviserTeamStatusTrend{Adviser: alice, Teams: 2, Good: 1, Uncontactable: 1, Improved: 1, Worsened: 1})
+	is.Equal(trends[1], AdviserTeamStatusTrend{Adviser: bob, Teams: 1, Good: 1})
+	is.Equal(trends[2], AdviserTeamStatusTrend{Teams: 1, Ok: 1})
+}
@@ -0,0 +1,419 @@
+package skylab
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/auth"
+	"github.com/bokwoon95/nusskylabx/helpers/auth/totp"
+	"github.com/bokwoon95/nusskylabx/helpers/cookies"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/tables"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	TOTPIssuer            = "Skylab" // shown as the account name in authenticator apps
+	TOTPRecoveryCodeCount = 10
+)
+
+// TOTP is the two-factor authentication status of a user.
+type TOTP struct {
+	Valid             bool // whether the user has started setting up two-factor authentication
+	UserID            int
+	Secret            string
+	Enabled           bool // whether the user has confirmed a code, after which it is enforced
+	EnabledAt         time.Time
+	LastUsedStep      int64
+	RecoveryCodesLeft int
+}
+
+// ProvisioningURI is the otpauth:// URI that authenticator apps scan to add
+// the user's account.
+func (t TOTP) ProvisioningURI(email string) string {
+	return totp.URI(TOTPIssuer, email, t.Secret)
+}
+
+// QRCode renders the ProvisioningURI as an inline PNG QR code.
+func (t TOTP) QRCode(email string) (template.HTML, error) {
+	png, err := qrcode.Encode(t.ProvisioningURI(email), qrcode.Medium, 200)
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	src := "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+	return template.HTML(`<img src="` + src + `" alt="Two-factor authentication QR code" width="200" height="200">`), nil
+}
+
+// totpSecretPrefix marks the TOTP secrets in user_totp that have been
+// encrypted with skylb.SecretKey. Secrets saved before they were encrypted do
+// not have it, and are encrypted by EncryptTOTPSecrets.
+const totpSecretPrefix = "enc:"
+
+func (skylb Skylab) encryptTOTPSecret(secret string) (string, error) {
+	encrypted, err := auth.Encrypt(skylb.SecretKey, []byte(secret))
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	return totpSecretPrefix + encrypted, nil
+}
+
+func (skylb Skylab) decryptTOTPSecret(stored string) (string, error) {
+	if !strings.HasPrefix(stored, totpSecretPrefix) {
+		return stored, nil
+	}
+	secret, err := auth.Decrypt(skylb.SecretKey, strings.TrimPrefix(stored, totpSecretPrefix))
+	if err != nil {
+		return "", erro.Wrap(err)
+	}
+	return string(secret), nil
+}
+
+// EncryptTOTPSecrets encrypts the TOTP secrets in user_totp that are still
+// stored in plaintext. It returns the number of secrets encrypted.
+func (skylb Skylab) EncryptTOTPSecrets() (encrypted int, err error) {
+	rows, err := skylb.DB.Query(`SELECT user_id, secret FROM user_totp WHERE secret NOT LIKE $1 || '%'`, totpSecretPrefix)
+	if err != nil {
+		return 0, erro.Wrap(err)
+	}
+	secrets := make(map[int]string)
+	for rows.Next() {
+		var userID int
+		var secret string
+		err = rows.Scan(&userID, &secret)
+		if err != nil {
+			rows.Close()
+			return 0, erro.Wrap(err)
+		}
+		secrets[userID] = secret
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, erro.Wrap(err)
+	}
+	for userID, secret := range secrets {
+		stored, err := skylb.encryptTOTPSecret(secret)
+		if err != nil {
+			return encrypted, erro.Wrap(err)
+		}
+		// Only replace the secret if it has not been changed in the meantime
+		_, err = skylb.DB.Exec(`UPDATE user_totp SET secret = $1 WHERE user_id = $2 AND secret = $3`, stored, userID, secret)
+		if err != nil {
+			return encrypted, erro.Wrap(err)
+		}
+		encrypted++
+	}
+	return encrypted, nil
+}
+
+// GetTOTP gets the two-factor authentication status of a user. If the user
+// has not set up two-factor authentication the returned TOTP will not be
+// valid (but err will still be nil).
+func (skylb Skylab) GetTOTP(userID int) (t TOTP, err error) {
+	ut, trc := tables.USER_TOTP(), tables.TOTP_RECOVERY_CODES()
+	var enabledAt sql.NullTime
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ut).
+		Where(ut.USER_ID.EqInt(userID)).
+		SelectRowx(func(row *sq.Row) {
+			t.Valid = row.IntValid(ut.USER_ID)
+			t.UserID = row.Int(ut.USER_ID)
+			t.Secret = row.String(ut.SECRET)
+			row.ScanInto(&enabledAt, ut.ENABLED_AT)
+			t.LastUsedStep = row.Int64(ut.LAST_USED_STEP)
+		}).
+		Fetch(skylb.DB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, nil
+	}
+	if err != nil {
+		return t, erro.Wrap(err)
+	}
+	t.Secret, err = skylb.decryptTOTPSecret(t.Secret)
+	if err != nil {
+		return t, erro.Wrap(err)
+	}
+	t.Enabled, t.EnabledAt = enabledAt.Valid, enabledAt.Time
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		From(trc).
+		Where(trc.USER_ID.EqInt(userID), trc.USED_AT.IsNull()).
+		SelectOne().
+		Exec(skylb.DB, sq.ErowsAffected)
+	t.RecoveryCodesLeft = int(rowsAffected)
+	return t, erro.Wrap(err)
+}
+
+// SetupTOTP generates a new TOTP secret for the user. Two-factor
+// authentication is not enforced until the user confirms a code from their
+// authenticator app with EnableTOTP.
+func (skylb Skylab) SetupTOTP(userID int) (t TOTP, err error) {
+	t, err = skylb.GetTOTP(userID)
+	if err != nil {
+		return t, erro.Wrap(err)
+	}
+	if t.Enabled {
+		return t, erro.Wrap(erro.Errorf(ErrTOTPAlreadyEnabled))
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return t, erro.Wrap(err)
+	}
+	secret, err = skylb.encryptTOTPSecret(secret)
+	if err != nil {
+		return t, erro.Wrap(err)
+	}
+	_, err = skylb.DB.Exec(
+		`INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()`,
+		userID, secret,
+	)
+	if err != nil {
+		return t, erro.Wrap(err)
+	}
+	return skylb.GetTOTP(userID)
+}
+
+// EnableTOTP confirms that the user's authenticator app is producing the
+// right codes, after which two-factor authentication is enforced for the
+// user. It returns a fresh set of recovery codes.
+func (skylb Skylab) EnableTOTP(userID int, code string) (recoveryCodes []string, err error) {
+	t, err := skylb.GetTOTP(userID)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	if !t.Valid {
+		return nil, erro.Wrap(erro.Errorf(ErrTOTPNotSetUp))
+	}
+	if t.Enabled {
+		return nil, erro.Wrap(erro.Errorf(ErrTOTPAlreadyEnabled))
+	}
+	step, ok := totp.Validate(t.Secret, code, time.Now(), t.LastUsedStep)
+	if !ok {
+		return nil, erro.Wrap(erro.Errorf(ErrTOTPCodeInvalid))
+	}
+	ut := tables.USER_TOTP()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		Update(ut).
+		Set(
+			ut.ENABLED_AT.SetTime(time.Now()),
+			ut.LAST_USED_STEP.Set(step),
+		).
+		Where(ut.USER_ID.EqInt(userID)).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	return skylb.GenerateRecoveryCodes(userID)
+}
+
+// DisableTOTP turns off two-factor authentication for the user and deletes
+// their secret and recovery codes. Admins cannot turn it off.
+func (skylb Skylab) DisableTOTP(user User) error {
+	if user.Roles[RoleAdmin] != 0 {
+		return erro.Wrap(erro.Errorf(ErrTOTPRequired))
+	}
+	ut, trc := tables.USER_TOTP(), tables.TOTP_RECOVERY_CODES()
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(trc).
+		Where(trc.USER_ID.EqInt(user.UserID)).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(ut).
+		Where(ut.USER_ID.EqInt(user.UserID)).
+		Exec(skylb.DB, 0)
+	return erro.Wrap(err)
+}
+
+// GenerateRecoveryCodes replaces the user's recovery codes with new ones and
+// returns them in plaintext. Only their hashes are stored, so they cannot be
+// shown again.
+func (skylb Skylab) GenerateRecoveryCodes(userID int) (recoveryCodes []string, err error) {
+	trc := tables.TOTP_RECOVERY_CODES()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		DeleteFrom(trc).
+		Where(trc.USER_ID.EqInt(userID)).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	query := sq.WithDefaultLog(sq.Lverbose).
+		InsertInto(trc).
+		Columns(trc.USER_ID, trc.HASH)
+	for i := 0; i < TOTPRecoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, erro.Wrap(err)
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b)) // 8 characters
+		code = code[:4] + "-" + code[4:]
+		recoveryCodes = append(recoveryCodes, code)
+		query = query.Values(userID, skylb.Hash([]byte(code)))
+	}
+	_, err = query.Exec(skylb.DB, 0)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	return recoveryCodes, nil
+}
+
+// VerifyTOTP checks a code from the user's authenticator app, or one of
+// their unused recovery codes. Each code can only be used once.
+func (skylb Skylab) VerifyTOTP(userID int, code string) (ok bool, err error) {
+	t, err := skylb.GetTOTP(userID)
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	if !t.Enabled {
+		return false, nil
+	}
+	if step, ok := totp.Validate(t.Secret, code, time.Now(), t.LastUsedStep); ok {
+		ut := tables.USER_TOTP()
+		// Only update if last_used_step has not moved on in the meantime, so
+		// that two concurrent requests cannot both use the same code
+		rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+			Update(ut).
+			Set(ut.LAST_USED_STEP.Set(step)).
+			Where(ut.USER_ID.EqInt(userID), ut.LAST_USED_STEP.LtInt(int(step))).
+			Exec(skylb.DB, sq.ErowsAffected)
+		return rowsAffected != 0, erro.Wrap(err)
+	}
+	trc := tables.TOTP_RECOVERY_CODES()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		Update(trc).
+		Set(trc.USED_AT.SetTime(time.Now())).
+		Where(
+			trc.USER_ID.EqInt(userID),
+			trc.HASH.EqString(skylb.Hash([]byte(strings.ToLower(strings.TrimSpace(code))))),
+			trc.USED_AT.IsNull(),
+		).
+		Exec(skylb.DB, sq.ErowsAffected)
+	return rowsAffected != 0, erro.Wrap(err)
+}
+
+// sessionNotPendingTOTP is the predicate that a session is usable as far as
+// two-factor authentication is concerned: either its user has not enabled
+// two-factor authentication, or the session has been verified.
+func sessionNotPendingTOTP(ss tables.TABLE_SESSIONS) sq.Predicate {
+	ut := tables.USER_TOTP()
+	return sq.Or(
+		ss.TOTP_VERIFIED_AT.IsNotNull(),
+		sq.Not(sq.Exists(sq.
+			From(ut).
+			Where(ut.USER_ID.Eq(ss.USER_ID), ut.ENABLED_AT.IsNotNull()).
+			SelectOne(),
+		)),
+	)
+}
+
+// GetPendingTOTPUser gets the user whose session is waiting for a two-factor
+// authentication code. If the session is not pending, the returned user will
+// not be valid (but err will still be nil).
+func (skylb Skylab) GetPendingTOTPUser(sessionID string) (user User, err error) {
+	u, ss, ut := tables.USERS(), tables.SESSIONS(), tables.USER_TOTP()
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(ss).
+		Join(u, u.USER_ID.Eq(ss.USER_ID)).
+		Join(ut, ut.USER_ID.Eq(ss.USER_ID)).
+		Where(
+			ss.HASH.EqString(skylb.Hash([]byte(sessionID))),
+			ss.EXPIRES_AT.GtTime(time.Now()),
+			ss.TOTP_VERIFIED_AT.IsNull(),
+			ut.ENABLED_AT.IsNotNull(),
+		).
+		SelectRowx(func(row *sq.Row) {
+			user.Valid = row.IntValid(u.USER_ID)
+			user.UserID = row.Int(u.USER_ID)
+			user.Displayname = row.String(u.DISPLAYNAME)
+			user.Email = row.String(u.EMAIL)
+		}).
+		Fetch(skylb.DB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, nil
+	}
+	return user, erro.Wrap(err)
+}
+
+// SessionIsTOTPVerified reports whether the session has passed two-factor
+// authentication.
+func (skylb Skylab) SessionIsTOTPVerified(sessionID string) (verified bool, err error) {
+	ss := tables.SESSIONS()
+	rowsAffected, err := sq.WithDefaultLog(sq.Lverbose).
+		From(ss).
+		Where(
+			ss.HASH.EqString(skylb.Hash([]byte(sessionID))),
+			ss.TOTP_VERIFIED_AT.IsNotNull(),
+		).
+		SelectOne().
+		Exec(skylb.DB, sq.ErowsAffected)
+	return rowsAffected != 0, erro.Wrap(err)
+}
+
+// SetSessionTOTPVerified marks the session as having passed two-factor
+// authentication. If the session belongs to an admin, the admin session
+// cookie is set as well (SetSession holds it back until now).
+func (skylb Skylab) SetSessionTOTPVerified(w http.ResponseWriter, sessionID string) error {
+	ss := tables.SESSIONS()
+	_, err := sq.WithDefaultLog(sq.Lverbose).
+		Update(ss).
+		Set(ss.TOTP_VERIFIED_AT.SetTime(time.Now())).
+		Where(ss.HASH.EqString(skylb.Hash([]byte(sessionID)))).
+		Exec(skylb.DB, 0)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	isAdmin, err := skylb.SessionIdIsValidRole(sessionID, RoleAdmin)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if isAdmin {
+		cookies.SetCookie(w, AdminSessionCookieName, sessionID, cookies.Duration(SessionMaxAge))
+	}
+	return nil
+}
+
+// RequireAdminTOTP ensures that anyone with the admin role has passed
+// two-factor authentication before they reach the admin pages. It must come
+// after GetSession and before AllowIfDevelopment, so that it applies in
+// development as well. Admins who have not set up two-factor authentication
+// are sent to /user to do so.
+func (skylb Skylab) RequireAdminTOTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(ContextUser).(User)
+		admin, _ := r.Context().Value(ContextAdmin).(User)
+		var cookieName, role string
+		switch {
+		case admin.Valid:
+			cookieName, role = AdminSessionCookieName, "admin"
+		case user.Roles[RoleAdmin] != 0:
+			cookieName, role = SessionCookieName, "user"
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		verified, err := skylb.SessionIsTOTPVerified(cookies.GetCookieValue(r, cookieName))
+		if err != nil {
+			skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !verified {
+			msgs := map[string][]string{flash.Error: {"Admins must set up two-factor authentication before they can access the admin pages"}}
+			_, _ = skylb.SetFlashMsgs(w, r, msgs)
+			http.Redirect(w, r, "/user?"+role+"=true#two-factor", http.StatusMovedPermanently)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,38 @@
+package skylab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTOTP_QRCode(t *testing.T) {
+	is := is.New(t)
+	tp := TOTP{Valid: true, Secret: "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"}
+	is.True(strings.HasPrefix(tp.ProvisioningURI("someone@u.nus.edu"), "otpauth://totp/Skylab:someone@u.nus.edu?"))
+	img, err := tp.QRCode("someone@u.nus.edu")
+	is.NoErr(err)
+	is.True(strings.HasPrefix(string(img), `<img src="data:image/png;base64,`))
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	is := is.New(t)
+	skylb := Skylab{SecretKey: "test-secret-key"}
+	secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	stored, err := skylb.encryptTOTPSecret(secret)
+	is.NoErr(err)
+	is.True(strings.HasPrefix(stored, totpSecretPrefix))
+	is.True(!strings.Contains(stored, secret))
+	decrypted, err := skylb.decryptTOTPSecret(stored)
+	is.NoErr(err)
+	is.Equal(decrypted, secret)
+
+	// Secrets saved before encryption are read as they are
+	decrypted, err = skylb.decryptTOTPSecret(secret)
+	is.NoErr(err)
+	is.Equal(decrypted, secret)
+
+	_, err = Skylab{SecretKey: "another-key"}.decryptTOTPSecret(stored)
+	is.True(err != nil)
+}
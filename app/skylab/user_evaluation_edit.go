@@ -42,6 +42,11 @@ func (skylb Skylab) UserEvaluationEdit(role string) http.HandlerFunc {
 			}
 			return
 		}
+		r, data.FormErrors = skylb.GetFormErrors(w, r)
+		if data.FormErrors != nil {
+			// Show the answers the user just posted instead of the saved ones
+			data.Evaluation.EvaluationAnswers = formx.ExtractAnswers(r.Form, data.Evaluation.EvaluationForm.Questions)
+		}
 		switch role {
 		case RoleStudent:
 		case RoleAdviser:
@@ -49,6 +54,11 @@ func (skylb Skylab) UserEvaluationEdit(role string) http.HandlerFunc {
 			data.PreviewURL = AdviserUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/preview"
 			data.UpdateURL = AdviserUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/update"
 			data.SubmitURL = AdviserUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/submit"
+		case RoleMentor:
+			data.SubmissionURL = MentorSubmission + "/" + strconv.Itoa(data.Evaluation.Evaluatee.SubmissionID)
+			data.PreviewURL = MentorUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/preview"
+			data.UpdateURL = MentorUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/update"
+			data.SubmitURL = MentorUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/submit"
 		}
 		funcs := formx.Funcs(nil, skylb.Policy)
 		r, _ = skylb.SetFlashMsgs(w, r, msgs)
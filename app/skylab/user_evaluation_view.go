@@ -58,10 +58,31 @@ func (skylb Skylab) UserEvaluationView(role string) http.HandlerFunc {
 				data.EditURL = "google.com"
 				data.SubmitURL = "google.com"
 			}
+			// The user is in the evaluatee team
+			period := data.Evaluation.EvaluationForm.Period
+			settings, reason, err := skylb.EvaluationsWithheld(data.Evaluation.Evaluatee.Team.TeamID, period.Cohort, period.Milestone)
+			if err != nil {
+				skylb.InternalServerError(w, r, err)
+				return
+			}
+			if reason != "" {
+				msgs[flash.Error] = []string{reason}
+				r, _ = skylb.SetFlashMsgs(w, r, msgs)
+				http.Redirect(w, r, StudentSubmission+"/"+strconv.Itoa(data.Evaluation.Evaluatee.SubmissionID), http.StatusSeeOther)
+				return
+			}
+			if settings.Anonymous {
+				data.Anonymous = true
+				data.Evaluation.Anonymize()
+			}
 		case RoleAdviser:
 			data.SubmissionURL = AdviserSubmission + "/" + strconv.Itoa(data.Evaluation.Evaluatee.SubmissionID)
 			data.EditURL = AdviserUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/edit"
 			data.SubmitURL = AdviserUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/submit"
+		case RoleMentor:
+			data.SubmissionURL = MentorSubmission + "/" + strconv.Itoa(data.Evaluation.Evaluatee.SubmissionID)
+			data.EditURL = MentorUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/edit"
+			data.SubmitURL = MentorUserEvaluation + "/" + strconv.Itoa(userEvaluationID) + "/submit"
 		}
 		render(data, msgs)
 	}
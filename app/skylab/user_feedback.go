@@ -0,0 +1,128 @@
+package skylab
+
+import (
+	"strconv"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// UserFeedbacks returns the team's feedback on each user it can give feedback
+// on, which are its adviser and its mentor. started is the feedback the team
+// has already started; the feedback on a user that it has not started is an
+// invalid UserFeedback with only the Evaluator, Evaluatee and Role. The team
+// must have been mapped from V_TEAMS so that the user_role_ids of its adviser
+// and mentor are known.
+func (t Team) UserFeedbacks(started []UserFeedback) []UserFeedback {
+	var feedbacks []UserFeedback
+	evaluatees := []struct {
+		role string
+		user User
+	}{
+		{RoleAdviser, t.Adviser},
+		{RoleMentor, t.Mentor},
+	}
+	for _, evaluatee := range evaluatees {
+		if !evaluatee.user.Valid {
+			continue
+		}
+		feedback := UserFeedback{Evaluator: t, Evaluatee: evaluatee.user, Role: evaluatee.role}
+		for _, startedFeedback := range started {
+			if startedFeedback.Role == evaluatee.role &&
+				startedFeedback.Evaluatee.Roles[evaluatee.role] == evaluatee.user.Roles[evaluatee.role] {
+				feedback = startedFeedback
+				break
+			}
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+	return feedbacks
+}
+
+// FeedbackFormID returns the formID of the feedback form of the cohort, which
+// is the main form of the cohort's feedback period. The error wraps
+// sql.ErrNoRows if the administrator has not created it yet.
+func (skylb Skylab) FeedbackFormID(cohort string) (int, error) {
+	var formID int
+	p, f := tables.PERIODS(), tables.FORMS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(f).
+		Join(p, p.PERIOD_ID.Eq(f.PERIOD_ID)).
+		Where(
+			p.COHORT.EqString(cohort),
+			p.STAGE.EqString(StageFeedback),
+			p.MILESTONE.EqString(MilestoneNull),
+			f.NAME.EqString(""),
+			f.SUBSECTION.EqString(""),
+		).
+		SelectRowx(func(row *sq.Row) { formID = row.Int(f.FORM_ID) }).
+		Fetch(skylb.DB)
+	return formID, erro.Wrap(err)
+}
+
+// UserFeedbacks returns the feedback on users that match the predicates,
+// ordered by the team giving the feedback and then the role of the user
+// receiving it.
+func (skylb Skylab) UserFeedbacks(predicates ...sq.Predicate) ([]UserFeedback, error) {
+	var feedbacks []UserFeedback
+	var feedback UserFeedback
+	fou, t := tables.FEEDBACK_ON_USERS(), tables.V_TEAMS()
+	ur, u := tables.USER_ROLES(), tables.USERS()
+	f, p := tables.FORMS(), tables.PERIODS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(fou).
+		Join(t, t.TEAM_ID.Eq(fou.EVALUATOR_TEAM_ID)).
+		Join(ur, ur.USER_ROLE_ID.Eq(fou.EVALUATEE_USER_ROLE_ID)).
+		Join(u, u.USER_ID.Eq(ur.USER_ID)).
+		Join(f, f.FORM_ID.Eq(fou.FEEDBACK_FORM_ID)).
+		Join(p, p.PERIOD_ID.Eq(f.PERIOD_ID)).
+		Where(predicates...).
+		OrderBy(t.TEAM_NAME, fou.EVALUATOR_TEAM_ID, ur.ROLE).
+		Selectx(func(row *sq.Row) {
+			feedback = UserFeedback{
+				Valid:            row.IntValid(fou.FEEDBACK_ID_ON_USER),
+				FeedbackIDOnUser: row.Int(fou.FEEDBACK_ID_ON_USER),
+				Evaluatee: User{
+					Valid:       row.IntValid(u.USER_ID),
+					UserID:      row.Int(u.USER_ID),
+					Displayname: row.String(u.DISPLAYNAME),
+					Email:       row.String(u.EMAIL),
+					Roles:       map[string]int{row.String(ur.ROLE): row.Int(ur.USER_ROLE_ID)},
+				},
+				Role: row.String(ur.ROLE),
+				FeedbackForm: Form{
+					Valid:      row.IntValid(f.FORM_ID),
+					FormID:     row.Int(f.FORM_ID),
+					Name:       row.String(f.NAME),
+					Subsection: row.String(f.SUBSECTION),
+					Version:    row.Int(f.VERSION),
+				},
+				Submitted:    row.Bool(fou.SUBMITTED),
+				OverrideOpen: row.Bool(fou.OVERRIDE_OPEN),
+				UpdatedAt:    row.NullTime(fou.UPDATED_AT),
+			}
+			feedback.Evaluator.RowMapper(t)(row)
+			feedback.FeedbackForm.Period.RowMapper(p)(row)
+			row.ScanInto(&feedback.FeedbackForm.Questions, f.QUESTIONS)
+			row.ScanInto(&feedback.FeedbackAnswers, fou.FEEDBACK_DATA)
+		}, func() {
+			feedbacks = append(feedbacks, feedback)
+		}).
+		Fetch(skylb.DB)
+	return feedbacks, erro.Wrap(err)
+}
+
+// UserFeedback returns the feedback on a user with the feedbackIDOnUser. The
+// error is an ErrUserFeedbackNotExist if there is no such feedback.
+func (skylb Skylab) UserFeedback(feedbackIDOnUser int) (UserFeedback, error) {
+	fou := tables.FEEDBACK_ON_USERS()
+	feedbacks, err := skylb.UserFeedbacks(fou.FEEDBACK_ID_ON_USER.EqInt(feedbackIDOnUser))
+	if err != nil {
+		return UserFeedback{}, erro.Wrap(err)
+	}
+	if len(feedbacks) == 0 {
+		return UserFeedback{}, erro.Wrap(erro.Errorf(ErrUserFeedbackNotExist, strconv.Itoa(feedbackIDOnUser)))
+	}
+	return feedbacks[0], nil
+}
@@ -0,0 +1,31 @@
+package skylab
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTeamUserFeedbacks(t *testing.T) {
+	is := is.New(t)
+	adviser := User{Valid: true, UserID: 1, Roles: map[string]int{RoleAdviser: 11}}
+	mentor := User{Valid: true, UserID: 2, Roles: map[string]int{RoleMentor: 12}}
+	team := Team{Valid: true, TeamID: 1, Adviser: adviser, Mentor: mentor}
+
+	// Feedback that has not been started yet only knows who it is for
+	is.Equal(team.UserFeedbacks(nil), []UserFeedback{
+		{Evaluator: team, Evaluatee: adviser, Role: RoleAdviser},
+		{Evaluator: team, Evaluatee: mentor, Role: RoleMentor},
+	})
+
+	// Feedback that has been started is matched by the evaluatee's user_role_id
+	started := UserFeedback{Valid: true, FeedbackIDOnUser: 5, Evaluator: team, Evaluatee: mentor, Role: RoleMentor}
+	is.Equal(team.UserFeedbacks([]UserFeedback{started}), []UserFeedback{
+		{Evaluator: team, Evaluatee: adviser, Role: RoleAdviser},
+		started,
+	})
+
+	// Teams without a mentor can only give feedback on their adviser
+	team.Mentor = User{}
+	is.Equal(len(team.UserFeedbacks(nil)), 1)
+}
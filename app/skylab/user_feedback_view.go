@@ -0,0 +1,48 @@
+package skylab
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+)
+
+// UserFeedbackView shows the feedback on a user with the feedbackIDOnUser URL
+// param.
+func (skylb Skylab) UserFeedbackView(role string) http.HandlerFunc {
+	if !Contains(Roles(), role) {
+		panic(fmt.Errorf("%s is not a valid skylab role", role))
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		skylb.Log.TraceRequest(r)
+		r = skylb.SetRoleSection(w, r, role, SectionPreserve)
+		var data UserFeedbackView
+		feedbackIDOnUser, err := urlparams.Int(r, "feedbackIDOnUser")
+		if err != nil {
+			skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		data.Feedback, err = skylb.UserFeedback(feedbackIDOnUser)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrUserFeedbackNotExist):
+				skylb.BadRequest(w, r, fmt.Sprintf("No feedback found for feedbackIDOnUser %d", feedbackIDOnUser))
+			default:
+				skylb.InternalServerError(w, r, err)
+			}
+			return
+		}
+		switch role {
+		case RoleStudent:
+			data.BackURL = StudentListFeedbacks
+			data.EditURL = StudentUserFeedback + "/" + strconv.Itoa(feedbackIDOnUser) + "/edit"
+		case RoleAdmin:
+			data.BackURL = AdminListFeedbacks + "/" + data.Feedback.Evaluator.Cohort
+		}
+		funcs := formx.Funcs(nil, skylb.Policy)
+		skylb.Render(w, r, data, funcs, "app/skylab/user_feedback_view.html", "helpers/formx/render_form_results.html")
+	}
+}
@@ -11,18 +11,30 @@ import (
 type ApplicationEdit struct {
 	ApplicantUserID int
 	Application     Application
+	StatusHistory   []ApplicationStatusChange
+	FormErrors      formx.ValidationErrors
 }
 
 type ApplicationView struct {
 	Application Application
 }
 
+type MatchmakingData struct {
+	Profile          MatchProfile
+	HasPartner       bool
+	Suggestions      []MatchSuggestion
+	IncomingRequests []MatchRequest
+	OutgoingRequests []MatchRequest
+}
+
 type FormEdit struct {
 	Title            string
 	Form             Form
 	QuestionsAnswers []formx.QuestionAnswer
 	PreviewURL       string
 	UpdateURL        string
+	ExportURL        string
+	AnalyticsURL     string
 }
 
 type FormView struct {
@@ -39,6 +51,7 @@ type SubmissionViewData struct {
 	Submission Submission
 	EditURL    string
 	SubmitURL  string
+	HistoryURL string
 }
 
 // SubmissionEditData is the data struct that targets the
@@ -48,14 +61,64 @@ type SubmissionEditData struct {
 	PeerEvaluations   []TeamEvaluation
 	AdviserEvaluation UserEvaluation
 	MentorEvaluation  UserEvaluation
-	PreviewURL        string
-	UpdateURL         string
-	SubmitURL         string
+	// EvaluationsWithheld is why the evaluations of the submission are
+	// hidden from its team, if they are
+	EvaluationsWithheld string
+	// AnonymousEvaluators hides who made the evaluations from the team
+	AnonymousEvaluators bool
+	PreviewURL          string
+	UpdateURL           string
+	SubmitURL           string
+	HistoryURL          string
+	UpdatedAt           string
+	FormErrors          formx.ValidationErrors
+}
+
+// SubmissionHistoryData is the data struct that targets the
+// "app/skylab/submission_history.html" template
+type SubmissionHistoryData struct {
+	Submission Submission
+	Revisions  []SubmissionRevision
+	ViewURL    string
+	RestoreURL string
+	UpdatedAt  string // see UpdatedAtValue
+}
+
+// ApplicationReviewListData is the data struct that targets the
+// "app/skylab/application_reviews.html" template
+type ApplicationReviewListData struct {
+	Reviews   []ApplicationReview
+	ReviewURL string
+}
+
+// ApplicationReviewEditData is the data struct that targets the
+// "app/skylab/application_review_edit.html" template
+type ApplicationReviewEditData struct {
+	Application Application
+	Review      ApplicationReview
+	ReviewForm  Form
+	ListURL     string
+	UpdateURL   string
+	SubmitURL   string
+	FormErrors  formx.ValidationErrors
+}
+
+// AnswersConflictData is the data struct that targets the
+// "app/skylab/answers_conflict.html" template
+type AnswersConflictData struct {
+	Title     string
+	UpdateURL string
+	EditURL   string
+	UpdatedAt string
+	Answers   formx.Answers
+	Conflicts []formx.Conflict
 }
 
 type TeamView struct {
-	Team        Team
-	UserBaseURL string
+	Team          Team
+	Meetings      []TeamMeeting
+	StatusHistory []TeamStatusChange
+	UserBaseURL   string
 }
 
 type TeamEdit struct {
@@ -68,6 +131,7 @@ type TeamEvaluationView struct {
 	SubmitURL      string
 	SubmissionURL  string
 	EditURL        string
+	Anonymous      bool
 }
 
 type TeamEvaluationEdit struct {
@@ -76,6 +140,8 @@ type TeamEvaluationEdit struct {
 	SubmitURL      string
 	SubmissionURL  string
 	PreviewURL     string
+	UpdatedAt      string
+	FormErrors     formx.ValidationErrors
 }
 
 type UserView struct {
@@ -124,6 +190,7 @@ type UserEvaluationEdit struct {
 	SubmitURL     string
 	PreviewURL    string
 	UpdateURL     string
+	FormErrors    formx.ValidationErrors
 }
 
 type UserEvaluationView struct {
@@ -131,4 +198,11 @@ type UserEvaluationView struct {
 	SubmissionURL string
 	SubmitURL     string
 	EditURL       string
+	Anonymous     bool
+}
+
+type UserFeedbackView struct {
+	Feedback UserFeedback
+	BackURL  string
+	EditURL  string
 }
@@ -0,0 +1,107 @@
+package skylab
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// WebhookEvent consts correspond to the events present inside the
+// webhook_event_enum table in the database. The events themselves are
+// recorded into the webhook_events table by triggers (see
+// sql/triggers/webhooks.sql).
+const (
+	WebhookEventApplicationAccepted     = "application.accepted"
+	WebhookEventSubmissionSubmitted     = "submission.submitted"
+	WebhookEventTeamEvaluationSubmitted = "team_evaluation.submitted"
+	WebhookEventUserEvaluationSubmitted = "user_evaluation.submitted"
+	WebhookEventTeamStatusChanged       = "team.status_changed"
+
+	// WebhookEventPing is only ever sent by the admin's "Send Test" button,
+	// it is never recorded in the database.
+	WebhookEventPing = "ping"
+)
+
+func WebhookEvents() []string {
+	return []string{
+		WebhookEventApplicationAccepted,
+		WebhookEventSubmissionSubmitted,
+		WebhookEventTeamEvaluationSubmitted,
+		WebhookEventUserEvaluationSubmitted,
+		WebhookEventTeamStatusChanged,
+	}
+}
+
+// WebhookDeliveryStatus consts correspond to the statuses present inside the
+// webhook_delivery_status_enum table in the database
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// Webhook is an admin-configured endpoint that lifecycle events are POSTed
+// to.
+type Webhook struct {
+	Valid       bool
+	WebhookID   int
+	URL         string
+	Secret      string
+	Description string
+	Events      []string // an empty Events means the webhook subscribes to every event
+	Enabled     bool
+	CreatedAt   time.Time
+}
+
+func (wh *Webhook) RowMapper(tbl tables.TABLE_WEBHOOKS) func(*sq.Row) {
+	return func(row *sq.Row) {
+		*wh = Webhook{
+			Valid:       row.IntValid(tbl.WEBHOOK_ID),
+			WebhookID:   row.Int(tbl.WEBHOOK_ID),
+			URL:         row.String(tbl.URL),
+			Secret:      row.String(tbl.SECRET),
+			Description: row.String(tbl.DESCRIPTION),
+			Enabled:     row.Bool(tbl.ENABLED),
+			CreatedAt:   row.Time(tbl.CREATED_AT),
+		}
+		row.ScanArray(&wh.Events, tbl.EVENTS)
+	}
+}
+
+// Subscribes reports whether the webhook wants to receive the event.
+func (wh Webhook) Subscribes(event string) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+	return Contains(wh.Events, event)
+}
+
+// WebhookPayload is the JSON body POSTed to every webhook.
+type WebhookPayload struct {
+	EventID   int             `json:"event_id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      interface{}     `json:"data"`              // the Application, Submission, TeamEvaluation, UserEvaluation or Team
+	Changes   json.RawMessage `json:"changes,omitempty"` // e.g. {"old_status": "ok", "new_status": "good"}
+}
+
+// WebhookDelivery is a single entry in the delivery log of a webhook.
+type WebhookDelivery struct {
+	Valid             bool
+	WebhookDeliveryID int
+	WebhookID         int
+	WebhookEventID    int
+	Event             string
+	Status            string
+	Attempts          int
+	NextAttemptAt     time.Time
+	ResponseStatus    sql.NullInt64
+	ResponseBody      string
+	Error             string
+	Payload           json.RawMessage
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
@@ -1 +1,296 @@
 package students
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/formutil"
+	"github.com/bokwoon95/nusskylabx/helpers/formx"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+// studentTeam returns the team of the student. The error wraps sql.ErrNoRows
+// if the student has no team.
+func (stu Students) studentTeam(user skylab.User) (skylab.Team, error) {
+	var team skylab.Team
+	t := tables.V_TEAMS()
+	err := sq.WithDefaultLog(sq.Lstats).
+		From(t).
+		Where(sq.Int(user.Roles[skylab.RoleStudent]).In(sq.Fields{t.STUDENT1_USER_ROLE_ID, t.STUDENT2_USER_ROLE_ID})).
+		SelectRowx((&team).RowMapper(t)).
+		Fetch(stu.skylb.DB)
+	return team, erro.Wrap(err)
+}
+
+// UserFeedbackCreate creates the user's team's feedback on the posted
+// evaluateeUserRoleID, or finds the one the team had already started, and sets
+// its feedbackIDOnUser in the URL params. Teams can only give feedback on their
+// own adviser and mentor.
+func (stu Students) UserFeedbackCreate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stu.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		evaluateeUserRoleID, err := formutil.Int(r, "evaluateeUserRoleID")
+		if err != nil {
+			stu.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		team, err := stu.studentTeam(user)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				stu.StudentNoTeam(w, r)
+			default:
+				stu.skylb.InternalServerError(w, r, err)
+			}
+			return
+		}
+		var isEvaluatee bool
+		for _, feedback := range team.UserFeedbacks(nil) {
+			if feedback.Evaluatee.Roles[feedback.Role] == evaluateeUserRoleID {
+				isEvaluatee = true
+				break
+			}
+		}
+		if !isEvaluatee {
+			stu.skylb.NotAuthorized(w, r)
+			return
+		}
+		formID, err := stu.skylb.FeedbackFormID(team.Cohort)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				stu.skylb.BadRequest(w, r, "Unfortunately it seems like the administrator has not created the feedback form yet.")
+			default:
+				stu.skylb.InternalServerError(w, r, err)
+			}
+			return
+		}
+
+		// Insert or select the feedback, returning the feedbackIDOnUser
+		fou := tables.FEEDBACK_ON_USERS()
+		var feedbackIDOnUser int
+		err = sq.WithDefaultLog(sq.Lverbose).
+			InsertInto(fou).
+			Columns(fou.EVALUATOR_TEAM_ID, fou.EVALUATEE_USER_ROLE_ID, fou.FEEDBACK_FORM_ID).
+			Values(team.TeamID, evaluateeUserRoleID, formID).
+			OnConflict(fou.EVALUATOR_TEAM_ID, fou.EVALUATEE_USER_ROLE_ID).DoNothing().
+			ReturningRowx(func(row *sq.Row) { feedbackIDOnUser = row.Int(fou.FEEDBACK_ID_ON_USER) }).
+			Fetch(stu.skylb.DB)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			stu.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			err = sq.WithDefaultLog(sq.Lverbose).
+				From(fou).
+				Where(
+					fou.EVALUATOR_TEAM_ID.EqInt(team.TeamID),
+					fou.EVALUATEE_USER_ROLE_ID.EqInt(evaluateeUserRoleID),
+				).
+				SelectRowx(func(row *sq.Row) { feedbackIDOnUser = row.Int(fou.FEEDBACK_ID_ON_USER) }).
+				Fetch(stu.skylb.DB)
+			if err != nil {
+				stu.skylb.InternalServerError(w, r, err)
+				return
+			}
+		}
+
+		r = urlparams.SetInt(r, "feedbackIDOnUser", feedbackIDOnUser)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CanEditUserFeedback only lets the students of the team giving the feedback
+// on a user view and edit it.
+func (stu Students) CanEditUserFeedback(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stu.skylb.Log.TraceRequest(r)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		feedbackIDOnUser, err := urlparams.Int(r, "feedbackIDOnUser")
+		if err != nil {
+			stu.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		t, fou := tables.V_TEAMS(), tables.FEEDBACK_ON_USERS()
+		rowsAffected, err := sq.WithDefaultLog(sq.Lstats).
+			SelectOne().
+			From(fou).
+			Join(t, t.TEAM_ID.Eq(fou.EVALUATOR_TEAM_ID)).
+			Where(
+				fou.FEEDBACK_ID_ON_USER.EqInt(feedbackIDOnUser),
+				sq.Int(user.UserID).In(sq.Fields{
+					t.STUDENT1_USER_ID,
+					t.STUDENT2_USER_ID,
+				}),
+			).
+			Exec(stu.skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			stu.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if rowsAffected == 0 {
+			stu.skylb.NotAuthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserFeedbackEdit shows the form for editing the feedback on a user with the
+// feedbackIDOnUser URL param.
+func (stu Students) UserFeedbackEdit(w http.ResponseWriter, r *http.Request) {
+	stu.skylb.Log.TraceRequest(r)
+	r = stu.skylb.SetRoleSection(w, r, skylab.RoleStudent, skylab.StudentListFeedbacks)
+	headers.DoNotCache(w)
+	type Data struct {
+		Feedback   skylab.UserFeedback
+		PreviewURL string
+		UpdateURL  string
+		SubmitURL  string
+		FormErrors formx.ValidationErrors
+	}
+	var data Data
+	feedbackIDOnUser, err := urlparams.Int(r, "feedbackIDOnUser")
+	if err != nil {
+		stu.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	data.Feedback, err = stu.skylb.UserFeedback(feedbackIDOnUser)
+	if err != nil {
+		switch {
+		case errors.Is(err, skylab.ErrUserFeedbackNotExist):
+			stu.skylb.BadRequest(w, r, fmt.Sprintf("No feedback found for feedbackIDOnUser %d", feedbackIDOnUser))
+		default:
+			stu.skylb.InternalServerError(w, r, err)
+		}
+		return
+	}
+	r, data.FormErrors = stu.skylb.GetFormErrors(w, r)
+	if data.FormErrors != nil {
+		// Show the answers the user just posted instead of the saved ones
+		data.Feedback.FeedbackAnswers = formx.ExtractAnswers(r.Form, data.Feedback.FeedbackForm.Questions)
+	}
+	feedbackURL := skylab.StudentUserFeedback + "/" + strconv.Itoa(feedbackIDOnUser)
+	data.PreviewURL = feedbackURL + "/preview"
+	data.UpdateURL = feedbackURL + "/update"
+	data.SubmitURL = feedbackURL + "/submit"
+	funcs := formx.Funcs(nil, stu.skylb.Policy)
+	stu.skylb.Render(w, r, data, funcs, "app/students/feedback_user.html", "helpers/formx/render_form.html")
+}
+
+// UserFeedbackUpdate saves the posted answers to the feedback on a user with
+// the feedbackIDOnUser URL param, re-rendering UserFeedbackEdit if they are
+// invalid.
+func (stu Students) UserFeedbackUpdate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stu.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		feedbackIDOnUser, err := urlparams.Int(r, "feedbackIDOnUser")
+		if err != nil {
+			stu.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		var questions formx.Questions
+		var submitted bool
+		fou, f := tables.FEEDBACK_ON_USERS(), tables.FORMS()
+		err = sq.WithDefaultLog(sq.Lstats).
+			From(fou).
+			Join(f, f.FORM_ID.Eq(fou.FEEDBACK_FORM_ID)).
+			Where(fou.FEEDBACK_ID_ON_USER.EqInt(feedbackIDOnUser)).
+			SelectRowx(func(row *sq.Row) {
+				row.ScanInto(&questions, f.QUESTIONS)
+				submitted = row.Bool(fou.SUBMITTED)
+			}).
+			Fetch(stu.skylb.DB)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				stu.skylb.BadRequest(w, r, fmt.Sprintf("No such feedback {feedbackIDOnUser:%d}", feedbackIDOnUser))
+			default:
+				stu.skylb.InternalServerError(w, r, err)
+			}
+			return
+		}
+		_ = formutil.ParseForm(r)
+		answers := formx.ExtractAnswers(r.Form, questions)
+		err = validateAnswers(questions, answers, submitted)
+		if errs, ok := err.(formx.ValidationErrors); ok {
+			stu.UserFeedbackEdit(w, skylab.SetFormErrors(r, errs))
+			return
+		}
+		// No answers at all (as opposed to blank answers) means there is
+		// nothing to update
+		if answers.IsEmpty() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		_, err = sq.WithDefaultLog(sq.Lstats).
+			Update(fou).
+			Set(fou.FEEDBACK_DATA.Set(answers)).
+			Where(fou.FEEDBACK_ID_ON_USER.EqInt(feedbackIDOnUser)).
+			Exec(stu.skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			msgs[flash.Error] = []string{erro.Wrap(err).Error()}
+		} else {
+			msgs[flash.Success] = []string{"Updated!"}
+		}
+		r, _ = stu.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserFeedbackSubmit submits the feedback on a user with the feedbackIDOnUser
+// URL param, re-rendering UserFeedbackEdit if its answers are incomplete.
+func (stu Students) UserFeedbackSubmit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stu.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		feedbackIDOnUser, err := urlparams.Int(r, "feedbackIDOnUser")
+		if err != nil {
+			stu.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		var questions formx.Questions
+		var answers formx.Answers
+		fou, f := tables.FEEDBACK_ON_USERS(), tables.FORMS()
+		err = sq.WithDefaultLog(sq.Lstats).
+			From(fou).
+			Join(f, f.FORM_ID.Eq(fou.FEEDBACK_FORM_ID)).
+			Where(fou.FEEDBACK_ID_ON_USER.EqInt(feedbackIDOnUser)).
+			SelectRowx(func(row *sq.Row) {
+				row.ScanInto(&questions, f.QUESTIONS)
+				row.ScanInto(&answers, fou.FEEDBACK_DATA)
+			}).
+			Fetch(stu.skylb.DB)
+		if err != nil {
+			stu.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if errs := formx.Validate(questions, answers); errs != nil {
+			stu.UserFeedbackEdit(w, skylab.SetFormErrors(r, errs))
+			return
+		}
+		_, err = sq.WithDefaultLog(sq.Lstats).
+			Update(fou).
+			Set(fou.SUBMITTED.SetBool(true)).
+			Where(fou.FEEDBACK_ID_ON_USER.EqInt(feedbackIDOnUser)).
+			Exec(stu.skylb.DB, sq.ErowsAffected)
+		if err != nil {
+			msgs[flash.Error] = []string{erro.Wrap(err).Error()}
+		} else {
+			msgs[flash.Success] = []string{"Submitted!"}
+		}
+		r, _ = stu.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,10 +1,13 @@
 package students
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/bokwoon95/nusskylabx/app/skylab"
 	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/tables"
 )
 
 func (stu Students) ListFeedbacks(w http.ResponseWriter, r *http.Request) {
@@ -14,8 +17,26 @@ func (stu Students) ListFeedbacks(w http.ResponseWriter, r *http.Request) {
 
 	type Data struct {
 		TeamFeedbacks []skylab.TeamFeedback
-		UserFeedbacks []skylab.UserFeedback
+		UserFeedbacks []skylab.UserFeedback // one for the team's adviser and mentor each, started or not
 	}
 	var data Data
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	team, err := stu.studentTeam(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			stu.StudentNoTeam(w, r)
+		default:
+			stu.skylb.InternalServerError(w, r, err)
+		}
+		return
+	}
+	fou := tables.FEEDBACK_ON_USERS()
+	started, err := stu.skylb.UserFeedbacks(fou.EVALUATOR_TEAM_ID.EqInt(team.TeamID))
+	if err != nil {
+		stu.skylb.InternalServerError(w, r, err)
+		return
+	}
+	data.UserFeedbacks = team.UserFeedbacks(started)
 	stu.skylb.Render(w, r, data, nil, "app/students/list_feedbacks.html")
 }
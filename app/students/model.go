@@ -1,6 +1,8 @@
 package students
 
 import (
+	"time"
+
 	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
 	"github.com/bokwoon95/nusskylabx/tables"
@@ -18,21 +20,56 @@ func answersPresent(answers formx.Answers) bool {
 	return false
 }
 
-func (stu Students) UpdateSubmissionAnswers(submissionID int, form map[string][]string) error {
+// validateAnswers validates answers as a draft, or fully if they belong to
+// something that has already been submitted (so that updating it cannot make
+// it incomplete). It returns the formx.ValidationErrors as an error if there
+// are any.
+func validateAnswers(questions formx.Questions, answers formx.Answers, submitted bool) error {
+	validate := formx.ValidateDraft
+	if submitted {
+		validate = formx.Validate
+	}
+	if errs := validate(questions, answers); errs != nil {
+		return errs
+	}
+	return nil
+}
+
+// UpdateSubmissionAnswers saves the answers in form to the submission. If the
+// answers are invalid, the formx.ValidationErrors are returned and nothing is
+// saved. If another member of the team saved different answers since the form
+// was loaded, a skylab.AnswersConflict is returned and nothing is saved.
+// Changed answers are recorded as a new revision saved by the user.
+func (stu Students) UpdateSubmissionAnswers(submissionID, userID int, form map[string][]string) error {
 	var questions formx.Questions
-	var answers formx.Answers
+	var answers, saved formx.Answers
+	var submitted bool
+	var updatedAt time.Time
 	var err error
 	s, f := tables.SUBMISSIONS(), tables.FORMS()
 	err = sq.WithDefaultLog(sq.Lverbose).
 		From(s).
 		Join(f, f.FORM_ID.Eq(s.SUBMISSION_FORM_ID)).
 		Where(s.SUBMISSION_ID.EqInt(submissionID)).
-		SelectRowx(func(row *sq.Row) { row.ScanInto(&questions, f.QUESTIONS) }).
+		SelectRowx(func(row *sq.Row) {
+			row.ScanInto(&questions, f.QUESTIONS)
+			row.ScanInto(&saved, s.SUBMISSION_DATA)
+			submitted = row.Bool(s.SUBMITTED)
+			updatedAt = row.Time(s.UPDATED_AT)
+		}).
 		Fetch(stu.skylb.DB)
 	if err != nil {
 		return erro.Wrap(err)
 	}
 	answers = formx.ExtractAnswers(form, questions)
+	answers, err = skylab.CheckAnswersConflict(form, questions, answers, saved, updatedAt)
+	if err != nil {
+		return err
+	}
+	err = validateAnswers(questions, answers, submitted)
+	if err != nil {
+		return err
+	}
 	// If no answers are present at all (which is different from answers having
 	// blank values), do not proceed with the data update as that is not what
 	// we want under any circumstance. If a user wishes to clear out an answer,
@@ -40,29 +77,73 @@ func (stu Students) UpdateSubmissionAnswers(submissionID int, form map[string][]
 	if !answersPresent(answers) {
 		return nil
 	}
-	_, err = sq.WithDefaultLog(sq.Lverbose).
-		Update(s).
-		Set(s.SUBMISSION_DATA.Set(answers)).
+	ok, err := stu.skylb.SaveSubmissionAnswers(submissionID, userID, answers, updatedAt)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if !ok {
+		// Someone else saved in between, check the answers against theirs
+		return stu.UpdateSubmissionAnswers(submissionID, userID, form)
+	}
+	return nil
+}
+
+// ValidateSubmission checks the saved answers of a submission against the
+// validation rules of its form.
+func (stu Students) ValidateSubmission(submissionID int) (formx.ValidationErrors, error) {
+	var questions formx.Questions
+	var answers formx.Answers
+	s, f := tables.SUBMISSIONS(), tables.FORMS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(s).
+		Join(f, f.FORM_ID.Eq(s.SUBMISSION_FORM_ID)).
 		Where(s.SUBMISSION_ID.EqInt(submissionID)).
-		Exec(stu.skylb.DB, sq.ErowsAffected)
-	return erro.Wrap(err)
+		SelectRowx(func(row *sq.Row) {
+			row.ScanInto(&questions, f.QUESTIONS)
+			row.ScanInto(&answers, s.SUBMISSION_DATA)
+		}).
+		Fetch(stu.skylb.DB)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	return formx.Validate(questions, answers), nil
 }
 
+// UpdateEvaluationAnswers saves the answers in form to the team evaluation.
+// If the answers are invalid, the formx.ValidationErrors are returned and
+// nothing is saved. If another member of the team saved different answers
+// since the form was loaded, a skylab.AnswersConflict is returned and nothing
+// is saved.
 func (stu Students) UpdateEvaluationAnswers(teamEvaluationID int, form map[string][]string) error {
 	var questions formx.Questions
-	var answers formx.Answers
+	var answers, saved formx.Answers
+	var submitted bool
+	var updatedAt time.Time
 	var err error
 	te, f := tables.TEAM_EVALUATIONS(), tables.FORMS()
 	err = sq.WithDefaultLog(sq.Lverbose).
 		From(te).
 		Join(f, f.FORM_ID.Eq(te.EVALUATION_FORM_ID)).
 		Where(te.TEAM_EVALUATION_ID.EqInt(teamEvaluationID)).
-		SelectRowx(func(row *sq.Row) { row.ScanInto(&questions, f.QUESTIONS) }).
+		SelectRowx(func(row *sq.Row) {
+			row.ScanInto(&questions, f.QUESTIONS)
+			row.ScanInto(&saved, te.EVALUATION_DATA)
+			submitted = row.Bool(te.SUBMITTED)
+			updatedAt = row.Time(te.UPDATED_AT)
+		}).
 		Fetch(stu.skylb.DB)
 	if err != nil {
 		return erro.Wrap(err)
 	}
 	answers = formx.ExtractAnswers(form, questions)
+	answers, err = skylab.CheckAnswersConflict(form, questions, answers, saved, updatedAt)
+	if err != nil {
+		return err
+	}
+	err = validateAnswers(questions, answers, submitted)
+	if err != nil {
+		return err
+	}
 	// If no answers are present at all (which is different from answers having
 	// blank values), do not proceed with the data update as that is not what
 	// we want under any circumstance. If a user wishes to clear out an answer,
@@ -70,12 +151,43 @@ func (stu Students) UpdateEvaluationAnswers(teamEvaluationID int, form map[strin
 	if !answersPresent(answers) {
 		return nil
 	}
-	_, err = sq.WithDefaultLog(sq.Lstats).
+	rowsAffected, err := sq.WithDefaultLog(sq.Lstats).
 		Update(te).
 		Set(te.EVALUATION_DATA.Set(answers)).
-		Where(te.TEAM_EVALUATION_ID.EqInt(teamEvaluationID)).
+		Where(
+			te.TEAM_EVALUATION_ID.EqInt(teamEvaluationID),
+			te.UPDATED_AT.EqTime(updatedAt),
+		).
 		Exec(stu.skylb.DB, sq.ErowsAffected)
-	return erro.Wrap(err)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	if rowsAffected == 0 {
+		// Someone else saved in between, check the answers against theirs
+		return stu.UpdateEvaluationAnswers(teamEvaluationID, form)
+	}
+	return nil
+}
+
+// ValidateEvaluation checks the saved answers of a team evaluation against
+// the validation rules of its form.
+func (stu Students) ValidateEvaluation(teamEvaluationID int) (formx.ValidationErrors, error) {
+	var questions formx.Questions
+	var answers formx.Answers
+	te, f := tables.TEAM_EVALUATIONS(), tables.FORMS()
+	err := sq.WithDefaultLog(sq.Lverbose).
+		From(te).
+		Join(f, f.FORM_ID.Eq(te.EVALUATION_FORM_ID)).
+		Where(te.TEAM_EVALUATION_ID.EqInt(teamEvaluationID)).
+		SelectRowx(func(row *sq.Row) {
+			row.ScanInto(&questions, f.QUESTIONS)
+			row.ScanInto(&answers, te.EVALUATION_DATA)
+		}).
+		Fetch(stu.skylb.DB)
+	if err != nil {
+		return nil, erro.Wrap(err)
+	}
+	return formx.Validate(questions, answers), nil
 }
 
 func (stu Students) UpsertEvaluationAnswers(user skylab.User, milestone string, evaluateeSubmissionID int, form map[string][]string) error {
@@ -111,6 +223,9 @@ func (stu Students) UpsertEvaluationAnswers(user skylab.User, milestone string,
 		return erro.Wrap(err)
 	}
 	answers := formx.ExtractAnswers(form, questions)
+	if errs := formx.ValidateDraft(questions, answers); errs != nil {
+		return errs
+	}
 	// If no answers are present at all (which is different from answers having
 	// blank values), do not proceed with the data update as that is not what
 	// we want under any circumstance. If a user wishes to clear out an answer,
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	sq "github.com/bokwoon95/go-structured-query/postgres"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
@@ -58,6 +60,7 @@ func (stu Students) SubmissionEdit(w http.ResponseWriter, r *http.Request) {
 	data.PreviewURL = skylab.StudentSubmission + "/" + strconv.Itoa(submissionID) + "/preview"
 	data.UpdateURL = skylab.StudentSubmission + "/" + strconv.Itoa(submissionID) + "/update"
 	data.SubmitURL = skylab.StudentSubmission + "/" + strconv.Itoa(submissionID) + "/submit"
+	data.UpdatedAt = skylab.UpdatedAtValue(data.Submission.UpdatedAt)
 
 	// Team evaluations
 	te := tables.V_TEAM_EVALUATIONS()
@@ -215,7 +218,18 @@ func (stu Students) SubmissionUpdate(next http.Handler) http.Handler {
 			return
 		}
 		_ = formutil.ParseForm(r)
-		err = stu.UpdateSubmissionAnswers(submissionID, r.Form)
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		err = stu.UpdateSubmissionAnswers(submissionID, user.UserID, r.Form)
+		if errs, ok := err.(formx.ValidationErrors); ok {
+			stu.skylb.SubmissionEdit(skylab.RoleStudent)(w, skylab.SetFormErrors(r, errs))
+			return
+		}
+		if conflict, ok := err.(skylab.AnswersConflict); ok {
+			r = stu.skylb.SetRoleSection(w, r, skylab.RoleStudent, stu.getSectionFromSubmissionID(submissionID))
+			editURL := skylab.StudentSubmission + "/" + strconv.Itoa(submissionID) + "/edit"
+			stu.skylb.AnswersConflictPage(w, r, conflict, "Submission", r.URL.Path, editURL)
+			return
+		}
 		if err != nil {
 			msgs[flash.Error] = []string{err.Error()}
 			goto Redirect
@@ -236,6 +250,15 @@ func (stu Students) SubmissionSubmit(next http.Handler) http.Handler {
 			stu.skylb.InternalServerError(w, r, err)
 			return
 		}
+		errs, err := stu.ValidateSubmission(submissionID)
+		if err != nil {
+			stu.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if errs != nil {
+			stu.skylb.SubmissionEdit(skylab.RoleStudent)(w, skylab.SetFormErrors(r, errs))
+			return
+		}
 		s := tables.SUBMISSIONS()
 		_, err = sq.WithDefaultLog(sq.Lverbose).
 			Update(s).
@@ -252,6 +275,50 @@ func (stu Students) SubmissionSubmit(next http.Handler) http.Handler {
 	})
 }
 
+// SubmissionRestore restores the answers of a submission to one of its earlier
+// revisions, see skylab.Skylab.RestoreSubmissionRevision.
+func (stu Students) SubmissionRestore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stu.skylb.Log.TraceRequest(r)
+		msgs := make(map[string][]string)
+		submissionID, err := urlparams.Int(r, "submissionID")
+		if err != nil {
+			stu.skylb.BadRequest(w, r, err.Error())
+			return
+		}
+		_ = formutil.ParseForm(r)
+		revision, err := strconv.Atoi(r.FormValue("revision"))
+		if err != nil {
+			stu.skylb.BadRequest(w, r, fmt.Sprintf("Invalid revision %q", r.FormValue("revision")))
+			return
+		}
+		updatedAt, err := time.Parse(time.RFC3339Nano, r.FormValue(skylab.UpdatedAtField))
+		if err != nil {
+			stu.skylb.BadRequest(w, r, fmt.Sprintf("Invalid %s %q", skylab.UpdatedAtField, r.FormValue(skylab.UpdatedAtField)))
+			return
+		}
+		user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+		err = stu.skylb.RestoreSubmissionRevision(submissionID, revision, user.UserID, updatedAt)
+		if errs, ok := err.(formx.ValidationErrors); ok {
+			msgs[flash.Error] = []string{fmt.Sprintf("Revision %d cannot be restored because its answers are not valid for the submitted form", revision)}
+			var names []string
+			for name := range errs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				msgs[flash.Error] = append(msgs[flash.Error], name+": "+errs[name])
+			}
+		} else if err != nil {
+			msgs[flash.Error] = []string{err.Error()}
+		} else {
+			msgs[flash.Success] = []string{fmt.Sprintf("Restored revision %d!", revision)}
+		}
+		r, _ = stu.skylb.SetFlashMsgs(w, r, msgs)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (stu Students) CanEditSubmission(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		stu.skylb.Log.TraceRequest(r)
@@ -14,7 +14,8 @@ func (stu Students) Team(w http.ResponseWriter, r *http.Request) {
 	stu.skylb.Log.TraceRequest(r)
 	r = stu.skylb.SetRoleSection(w, r, skylab.RoleStudent, skylab.StudentTeam)
 	type Data struct {
-		Team skylab.Team
+		Team     skylab.Team
+		Meetings []skylab.TeamMeeting
 	}
 	var data Data
 	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
@@ -22,7 +23,7 @@ func (stu Students) Team(w http.ResponseWriter, r *http.Request) {
 	t := tables.V_TEAMS()
 	err := sq.WithDefaultLog(sq.Lverbose).
 		From(t).
-		Where(sq.Int(studentUserRoleID).In(sq.Fields{t.STUDENT1_USER_ROLE_ID, t.STUDENT2_USER_ID})).
+		Where(sq.Int(studentUserRoleID).In(sq.Fields{t.STUDENT1_USER_ROLE_ID, t.STUDENT2_USER_ROLE_ID})).
 		SelectRowx((&data.Team).RowMapper(t)).
 		Fetch(stu.skylb.DB)
 	if err != nil {
@@ -34,5 +35,11 @@ func (stu Students) Team(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	stu.skylb.Render(w, r, data, nil, "app/students/team.html")
+	tm := tables.TEAM_MEETINGS()
+	data.Meetings, err = stu.skylb.TeamMeetings(tm.TEAM_ID.EqInt(data.Team.TeamID))
+	if err != nil {
+		stu.skylb.InternalServerError(w, r, err)
+		return
+	}
+	stu.skylb.Render(w, r, data, nil, "app/students/team.html", "app/skylab/team_meetings.html")
 }
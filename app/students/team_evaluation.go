@@ -43,10 +43,16 @@ func (stu Students) TeamEvaluationEdit(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	r, data.FormErrors = stu.skylb.GetFormErrors(w, r)
+	if data.FormErrors != nil {
+		// Show the answers the user just posted instead of the saved ones
+		data.TeamEvaluation.EvaluationAnswers = formx.ExtractAnswers(r.Form, data.TeamEvaluation.EvaluationForm.Questions)
+	}
 	data.SubmissionURL = skylab.StudentSubmission + "/" + strconv.Itoa(data.TeamEvaluation.Evaluatee.SubmissionID)
 	data.PreviewURL = skylab.StudentTeamEvaluation + "/" + strconv.Itoa(teamEvaluationID) + "/preview"
 	data.UpdateURL = skylab.StudentTeamEvaluation + "/" + strconv.Itoa(teamEvaluationID) + "/update"
 	data.SubmitURL = skylab.StudentTeamEvaluation + "/" + strconv.Itoa(teamEvaluationID) + "/submit"
+	data.UpdatedAt = skylab.UpdatedAtValue(data.TeamEvaluation.UpdatedAt)
 	funcs := formx.Funcs(nil, stu.skylb.Policy)
 	stu.skylb.Render(w, r, data, funcs,
 		"app/skylab/team_evaluation_edit.html",
@@ -317,6 +323,16 @@ func (stu Students) TeamEvaluationUpdate(next http.Handler) http.Handler {
 		}
 		_ = formutil.ParseForm(r)
 		err = stu.UpdateEvaluationAnswers(teamEvaluationID, r.Form)
+		if errs, ok := err.(formx.ValidationErrors); ok {
+			stu.TeamEvaluationEdit(w, skylab.SetFormErrors(r, errs))
+			return
+		}
+		if conflict, ok := err.(skylab.AnswersConflict); ok {
+			r = stu.skylb.SetRoleSection(w, r, skylab.RoleStudent, skylab.SectionPreserve)
+			editURL := skylab.StudentTeamEvaluation + "/" + strconv.Itoa(teamEvaluationID) + "/edit"
+			stu.skylb.AnswersConflictPage(w, r, conflict, "Team Evaluation", r.URL.Path, editURL)
+			return
+		}
 		if err != nil {
 			msgs[flash.Error] = []string{erro.Wrap(err).Error()}
 		} else {
@@ -336,6 +352,15 @@ func (stu Students) TeamEvaluationSubmit(next http.Handler) http.Handler {
 			stu.skylb.InternalServerError(w, r, err)
 			return
 		}
+		errs, err := stu.ValidateEvaluation(teamEvaluationID)
+		if err != nil {
+			stu.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if errs != nil {
+			stu.TeamEvaluationEdit(w, skylab.SetFormErrors(r, errs))
+			return
+		}
 		te := tables.TEAM_EVALUATIONS()
 		_, err = sq.WithDefaultLog(sq.Lstats).
 			Update(te).
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/cookies"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"github.com/bokwoon95/nusskylabx/helpers/ratelimit"
+)
+
+// LoginTOTP asks a user whose session is pending two-factor authentication
+// for their code.
+func (ap App) LoginTOTP(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	headers.DoNotCache(w)
+	type Data struct {
+		User skylab.User
+	}
+	var data Data
+	var err error
+	data.User, err = ap.skylb.GetPendingTOTPUser(cookies.GetCookieValue(r, skylab.SessionCookieName))
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if !data.User.Valid {
+		http.Redirect(w, r, "/login-page", http.StatusMovedPermanently)
+		return
+	}
+	ap.skylb.Render(w, r, data, nil, "app/login_totp.html")
+}
+
+// LoginTOTPVerify checks the code entered for a pending session. If it is
+// correct the session is marked as verified and the user is injected into the
+// context, so that it can be followed by RedirectUserrole. Codes entered for
+// the user are limited by skylab.RateLimitTOTP, after which the pending
+// session is revoked.
+func (ap App) LoginTOTPVerify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ap.skylb.Log.TraceRequest(r)
+		sessionID := cookies.GetCookieValue(r, skylab.SessionCookieName)
+		pendingUser, err := ap.skylb.GetPendingTOTPUser(sessionID)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !pendingUser.Valid {
+			http.Redirect(w, r, "/login-page", http.StatusMovedPermanently)
+			return
+		}
+		key := "totp:user:" + strconv.Itoa(pendingUser.UserID)
+		res, err := ratelimit.Check(r.Context(), ap.skylb.RateLimitStore, key, skylab.RateLimitTOTP)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !res.Allowed {
+			err = ap.skylb.RevokeSessionCookie(w, r, skylab.SessionCookieName)
+			if err != nil {
+				ap.skylb.InternalServerError(w, r, err)
+				return
+			}
+			msgs := map[string][]string{flash.Error: {"Too many incorrect codes, please log in again later"}}
+			_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+			http.Redirect(w, r, "/login-page", http.StatusMovedPermanently)
+			return
+		}
+		ok, err := ap.skylb.VerifyTOTP(pendingUser.UserID, r.FormValue("code"))
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			msgs := map[string][]string{flash.Error: {"Incorrect code, please try again"}}
+			_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+			http.Redirect(w, r, "/login/totp", http.StatusMovedPermanently)
+			return
+		}
+		err = ap.skylb.SetSessionTOTPVerified(w, sessionID)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		user, err := ap.skylb.GetUserFromSessionID(sessionID)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), skylab.ContextUser, user)))
+	})
+}
+
+// totpRedirect redirects back to the two-factor authentication section of
+// the /user page with a flash message.
+func (ap App) totpRedirect(w http.ResponseWriter, r *http.Request, role, flashType, msg string) {
+	_, _ = ap.skylb.SetFlashMsgs(w, r, map[string][]string{flashType: {msg}})
+	http.Redirect(w, r, "/user?"+role+"=true#two-factor", http.StatusMovedPermanently)
+}
+
+// recoveryCodesMsg formats recovery codes as a flash message, since they can
+// only be shown once.
+func recoveryCodesMsg(prefix string, recoveryCodes []string) string {
+	return fmt.Sprintf(
+		"%s Save these recovery codes somewhere safe, each can be used once if you lose your authenticator app. They will not be shown again:<div><code>%s</code></div>",
+		prefix, strings.Join(recoveryCodes, "</code> <code>"),
+	)
+}
+
+func (ap App) UserTOTPSetup(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	_, err := ap.skylb.SetupTOTP(user.UserID)
+	if err != nil {
+		if erro.Is(err, skylab.ErrTOTPAlreadyEnabled) {
+			ap.totpRedirect(w, r, role, flash.Error, "Two-factor authentication is already enabled")
+			return
+		}
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	ap.totpRedirect(w, r, role, flash.Success, "Scan the QR code with your authenticator app, then enter the code it shows to finish setting up two-factor authentication")
+}
+
+func (ap App) UserTOTPEnable(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	recoveryCodes, err := ap.skylb.EnableTOTP(user.UserID, r.FormValue("code"))
+	if err != nil {
+		switch {
+		case erro.Is(err, skylab.ErrTOTPCodeInvalid):
+			ap.totpRedirect(w, r, role, flash.Error, "Incorrect code, please try again")
+		case erro.Is(err, skylab.ErrTOTPNotSetUp), erro.Is(err, skylab.ErrTOTPAlreadyEnabled):
+			ap.totpRedirect(w, r, role, flash.Error, "Two-factor authentication is not being set up, please start again")
+		default:
+			ap.skylb.InternalServerError(w, r, err)
+		}
+		return
+	}
+	// The current session has just proven it has the authenticator app, so it
+	// does not need to enter another code. The user's other sessions will.
+	cookieName := skylab.SessionCookieName
+	if role == "admin" {
+		cookieName = skylab.AdminSessionCookieName
+	}
+	err = ap.skylb.SetSessionTOTPVerified(w, cookies.GetCookieValue(r, cookieName))
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	ap.totpRedirect(w, r, role, flash.Success, recoveryCodesMsg("Two-factor authentication is now enabled.", recoveryCodes))
+}
+
+func (ap App) UserTOTPRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	ok, err := ap.skylb.VerifyTOTP(user.UserID, r.FormValue("code"))
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if !ok {
+		ap.totpRedirect(w, r, role, flash.Error, "Incorrect code, please try again")
+		return
+	}
+	recoveryCodes, err := ap.skylb.GenerateRecoveryCodes(user.UserID)
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	ap.totpRedirect(w, r, role, flash.Success, recoveryCodesMsg("Your old recovery codes no longer work.", recoveryCodes))
+}
+
+func (ap App) UserTOTPDisable(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	ok, err := ap.skylb.VerifyTOTP(user.UserID, r.FormValue("code"))
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if !ok {
+		ap.totpRedirect(w, r, role, flash.Error, "Incorrect code, please try again")
+		return
+	}
+	err = ap.skylb.DisableTOTP(user)
+	if err != nil {
+		if erro.Is(err, skylab.ErrTOTPRequired) {
+			ap.totpRedirect(w, r, role, flash.Error, "Two-factor authentication is required for admins and cannot be disabled")
+			return
+		}
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	ap.totpRedirect(w, r, role, flash.Success, "Two-factor authentication has been disabled")
+}
@@ -1,9 +1,15 @@
 package app
 
 import (
+	"fmt"
+	"html/template"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/cookies"
+	"github.com/bokwoon95/nusskylabx/helpers/flash"
 	"github.com/bokwoon95/nusskylabx/helpers/urlparams"
 )
 
@@ -18,10 +24,22 @@ func (ap App) User(w http.ResponseWriter, r *http.Request) {
 	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
 	admin, _ := r.Context().Value(skylab.ContextAdmin).(skylab.User)
 	type Data struct {
-		User skylab.User
-		Role string
+		User              skylab.User
+		Role              string
+		APITokens         []skylab.APIToken
+		APITokenMaxDays   int
+		CanCreateAPIToken bool
+		Sessions          []skylab.Session
+		SessionMaxAgeDays int
+		SessionIdleDays   int
+		TOTP              skylab.TOTP
+		TOTPQRCode        template.HTML
+		TOTPRequired      bool
 	}
 	var data Data
+	data.APITokenMaxDays = skylab.APITokenMaxDays
+	data.SessionMaxAgeDays = int(skylab.SessionMaxAge.Hours() / 24)
+	data.SessionIdleDays = int(skylab.SessionIdleTimeout.Hours() / 24)
 	if asUser {
 		data.User = user
 		data.Role = "user"
@@ -30,6 +48,37 @@ func (ap App) User(w http.ResponseWriter, r *http.Request) {
 		data.User = admin
 		data.Role = "admin"
 	}
+	if data.User.Valid {
+		var err error
+		data.APITokens, err = ap.skylb.ListAPITokens(data.User.UserID)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		cookieName := skylab.SessionCookieName
+		if asAdmin {
+			cookieName = skylab.AdminSessionCookieName
+		}
+		data.Sessions, err = ap.skylb.ListSessions(data.User.UserID, cookies.GetCookieValue(r, cookieName))
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		data.TOTP, err = ap.skylb.GetTOTP(data.User.UserID)
+		if err != nil {
+			ap.skylb.InternalServerError(w, r, err)
+			return
+		}
+		if data.TOTP.Valid && !data.TOTP.Enabled {
+			data.TOTPQRCode, err = data.TOTP.QRCode(data.User.Email)
+			if err != nil {
+				ap.skylb.InternalServerError(w, r, err)
+				return
+			}
+		}
+		data.TOTPRequired = data.User.Roles[skylab.RoleAdmin] != 0
+		data.CanCreateAPIToken = data.User.Roles[skylab.RoleAdmin] != 0 && !isImpersonating(r)
+	}
 	ap.skylb.Render(w, r, data, nil, "app/user.html")
 }
 
@@ -52,3 +101,134 @@ func (ap App) UserUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	http.Redirect(w, r, "/user"+param, http.StatusMovedPermanently)
 }
+
+// userFromRole returns the admin if role is "admin", otherwise it returns
+// the user.
+func userFromRole(r *http.Request, role string) skylab.User {
+	if role == "admin" {
+		admin, _ := r.Context().Value(skylab.ContextAdmin).(skylab.User)
+		return admin
+	}
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	return user
+}
+
+// isImpersonating reports whether an admin is logged in as another user.
+func isImpersonating(r *http.Request) bool {
+	user, _ := r.Context().Value(skylab.ContextUser).(skylab.User)
+	admin, _ := r.Context().Value(skylab.ContextAdmin).(skylab.User)
+	return user.Valid && admin.Valid && user.UserID != admin.UserID
+}
+
+// UserAPITokenCreate creates an API token for the user. The API only serves
+// admins, so every token is scoped to the admin role.
+func (ap App) UserAPITokenCreate(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	redirectURL := "/user?" + role + "=true"
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	msgs := make(map[string][]string)
+	err := r.ParseForm()
+	if err != nil {
+		ap.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	days, err := strconv.Atoi(r.FormValue("expiresInDays"))
+	if err != nil || days < 1 || days > skylab.APITokenMaxDays {
+		msgs[flash.Error] = append(msgs[flash.Error], fmt.Sprintf("Token expiry must be between 1 and %d days", skylab.APITokenMaxDays))
+		_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
+		return
+	}
+	if isImpersonating(r) {
+		msgs[flash.Error] = append(msgs[flash.Error], "API tokens cannot be created while logged in as another user")
+		_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
+		return
+	}
+	if user.Roles[skylab.RoleAdmin] == 0 {
+		msgs[flash.Error] = append(msgs[flash.Error], "Only admins can create API tokens")
+		_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
+		return
+	}
+	// An admin scoped token would let an admin who has not passed two-factor
+	// authentication get around RequireAdminTOTP
+	cookieName := skylab.SessionCookieName
+	if role == "admin" {
+		cookieName = skylab.AdminSessionCookieName
+	}
+	verified, err := ap.skylb.SessionIsTOTPVerified(cookies.GetCookieValue(r, cookieName))
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	if !verified {
+		msgs[flash.Error] = append(msgs[flash.Error], "Set up two-factor authentication before creating an API token")
+		_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
+		return
+	}
+	name := r.FormValue("name")
+	readOnly := r.FormValue("readOnly") != ""
+	token, err := ap.skylb.CreateAPIToken(user, name, readOnly, []string{skylab.RoleAdmin}, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	msgs[flash.Success] = append(msgs[flash.Success], fmt.Sprintf(
+		"Created API token '%s'. Copy it now, it will not be shown again: %s", name, token,
+	))
+	_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+	http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
+}
+
+func (ap App) UserAPITokenRevoke(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	apiTokenID, err := urlparams.Int(r, "apiTokenID")
+	if err != nil {
+		ap.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	err = ap.skylb.RevokeAPIToken(user.UserID, apiTokenID)
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	msgs := map[string][]string{flash.Success: {"API token revoked"}}
+	_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+	http.Redirect(w, r, "/user?"+role+"=true", http.StatusMovedPermanently)
+}
+
+func (ap App) UserSessionRevoke(w http.ResponseWriter, r *http.Request) {
+	ap.skylb.Log.TraceRequest(r)
+	role := r.FormValue("role")
+	user := userFromRole(r, role)
+	if !user.Valid {
+		ap.skylb.NotLoggedIn(w, r)
+		return
+	}
+	sessionID, err := urlparams.Int(r, "sessionID")
+	if err != nil {
+		ap.skylb.BadRequest(w, r, err.Error())
+		return
+	}
+	err = ap.skylb.RevokeSessionByID(user.UserID, sessionID)
+	if err != nil {
+		ap.skylb.InternalServerError(w, r, err)
+		return
+	}
+	msgs := map[string][]string{flash.Success: {"Session revoked"}}
+	_, _ = ap.skylb.SetFlashMsgs(w, r, msgs)
+	http.Redirect(w, r, "/user?"+role+"=true", http.StatusMovedPermanently)
+}
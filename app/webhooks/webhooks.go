@@ -0,0 +1,385 @@
+// Package webhooks implements the background dispatcher that delivers
+// lifecycle events (recorded into the webhook_events table by database
+// triggers) to the admin-configured webhooks. Every tick, the dispatcher first
+// computes the payload of every undispatched event and fans it out into one
+// webhook_deliveries row per subscribed webhook, then attempts every pending
+// delivery that is due, rescheduling failed attempts with exponential backoff
+// until MaxAttempts is reached. Events and deliveries are claimed one at a time
+// before they are handled, so that several instances of skylab (or
+// overlapping ticks) never dispatch or deliver the same one twice.
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	sq "github.com/bokwoon95/go-structured-query/postgres"
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/helpers/erro"
+	"github.com/bokwoon95/nusskylabx/helpers/webhook"
+	"github.com/bokwoon95/nusskylabx/tables"
+)
+
+const (
+	// DefaultInterval is how often the dispatcher checks for new events and
+	// pending deliveries.
+	DefaultInterval = 10 * time.Second
+
+	// MaxAttempts is the number of attempts made for each delivery before it
+	// is marked as failed.
+	MaxAttempts = 8
+
+	batchSize = 100
+
+	// deliveryLease is how long a claimed delivery is hidden from other
+	// dispatchers while it is being sent. It must be longer than the client
+	// timeout; if the dispatcher dies mid-send, the delivery is retried once
+	// the lease is up.
+	deliveryLease = time.Minute
+)
+
+type Webhooks struct {
+	skylb  skylab.Skylab
+	client *http.Client
+}
+
+func New(skylb skylab.Skylab) Webhooks {
+	return Webhooks{
+		skylb:  skylb,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run dispatches events and deliveries every interval until ctx is cancelled.
+// Errors are logged instead of returned so that one bad event or delivery
+// does not stop the dispatcher.
+func (whs Webhooks) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := whs.Tick(ctx); err != nil {
+			whs.skylb.Log.Printf("webhooks: %+v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs one round of DispatchEvents followed by DeliverPending.
+func (whs Webhooks) Tick(ctx context.Context) error {
+	err := whs.DispatchEvents()
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	err = whs.DeliverPending(ctx)
+	if err != nil {
+		return erro.Wrap(err)
+	}
+	return nil
+}
+
+// DispatchEvents computes the payload of every undispatched event and fans it
+// out into the webhook_deliveries of every enabled webhook subscribed to it.
+func (whs Webhooks) DispatchEvents() error {
+	for i := 0; i < batchSize; i++ {
+		dispatched, err := whs.dispatchEvent()
+		if err != nil {
+			return erro.Wrap(err)
+		}
+		if !dispatched {
+			return nil
+		}
+	}
+	return nil
+}
+
+// dispatchEvent claims the oldest undispatched event that no other dispatcher
+// is working on and dispatches it. The event stays locked until it has been
+// marked as dispatched. dispatched is false if there was no such event.
+func (whs Webhooks) dispatchEvent() (dispatched bool, err error) {
+	var event struct {
+		WebhookEventID int
+		Event          string
+		ObjectID       int
+		Changes        []byte
+		CreatedAt      time.Time
+	}
+	tx, err := whs.skylb.DB.Begin()
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	err = tx.QueryRow(
+		`SELECT webhook_event_id, event, object_id, changes, created_at
+		FROM webhook_events
+		WHERE dispatched_at IS NULL
+		ORDER BY webhook_event_id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+	).Scan(&event.WebhookEventID, &event.Event, &event.ObjectID, &event.Changes, &event.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, erro.Wrap(tx.Rollback())
+	}
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	data, dataErr := whs.eventData(event.Event, event.ObjectID)
+	if dataErr != nil {
+		whs.skylb.Log.Printf("webhooks: could not compute data for event %d: %+v", event.WebhookEventID, dataErr)
+	}
+	payload, err := json.Marshal(skylab.WebhookPayload{
+		EventID:   event.WebhookEventID,
+		Event:     event.Event,
+		CreatedAt: event.CreatedAt,
+		Data:      data,
+		Changes:   event.Changes,
+	})
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	_, err = tx.Exec(
+		`INSERT INTO webhook_deliveries (webhook_id, webhook_event_id)
+		SELECT webhook_id, $1 FROM webhooks WHERE enabled AND (events = '{}' OR $2 = ANY(events))
+		ON CONFLICT DO NOTHING`,
+		event.WebhookEventID, event.Event,
+	)
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	_, err = tx.Exec(
+		`UPDATE webhook_events SET payload = $1, dispatched_at = NOW() WHERE webhook_event_id = $2`,
+		payload, event.WebhookEventID,
+	)
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	return true, nil
+}
+
+// eventData fetches the object that the event is about, reusing the same
+// RowMappers as the rest of Skylab.
+func (whs Webhooks) eventData(event string, objectID int) (data interface{}, err error) {
+	switch event {
+	case skylab.WebhookEventApplicationAccepted:
+		apps := tables.V_APPLICATIONS()
+		application := &skylab.Application{}
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(apps).
+			Where(apps.APPLICATION_ID.EqInt(objectID)).
+			SelectRowx(application.RowMapper(apps)).
+			Fetch(whs.skylb.DB)
+		data = application
+	case skylab.WebhookEventSubmissionSubmitted:
+		s := tables.V_SUBMISSIONS()
+		submission := &skylab.Submission{}
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(s).
+			Where(s.SUBMISSION_ID.EqInt(objectID)).
+			SelectRowx(submission.RowMapper(s)).
+			Fetch(whs.skylb.DB)
+		data = submission
+	case skylab.WebhookEventTeamEvaluationSubmitted:
+		te := tables.V_TEAM_EVALUATIONS()
+		evaluation := &skylab.TeamEvaluation{}
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(te).
+			Where(te.TEAM_EVALUATION_ID.EqInt(objectID)).
+			SelectRowx(evaluation.RowMapper(te)).
+			Fetch(whs.skylb.DB)
+		data = evaluation
+	case skylab.WebhookEventUserEvaluationSubmitted:
+		ue := tables.V_USER_EVALUATIONS()
+		evaluation := &skylab.UserEvaluation{}
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(ue).
+			Where(ue.USER_EVALUATION_ID.EqInt(objectID)).
+			SelectRowx(evaluation.RowMapper(ue)).
+			Fetch(whs.skylb.DB)
+		data = evaluation
+	case skylab.WebhookEventTeamStatusChanged:
+		t := tables.V_TEAMS()
+		team := &skylab.Team{}
+		err = sq.WithDefaultLog(sq.Lverbose).
+			From(t).
+			Where(t.TEAM_ID.EqInt(objectID)).
+			SelectRowx(team.RowMapper(t)).
+			Fetch(whs.skylb.DB)
+		data = team
+	default:
+		return nil, fmt.Errorf("unknown webhook event '%s'", event)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, erro.Wrap(err)
+	}
+	return data, nil
+}
+
+// DeliverPending attempts every pending delivery that is due.
+func (whs Webhooks) DeliverPending(ctx context.Context) error {
+	for i := 0; i < batchSize; i++ {
+		delivered, err := whs.deliverPending(ctx)
+		if err != nil {
+			return erro.Wrap(err)
+		}
+		if !delivered {
+			return nil
+		}
+	}
+	return nil
+}
+
+// deliverPending claims the pending delivery that has been due the longest by
+// pushing its next_attempt_at back by deliveryLease, then attempts it.
+// delivered is false if there was no delivery due.
+func (whs Webhooks) deliverPending(ctx context.Context) (delivered bool, err error) {
+	var pending struct {
+		WebhookDeliveryID int
+		Attempts          int
+		Event             string
+		Payload           []byte
+		URL               string
+		Secret            string
+	}
+	err = whs.skylb.DB.QueryRowContext(ctx,
+		`UPDATE webhook_deliveries AS wd
+		SET next_attempt_at = $1
+		FROM webhook_events AS we, webhooks AS wh
+		WHERE wd.webhook_delivery_id = (
+			SELECT wd2.webhook_delivery_id
+			FROM webhook_deliveries AS wd2
+			JOIN webhooks AS wh2 ON wh2.webhook_id = wd2.webhook_id
+			WHERE wd2.status = $2 AND wd2.next_attempt_at <= NOW() AND wh2.enabled
+			ORDER BY wd2.next_attempt_at
+			LIMIT 1
+			FOR UPDATE OF wd2 SKIP LOCKED
+		)
+		AND we.webhook_event_id = wd.webhook_event_id
+		AND wh.webhook_id = wd.webhook_id
+		RETURNING wd.webhook_delivery_id, wd.attempts, we.event, we.payload, wh.url, wh.secret`,
+		time.Now().Add(deliveryLease), skylab.WebhookDeliveryStatusPending,
+	).Scan(&pending.WebhookDeliveryID, &pending.Attempts, &pending.Event, &pending.Payload, &pending.URL, &pending.Secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	resp, sendErr := webhook.Send(ctx, whs.client, webhook.Request{
+		URL:        pending.URL,
+		Secret:     pending.Secret,
+		Event:      pending.Event,
+		DeliveryID: strconv.Itoa(pending.WebhookDeliveryID),
+		Body:       pending.Payload,
+	})
+	attempts := pending.Attempts + 1
+	status := skylab.WebhookDeliveryStatusPending
+	var errMsg string
+	switch {
+	case sendErr == nil && resp.OK():
+		status = skylab.WebhookDeliveryStatusSucceeded
+	case sendErr != nil:
+		errMsg = sendErr.Error()
+	default:
+		errMsg = fmt.Sprintf("receiver responded with %d", resp.StatusCode)
+	}
+	if status == skylab.WebhookDeliveryStatusPending && attempts >= MaxAttempts {
+		status = skylab.WebhookDeliveryStatusFailed
+	}
+	var responseStatus sql.NullInt64
+	if resp.StatusCode != 0 {
+		responseStatus = sql.NullInt64{Int64: int64(resp.StatusCode), Valid: true}
+	}
+	wd := tables.WEBHOOK_DELIVERIES()
+	_, err = sq.WithDefaultLog(sq.Lverbose).
+		Update(wd).
+		Set(
+			wd.STATUS.SetString(status),
+			wd.ATTEMPTS.SetInt(attempts),
+			wd.NEXT_ATTEMPT_AT.SetTime(time.Now().Add(webhook.Backoff(attempts))),
+			wd.RESPONSE_STATUS.Set(responseStatus),
+			wd.RESPONSE_BODY.SetString(resp.Body),
+			wd.ERROR.SetString(errMsg),
+		).
+		Where(wd.WEBHOOK_DELIVERY_ID.EqInt(pending.WebhookDeliveryID)).
+		Exec(whs.skylb.DB, 0)
+	if err != nil {
+		return false, erro.Wrap(err)
+	}
+	return true, nil
+}
+
+// ListDeliveries lists the most recent deliveries of a webhook, newest first.
+func ListDeliveries(skylb skylab.Skylab, webhookID int, limit int) (deliveries []skylab.WebhookDelivery, err error) {
+	wd, we := tables.WEBHOOK_DELIVERIES(), tables.WEBHOOK_EVENTS()
+	var delivery skylab.WebhookDelivery
+	err = sq.WithDefaultLog(sq.Lverbose).
+		From(wd).
+		Join(we, we.WEBHOOK_EVENT_ID.Eq(wd.WEBHOOK_EVENT_ID)).
+		Where(wd.WEBHOOK_ID.EqInt(webhookID)).
+		OrderBy(wd.WEBHOOK_DELIVERY_ID.Desc()).
+		Limit(limit).
+		Selectx(func(row *sq.Row) {
+			delivery = skylab.WebhookDelivery{
+				Valid:             row.IntValid(wd.WEBHOOK_DELIVERY_ID),
+				WebhookDeliveryID: row.Int(wd.WEBHOOK_DELIVERY_ID),
+				WebhookID:         row.Int(wd.WEBHOOK_ID),
+				WebhookEventID:    row.Int(wd.WEBHOOK_EVENT_ID),
+				Event:             row.String(we.EVENT),
+				Status:            row.String(wd.STATUS),
+				Attempts:          row.Int(wd.ATTEMPTS),
+				NextAttemptAt:     row.Time(wd.NEXT_ATTEMPT_AT),
+				ResponseStatus:    row.NullInt64(wd.RESPONSE_STATUS),
+				ResponseBody:      row.String(wd.RESPONSE_BODY),
+				Error:             row.String(wd.ERROR),
+				CreatedAt:         row.Time(wd.CREATED_AT),
+				UpdatedAt:         row.Time(wd.UPDATED_AT),
+			}
+			var payload []byte
+			row.ScanInto(&payload, we.PAYLOAD)
+			delivery.Payload = payload
+		}, func() {
+			deliveries = append(deliveries, delivery)
+		}).
+		Fetch(skylb.DB)
+	return deliveries, erro.Wrap(err)
+}
+
+// Ping synchronously sends a test event to the webhook URL, bypassing the
+// delivery log. It is used by the admin's "Send Test" button.
+func Ping(ctx context.Context, wh skylab.Webhook) (webhook.Response, error) {
+	payload, err := json.Marshal(skylab.WebhookPayload{
+		Event:     skylab.WebhookEventPing,
+		CreatedAt: time.Now(),
+		Data:      map[string]interface{}{"webhook_id": wh.WebhookID},
+	})
+	if err != nil {
+		return webhook.Response{}, erro.Wrap(err)
+	}
+	return webhook.Send(ctx, nil, webhook.Request{
+		URL:        wh.URL,
+		Secret:     wh.Secret,
+		Event:      skylab.WebhookEventPing,
+		DeliveryID: "ping",
+		Body:       payload,
+	})
+}
@@ -0,0 +1,146 @@
+// loadforms syncs a folder of form files (see skylab.FormFile) into the
+// database, so that forms can be kept in version control and moved between
+// staging and production.
+//
+//	go run cmd/loadforms/main.go                     # import every form file in forms/
+//	go run cmd/loadforms/main.go forms/2020_*.yaml   # import only these files
+//	go run cmd/loadforms/main.go -check              # only validate the form files
+//	go run cmd/loadforms/main.go -export 2020        # write every form of the 2020 cohort into forms/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bokwoon95/nusskylabx/app/skylab"
+)
+
+var (
+	dirFlag     = flag.String("dir", "forms", "Folder of form files to import, or to export forms into")
+	checkFlag   = flag.Bool("check", false, "Validate the form files without importing them")
+	discardFlag = flag.Bool("discard", false, "Import forms even if they would orphan existing answers")
+	exportFlag  = flag.String("export", "", "Export every form of this cohort into the folder instead of importing")
+	formatFlag  = flag.String("format", skylab.FormFileFormatYAML, "File format to export forms in (yaml or json)")
+)
+
+func main() {
+	flag.Parse()
+	log.SetFlags(log.LstdFlags | log.Llongfile)
+	skylab.LoadDotenv()
+	skylb, err := skylab.New(skylab.Config{
+		DatabaseURL:  os.Getenv("DATABASE_URL"),
+		MigrationDir: os.Getenv("MIGRATION_DIR"),
+	})
+	if err != nil {
+		printAndExit(err.Error())
+	}
+	if *exportFlag != "" {
+		err = exportForms(skylb, *exportFlag)
+		if err != nil {
+			printAndExit(err.Error())
+		}
+		return
+	}
+	filenames := flag.Args()
+	if len(filenames) == 0 {
+		for _, pattern := range []string{"*.json", "*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(*dirFlag, pattern))
+			if err != nil {
+				printAndExit(err.Error())
+			}
+			filenames = append(filenames, matches...)
+		}
+		sort.Strings(filenames)
+	}
+	if len(filenames) == 0 {
+		printAndExit("No form files found in %s", *dirFlag)
+	}
+	var failed int
+	for _, filename := range filenames {
+		err = loadForm(skylb, filename)
+		if err != nil {
+			fmt.Printf("[FAIL] %s: %s\n", filename, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		printAndExit("%d of %d form file(s) failed", failed, len(filenames))
+	}
+}
+
+func loadForm(skylb skylab.Skylab, filename string) error {
+	format, err := skylab.FormFileFormatOf(filename)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	ff, err := skylab.UnmarshalFormFile(b, format)
+	if err != nil {
+		return err
+	}
+	if *checkFlag {
+		err = ff.Check(skylb.Cohorts())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[OK] %s\n", filename)
+		return nil
+	}
+	formID, version, err := skylb.ImportForm(ff, 0, *discardFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[OK] %s => form %d (version %d)\n", filename, formID, version)
+	return nil
+}
+
+func exportForms(skylb skylab.Skylab, cohort string) error {
+	if *formatFlag != skylab.FormFileFormatYAML && *formatFlag != skylab.FormFileFormatJSON {
+		return fmt.Errorf("-format must be %s or %s", skylab.FormFileFormatYAML, skylab.FormFileFormatJSON)
+	}
+	var formIDs []int
+	err := skylb.DB.Select(&formIDs, `
+	SELECT forms.form_id
+	FROM forms JOIN periods ON periods.period_id = forms.period_id
+	WHERE periods.cohort = $1 AND forms.form_id <> 0
+	ORDER BY forms.form_id
+	`, cohort)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(*dirFlag, 0755)
+	if err != nil {
+		return err
+	}
+	for _, formID := range formIDs {
+		ff, err := skylb.ExportForm(formID)
+		if err != nil {
+			return err
+		}
+		b, err := skylab.MarshalFormFile(ff, *formatFlag)
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(*dirFlag, ff.Filename(*formatFlag))
+		err = ioutil.WriteFile(filename, b, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[OK] form %d => %s\n", formID, filename)
+	}
+	return nil
+}
+
+func printAndExit(format string, v ...interface{}) {
+	fmt.Println("======================================== ERROR! ========================================")
+	log.Output(1, fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
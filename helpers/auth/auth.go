@@ -3,6 +3,8 @@ package auth
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -18,6 +20,7 @@ import (
 	"github.com/bokwoon95/nusskylabx/helpers/erro"
 
 	"github.com/bokwoon95/nusskylabx/helpers/auth/oauth"
+	"github.com/bokwoon95/nusskylabx/helpers/auth/oidc"
 	"github.com/bokwoon95/nusskylabx/helpers/auth/openid"
 	_ "github.com/joho/godotenv/autoload"
 	"golang.org/x/crypto/bcrypt"
@@ -26,6 +29,7 @@ import (
 const (
 	ErrDeserializeOutputInvalid erro.BaseError = "deserialize output is invalid because provided signature [%s] does not match computed signature [%s]"
 	ErrDeserializeInputInvalid  erro.BaseError = "deserialize input [%s] is invalid because missing '.' in string"
+	ErrDecryptInputInvalid      erro.BaseError = "decrypt input is invalid because it is shorter than the nonce"
 )
 
 func GenerateRandomString() (string, error) {
@@ -95,6 +99,50 @@ func Deserialize(key string, input string, outputAddr interface{}) (err error) {
 	return nil
 }
 
+// Encrypt encrypts plaintext with AES-256-GCM, using a key derived from key.
+// Unlike Serialize, the output cannot be read without the key. It can be
+// converted back with Decrypt.
+func Encrypt(key string, plaintext []byte) (output string, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return output, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return output, err
+	}
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.URLEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts input that was encrypted with Encrypt. It requires the same
+// key that was used to encrypt it, and fails if the input was tampered with.
+func Decrypt(key string, input string) (plaintext []byte, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return plaintext, err
+	}
+	ciphertext, err := base64.URLEncoding.DecodeString(input)
+	if err != nil {
+		return plaintext, fmt.Errorf("error with base64 URL decoding string: %w", err)
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return plaintext, erro.Errorf(ErrDecryptInputInvalid)
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -115,6 +163,8 @@ func Redirect(
 		oauth.Redirect(provider, returnTo, errorHandler)(w, r)
 	case openid.IsValidProvider(provider):
 		openid.Redirect(provider, returnTo, errorHandler)(w, r)
+	case oidc.IsValidProvider(provider):
+		oidc.Redirect(provider, returnTo, errorHandler)(w, r)
 	case provider == "":
 		errorHandler(w, r, fmt.Errorf("provider cannot be blank"))
 	default:
@@ -126,6 +176,8 @@ func Authenticate(returnTo string, errorHandler func(http.ResponseWriter, *http.
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			switch {
+			case oidc.IsCallback(r): // it's from oidc
+				oidc.Authenticate(returnTo, errorHandler)(next).ServeHTTP(w, r)
 			case r.FormValue("state") != "": // it's from oauth
 				oauth.Authenticate(returnTo, errorHandler)(next).ServeHTTP(w, r)
 			case r.FormValue("openid.sreg.email") != "": // it's from openid
@@ -139,7 +191,24 @@ func Authenticate(returnTo string, errorHandler func(http.ResponseWriter, *http.
 }
 
 func IsValidProvider(provider string) bool {
-	return openid.IsValidProvider(provider) || oauth.IsValidProvider(provider)
+	return openid.IsValidProvider(provider) || oauth.IsValidProvider(provider) || oidc.IsValidProvider(provider)
+}
+
+// LoadOIDCProviders registers the OpenID Connect providers from a JSON array
+// of oidc.Configs (see oidc.ParseConfigs). A provider cannot reuse the name
+// of a built-in openid or oauth provider.
+func LoadOIDCProviders(data string) error {
+	configs, err := oidc.ParseConfigs(data)
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		if openid.IsValidProvider(config.Name) || oauth.IsValidProvider(config.Name) {
+			return fmt.Errorf("OIDC provider name '%s' is already used by a built-in provider", config.Name)
+		}
+		oidc.Register(config)
+	}
+	return nil
 }
 
 func AddConstProvider(funcs template.FuncMap) template.FuncMap {
@@ -148,5 +217,6 @@ func AddConstProvider(funcs template.FuncMap) template.FuncMap {
 	}
 	funcs = openid.AddConstProvider(funcs)
 	funcs = oauth.AddConstProvider(funcs)
+	funcs["OIDCProviders"] = oidc.Providers
 	return funcs
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/bokwoon95/nusskylabx/helpers/random"
@@ -42,6 +43,23 @@ func TestSerializeDeserialize(t *testing.T) {
 	is.Equal(data2, data1)
 }
 
+func TestEncryptDecrypt(t *testing.T) {
+	is := is.New(t)
+	key, err := GenerateRandomString()
+	is.NoErr(err)
+	plaintext := []byte(random.Sentence(10))
+	str, err := Encrypt(key, plaintext)
+	is.NoErr(err)
+	is.True(!strings.Contains(str, string(plaintext)))
+	decrypted, err := Decrypt(key, str)
+	is.NoErr(err)
+	is.Equal(decrypted, plaintext)
+	_, err = Decrypt(key+"abcd", str)
+	is.True(err != nil) // wrong key
+	_, err = Decrypt(key, "abcd")
+	is.True(err != nil) // too short
+}
+
 func TestHashPassword(t *testing.T) {
 	is := is.New(t)
 	password := random.Word()
@@ -0,0 +1,530 @@
+// Package oidc implements the OpenID Connect authorization code flow for the
+// Relying Party against any issuer that supports discovery. Unlike the oauth
+// and openid packages, providers are not hardcoded but registered at startup
+// from configuration.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bokwoon95/nusskylabx/helpers/headers"
+	"golang.org/x/oauth2"
+)
+
+// Config configures a single OpenID Connect provider. It is usually loaded
+// from JSON with ParseConfigs.
+type Config struct {
+	Name             string   `json:"name"`              // used as the ?provider= value, e.g. "microsoft"
+	DisplayName      string   `json:"display_name"`      // shown on the login page, e.g. "Microsoft"
+	Issuer           string   `json:"issuer"`            // e.g. "https://login.microsoftonline.com/{tenant}/v2.0"
+	ClientID         string   `json:"client_id"`         //
+	ClientSecret     string   `json:"client_secret"`     //
+	Scopes           []string `json:"scopes"`            // defaults to openid, email and profile
+	EmailClaim       string   `json:"email_claim"`       // defaults to "email"
+	DisplaynameClaim string   `json:"displayname_claim"` // defaults to "name"
+
+	// TrustEmailClaim must be set to use an EmailClaim other than "email",
+	// or to accept logins from a provider that does not send an
+	// email_verified claim. Users are matched by their email, so it must only
+	// be set if the claim is one that the provider verifies and that users
+	// cannot change to someone else's email.
+	TrustEmailClaim bool `json:"trust_email_claim"`
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ParseConfigs parses a JSON array of Configs, filling in the defaults.
+func ParseConfigs(data string) (configs []Config, err error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+	err = json.Unmarshal([]byte(data), &configs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC provider config: %w", err)
+	}
+	for i := range configs {
+		cfg := &configs[i]
+		if !validName.MatchString(cfg.Name) {
+			return nil, fmt.Errorf("OIDC provider name '%s' must only contain lowercase letters, digits, '-' and '_'", cfg.Name)
+		}
+		if cfg.Issuer == "" || cfg.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider '%s' must have an issuer and a client_id", cfg.Name)
+		}
+		if cfg.DisplayName == "" {
+			cfg.DisplayName = cfg.Name
+		}
+		if len(cfg.Scopes) == 0 {
+			cfg.Scopes = []string{"openid", "email", "profile"}
+		}
+		if cfg.EmailClaim == "" {
+			cfg.EmailClaim = "email"
+		}
+		if cfg.EmailClaim != "email" && !cfg.TrustEmailClaim {
+			return nil, fmt.Errorf("OIDC provider '%s' must set trust_email_claim to use the email_claim '%s'", cfg.Name, cfg.EmailClaim)
+		}
+		if cfg.DisplaynameClaim == "" {
+			cfg.DisplaynameClaim = "name"
+		}
+	}
+	return configs, nil
+}
+
+// Discovery is the subset of the issuer's
+// /.well-known/openid-configuration document that is needed.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is a registered OpenID Connect provider. The discovery document
+// and signing keys are fetched lazily on first use and then cached, so that
+// an unreachable issuer does not prevent the server from starting.
+type Provider struct {
+	Config
+	Client *http.Client
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      map[string]*rsa.PublicKey // keyed by kid
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*Provider)
+	order      []string
+)
+
+// Register adds a provider to the registry, replacing any existing provider
+// with the same name.
+func Register(cfg Config) *Provider {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	p := &Provider{Config: cfg, Client: &http.Client{Timeout: 10 * time.Second}}
+	if _, ok := registry[cfg.Name]; !ok {
+		order = append(order, cfg.Name)
+	}
+	registry[cfg.Name] = p
+	return p
+}
+
+// Get gets a registered provider by name.
+func Get(name string) (*Provider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Providers lists the registered providers in the order they were registered.
+func Providers() []*Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	providers := make([]*Provider, 0, len(order))
+	for _, name := range order {
+		providers = append(providers, registry[name])
+	}
+	return providers
+}
+
+func IsValidProvider(provider string) bool {
+	_, ok := Get(provider)
+	return ok
+}
+
+// Discover fetches (and caches) the issuer's discovery document.
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	var d Discovery
+	err := p.getJSON(ctx, strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", &d)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(p.Issuer, "/") {
+		return nil, fmt.Errorf("discovered issuer '%s' does not match configured issuer '%s'", d.Issuer, p.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of '%s' is incomplete: %+v", p.Issuer, d)
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s responded with %d: %s", url, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (p *Provider) oauth2Config(d *Discovery, returnTo string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  returnTo,
+		Scopes:       p.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  d.AuthorizationEndpoint,
+			TokenURL: d.TokenEndpoint,
+		},
+	}
+}
+
+const (
+	stateCookieName = "_oidc_state"
+	statePrefix     = "oidc." // distinguishes OIDC callbacks from oauth callbacks, which also have a state
+)
+
+type state struct {
+	State    string
+	Nonce    string
+	Provider string
+}
+
+func generateRandomString() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IsCallback reports whether the request is a callback from an OIDC provider.
+func IsCallback(r *http.Request) bool {
+	return strings.HasPrefix(r.FormValue("state"), statePrefix)
+}
+
+// Redirect redirects the user to the provider's authorization endpoint.
+func Redirect(provider, returnTo string, errorHandler func(http.ResponseWriter, *http.Request, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		headers.DoNotCache(w)
+		p, ok := Get(provider)
+		if !ok {
+			errorHandler(w, r, fmt.Errorf("Invalid provider: %s", provider))
+			return
+		}
+		d, err := p.Discover(r.Context())
+		if err != nil {
+			errorHandler(w, r, err)
+			return
+		}
+		var s state
+		s.Provider = provider
+		s.State, err = generateRandomString()
+		if err != nil {
+			errorHandler(w, r, err)
+			return
+		}
+		s.State = statePrefix + s.State
+		s.Nonce, err = generateRandomString()
+		if err != nil {
+			errorHandler(w, r, err)
+			return
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			errorHandler(w, r, err)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     stateCookieName,
+			Value:    base64.RawURLEncoding.EncodeToString(b),
+			Path:     "/",
+			MaxAge:   int((10 * time.Minute).Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		url := p.oauth2Config(d, returnTo).AuthCodeURL(s.State, oauth2.SetAuthURLParam("nonce", s.Nonce))
+		http.Redirect(w, r, url, http.StatusFound)
+	}
+}
+
+// Authenticate handles the callback from the provider. It exchanges the code
+// for an ID token, verifies the ID token and injects the user's email and
+// displayname (mapped from the configured claims) into the context under the
+// "email" and "displayname" keys, the same way the oauth and openid packages
+// do.
+func Authenticate(returnTo string, errorHandler func(http.ResponseWriter, *http.Request, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if errMsg := r.FormValue("error"); errMsg != "" {
+				errorHandler(w, r, fmt.Errorf("provider returned an error: %s %s", errMsg, r.FormValue("error_description")))
+				return
+			}
+			cookie, err := r.Cookie(stateCookieName)
+			if err != nil {
+				errorHandler(w, r, fmt.Errorf("%s cookie not found, did the login take more than 10 minutes?", stateCookieName))
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: stateCookieName, Path: "/", MaxAge: -1})
+			b, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+			if err != nil {
+				errorHandler(w, r, err)
+				return
+			}
+			var s state
+			err = json.Unmarshal(b, &s)
+			if err != nil {
+				errorHandler(w, r, err)
+				return
+			}
+			if s.State != r.FormValue("state") {
+				errorHandler(w, r, fmt.Errorf("state[%s] doesn't match cookie state[%s]", r.FormValue("state"), s.State))
+				return
+			}
+			p, ok := Get(s.Provider)
+			if !ok {
+				errorHandler(w, r, fmt.Errorf("Invalid provider: %s", s.Provider))
+				return
+			}
+			email, displayname, err := p.Exchange(r.Context(), returnTo, r.FormValue("code"), s.Nonce)
+			if err != nil {
+				errorHandler(w, r, err)
+				return
+			}
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, "username", email)
+			ctx = context.WithValue(ctx, "displayname", displayname)
+			ctx = context.WithValue(ctx, "email", email)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Exchange exchanges the authorization code for an ID token, verifies it and
+// returns the email and displayname mapped from its claims. If the ID token
+// does not contain the claims, they are fetched from the userinfo endpoint.
+func (p *Provider) Exchange(ctx context.Context, returnTo, code, nonce string) (email, displayname string, err error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.Client)
+	token, err := p.oauth2Config(d, returnTo).Exchange(ctx, code)
+	if err != nil {
+		return "", "", err
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return "", "", fmt.Errorf("token response from %s has no id_token", p.Issuer)
+	}
+	claims, err := p.Verify(ctx, rawIDToken, nonce)
+	if err != nil {
+		return "", "", err
+	}
+	if _, ok := claims[p.EmailClaim]; !ok && d.UserinfoEndpoint != "" {
+		var userinfo map[string]interface{}
+		req, err := http.NewRequest(http.MethodGet, d.UserinfoEndpoint, nil)
+		if err != nil {
+			return "", "", err
+		}
+		token.SetAuthHeader(req)
+		resp, err := p.Client.Do(req.WithContext(ctx))
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&userinfo)
+		if err != nil {
+			return "", "", fmt.Errorf("could not decode userinfo: %w", err)
+		}
+		if userinfo["sub"] != claims["sub"] {
+			return "", "", fmt.Errorf("userinfo sub[%v] does not match id_token sub[%v]", userinfo["sub"], claims["sub"])
+		}
+		for k, v := range userinfo {
+			if _, ok := claims[k]; !ok {
+				claims[k] = v
+			}
+		}
+	}
+	return p.MapClaims(claims)
+}
+
+// MapClaims extracts the email and displayname from the claims according to
+// the provider's EmailClaim and DisplaynameClaim. The displayname falls back
+// to the email if it is missing. Logins are rejected unless the email_verified
+// claim is true, or it is missing and the provider has TrustEmailClaim set.
+func (p *Provider) MapClaims(claims map[string]interface{}) (email, displayname string, err error) {
+	if p.EmailClaim != "email" && !p.TrustEmailClaim {
+		return "", "", fmt.Errorf("provider %s does not trust its email_claim '%s'", p.DisplayName, p.EmailClaim)
+	}
+	email, _ = claims[p.EmailClaim].(string)
+	if email == "" {
+		return "", "", fmt.Errorf("claim '%s' (email) not found in %v", p.EmailClaim, claims)
+	}
+	verified, ok := claims["email_verified"]
+	if !ok && !p.TrustEmailClaim {
+		return "", "", fmt.Errorf("%s did not say whether email %s has been verified", p.DisplayName, email)
+	}
+	if ok && verified != true && verified != "true" {
+		return "", "", fmt.Errorf("email %s has not been verified by %s", email, p.DisplayName)
+	}
+	displayname, _ = claims[p.DisplaynameClaim].(string)
+	if displayname == "" {
+		displayname = email
+	}
+	return strings.ToLower(email), displayname, nil
+}
+
+var hashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+// Verify verifies the signature and the iss, aud, exp and nonce claims of an
+// ID token, and returns its claims.
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (claims map[string]interface{}, err error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed id_token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err = decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, fmt.Errorf("malformed id_token header: %w", err)
+	}
+	hash, ok := hashes[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported id_token signing algorithm '%s'", header.Alg)
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id_token signature: %w", err)
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), signature)
+	if err != nil {
+		return nil, fmt.Errorf("id_token signature is invalid: %w", err)
+	}
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return nil, fmt.Errorf("malformed id_token claims: %w", err)
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(p.Issuer, "/") {
+		return nil, fmt.Errorf("id_token issuer '%s' does not match '%s'", iss, p.Issuer)
+	}
+	if !hasAudience(claims["aud"], p.ClientID) {
+		return nil, fmt.Errorf("id_token audience %v does not contain '%s'", claims["aud"], p.ClientID)
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Now().After(time.Unix(int64(exp), 0).Add(time.Minute)) { // allow a minute of clock skew
+		return nil, fmt.Errorf("id_token has expired")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, fmt.Errorf("id_token nonce does not match")
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key gets the signing key with the given kid, refetching the issuer's keys
+// once if it is not found (the issuer may have rotated its keys).
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	err = p.getJSON(ctx, d.JWKSURI, &jwks)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		p.keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("signing key '%s' not found at %s", kid, d.JWKSURI)
+	}
+	return key, nil
+}
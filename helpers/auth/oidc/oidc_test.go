@@ -0,0 +1,227 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeIssuer is a minimal OIDC issuer that hands out ID tokens with the given
+// claims for any code.
+type fakeIssuer struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Discovery{
+			Issuer:                fi.URL,
+			AuthorizationEndpoint: fi.URL + "/authorize",
+			TokenEndpoint:         fi.URL + "/token",
+			JWKSURI:               fi.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     fi.sign(t, fi.claims),
+		})
+	})
+	fi.Server = httptest.NewServer(mux)
+	return fi
+}
+
+func (fi *fakeIssuer) sign(t *testing.T, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "key1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, fi.key, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestParseConfigs(t *testing.T) {
+	is := is.New(t)
+	configs, err := ParseConfigs(`[{"name": "microsoft", "issuer": "https://login.example.com", "client_id": "id"}]`)
+	is.NoErr(err)
+	is.Equal(len(configs), 1)
+	is.Equal(configs[0].DisplayName, "microsoft")
+	is.Equal(configs[0].Scopes, []string{"openid", "email", "profile"})
+	is.Equal(configs[0].EmailClaim, "email")
+	is.Equal(configs[0].DisplaynameClaim, "name")
+
+	_, err = ParseConfigs(`[{"name": "Not Valid", "issuer": "https://login.example.com", "client_id": "id"}]`)
+	is.True(err != nil) // invalid name
+	_, err = ParseConfigs(`[{"name": "noissuer", "client_id": "id"}]`)
+	is.True(err != nil) // missing issuer
+	_, err = ParseConfigs(`[{"name": "upn", "issuer": "https://login.example.com", "client_id": "id", "email_claim": "upn"}]`)
+	is.True(err != nil) // email_claim other than email without trust_email_claim
+	configs, err = ParseConfigs(`[{"name": "upn", "issuer": "https://login.example.com", "client_id": "id", "email_claim": "upn", "trust_email_claim": true}]`)
+	is.NoErr(err)
+	is.Equal(configs[0].EmailClaim, "upn")
+	configs, err = ParseConfigs("")
+	is.NoErr(err)
+	is.Equal(len(configs), 0)
+}
+
+func TestRedirectAndAuthenticate(t *testing.T) {
+	is := is.New(t)
+	fi := newFakeIssuer(t)
+	defer fi.Close()
+	Register(Config{
+		Name:             "fake",
+		DisplayName:      "Fake",
+		Issuer:           fi.URL,
+		ClientID:         "skylab",
+		ClientSecret:     "secret",
+		Scopes:           []string{"openid"},
+		EmailClaim:       "upn",
+		DisplaynameClaim: "name",
+		TrustEmailClaim:  true,
+	})
+	is.True(IsValidProvider("fake"))
+	const returnTo = "http://localhost/login/callback"
+	errorHandler := func(w http.ResponseWriter, r *http.Request, err error) {
+		t.Fatal(err)
+	}
+
+	// Redirect
+	rec := httptest.NewRecorder()
+	Redirect("fake", returnTo, errorHandler)(rec, httptest.NewRequest("GET", "/login?provider=fake", nil))
+	is.Equal(rec.Code, http.StatusFound)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	is.NoErr(err)
+	is.Equal(location.Path, "/authorize")
+	is.Equal(location.Query().Get("client_id"), "skylab")
+	state := location.Query().Get("state")
+	nonce := location.Query().Get("nonce")
+	is.True(nonce != "")
+	cookies := rec.Result().Cookies()
+	is.Equal(len(cookies), 1)
+
+	// Callback
+	fi.claims = map[string]interface{}{
+		"iss":   fi.URL,
+		"sub":   "123",
+		"aud":   "skylab",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+		"upn":   "Staff@NUS.edu.sg",
+		"name":  "Staff Member",
+	}
+	req := httptest.NewRequest("GET", "/login/callback?code=abc&state="+url.QueryEscape(state), nil)
+	is.True(IsCallback(req))
+	req.AddCookie(cookies[0])
+	var email, displayname string
+	rec = httptest.NewRecorder()
+	Authenticate(returnTo, errorHandler)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, _ = r.Context().Value("email").(string)
+		displayname, _ = r.Context().Value("displayname").(string)
+	})).ServeHTTP(rec, req)
+	is.Equal(email, "staff@nus.edu.sg") // mapped from the upn claim
+	is.Equal(displayname, "Staff Member")
+}
+
+func TestMapClaims(t *testing.T) {
+	is := is.New(t)
+	p := &Provider{Config: Config{DisplayName: "Fake", EmailClaim: "upn", DisplaynameClaim: "name", TrustEmailClaim: true}}
+	email, displayname, err := p.MapClaims(map[string]interface{}{"upn": "Staff@NUS.edu.sg"})
+	is.NoErr(err)
+	is.Equal(email, "staff@nus.edu.sg")
+	is.Equal(displayname, "Staff@NUS.edu.sg") // falls back to the email
+
+	_, _, err = p.MapClaims(map[string]interface{}{"upn": "staff@nus.edu.sg", "email_verified": false})
+	is.True(err != nil) // unverified, even though the email is not from the email claim
+	_, _, err = p.MapClaims(map[string]interface{}{"upn": "staff@nus.edu.sg", "email_verified": "false"})
+	is.True(err != nil) // unverified, as a string
+
+	// Without trust_email_claim, email_verified must be present and true
+	p = &Provider{Config: Config{DisplayName: "Fake", EmailClaim: "email", DisplaynameClaim: "name"}}
+	_, _, err = p.MapClaims(map[string]interface{}{"email": "staff@nus.edu.sg"})
+	is.True(err != nil) // missing email_verified
+	_, _, err = p.MapClaims(map[string]interface{}{"email": "staff@nus.edu.sg", "email_verified": true})
+	is.NoErr(err)
+	_, _, err = p.MapClaims(map[string]interface{}{"email": "staff@nus.edu.sg", "email_verified": "true"})
+	is.NoErr(err)
+
+	p.EmailClaim = "upn"
+
+	p.TrustEmailClaim = false
+	_, _, err = p.MapClaims(map[string]interface{}{"upn": "staff@nus.edu.sg"})
+	is.True(err != nil) // untrusted email claim
+}
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+	fi := newFakeIssuer(t)
+	defer fi.Close()
+	p := &Provider{Config: Config{Issuer: fi.URL, ClientID: "skylab"}, Client: fi.Client()}
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   fi.URL,
+			"aud":   []string{"other", "skylab"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": "n",
+		}
+	}
+	_, err := p.Verify(context.Background(), fi.sign(t, valid()), "n")
+	is.NoErr(err)
+
+	claims := valid()
+	claims["aud"] = "other"
+	_, err = p.Verify(context.Background(), fi.sign(t, claims), "n")
+	is.True(err != nil) // wrong audience
+
+	claims = valid()
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = p.Verify(context.Background(), fi.sign(t, claims), "n")
+	is.True(err != nil) // expired
+
+	claims = valid()
+	claims["iss"] = "https://evil.example.com"
+	_, err = p.Verify(context.Background(), fi.sign(t, claims), "n")
+	is.True(err != nil) // wrong issuer
+
+	_, err = p.Verify(context.Background(), fi.sign(t, valid()), "other nonce")
+	is.True(err != nil) // wrong nonce
+
+	token := fi.sign(t, valid())
+	_, err = p.Verify(context.Background(), token[:len(token)-4]+"AAAA", "n")
+	is.True(err != nil) // tampered signature
+}
@@ -0,0 +1,57 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// RFC 6238 Appendix B test vectors for SHA1, truncated to 6 digits
+func TestCodeAt(t *testing.T) {
+	is := is.New(t)
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for unix, code := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		got, err := CodeAt(secret, Step(time.Unix(unix, 0)))
+		is.NoErr(err)
+		is.Equal(got, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+	secret, err := GenerateSecret()
+	is.NoErr(err)
+	now := time.Now()
+	code, err := CodeAt(secret, Step(now))
+	is.NoErr(err)
+
+	step, ok := Validate(secret, code[:3]+" "+code[3:], now, 0)
+	is.True(ok) // spaces are ignored
+	is.Equal(step, Step(now))
+	_, ok = Validate(secret, code, now.Add(Period), 0)
+	is.True(ok) // previous code is still accepted
+	_, ok = Validate(secret, code, now.Add(3*Period), 0)
+	is.True(!ok) // too old
+	_, ok = Validate(secret, code, now, step)
+	is.True(!ok) // already used
+	_, ok = Validate(secret, "12345", now, 0)
+	is.True(!ok) // wrong length
+}
+
+func TestURI(t *testing.T) {
+	is := is.New(t)
+	uri := URI("Skylab", "someone@u.nus.edu", "JBSWY3DPEHPK3PXP")
+	is.True(strings.HasPrefix(uri, "otpauth://totp/Skylab:someone@u.nus.edu?"))
+	is.True(strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP"))
+	is.True(strings.Contains(uri, "issuer=Skylab"))
+}
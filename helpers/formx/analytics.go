@@ -0,0 +1,329 @@
+package formx
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// numberBuckets is the number of bars in the histogram of a number question
+const numberBuckets = 10
+
+// Count is the number of responses that gave a particular answer.
+type Count struct {
+	Value   string
+	Display string
+	Count   int
+	Percent float64 // of the responses that answered the question
+}
+
+// SubquestionStats is the distribution of the answers to one row of a
+// multiradio question.
+type SubquestionStats struct {
+	Name      string
+	Text      string
+	Responses int
+	Counts    []Count
+}
+
+// QuestionStats aggregates every response's answer to a question.
+type QuestionStats struct {
+	Type string
+	Text string
+	Name string
+
+	// Responses is the number of responses that answered the question.
+	// Skipped is the number that did not (including those that did not see
+	// it because of a ShowIf condition).
+	Responses int
+	Skipped   int
+
+	// Counts is the distribution of the answers to a choice or likert
+	// question, or the histogram of a number, date or time question.
+	Counts []Count
+
+	// Subquestions holds the distribution of each row of a multiradio
+	// question.
+	Subquestions []SubquestionStats
+
+	// Summary statistics of a number or likert question
+	Min, Max, Mean, Median float64
+
+	// MeanRanks is the average position (1 being the top) each option of a
+	// ranking question was ranked at, in the same order as Counts.
+	MeanRanks []float64
+
+	// Texts are the answers to a text question
+	Texts []string
+}
+
+// HasSummary reports whether the question has summary statistics.
+func (stats QuestionStats) HasSummary() bool {
+	return (stats.Type == QuestionTypeNumber || stats.Type == QuestionTypeLikert) && stats.Responses > 0
+}
+
+// Analyze aggregates the responses to a form, question by question. Answers
+// written against older versions of the form are mapped to the current
+// question names first (see Questions.Normalize). Paragraphs have nothing to
+// aggregate and are left out.
+func Analyze(questions Questions, responses []Answers) []QuestionStats {
+	normalized := make([]Answers, len(responses))
+	for i, answers := range responses {
+		normalized[i] = questions.Normalize(answers)
+	}
+	var results []QuestionStats
+	for _, question := range questions {
+		if question.Type == QuestionTypeParagraph {
+			continue
+		}
+		stats := QuestionStats{Type: question.Type, Text: question.Text, Name: question.Name}
+		var answers [][]string
+		for _, response := range normalized {
+			if question.Type == QuestionTypeMultiradio {
+				answered := false
+				for _, subquestion := range question.Subquestions {
+					if answerValue(response[subquestion.Name]) != "" {
+						answered = true
+					}
+				}
+				if answered {
+					stats.Responses++
+				}
+				continue
+			}
+			answer := nonEmpty(response[question.Name])
+			if len(answer) == 0 {
+				continue
+			}
+			stats.Responses++
+			answers = append(answers, answer)
+		}
+		stats.Skipped = len(responses) - stats.Responses
+		switch question.Type {
+		case QuestionTypeCheckbox, QuestionTypeSelect, QuestionTypeRadio:
+			stats.Counts = countOptions(question.Options, answers, stats.Responses)
+		case QuestionTypeMultiradio:
+			for _, subquestion := range question.Subquestions {
+				var subanswers [][]string
+				for _, response := range normalized {
+					if answer := nonEmpty(response[subquestion.Name]); len(answer) > 0 {
+						subanswers = append(subanswers, answer[:1])
+					}
+				}
+				stats.Subquestions = append(stats.Subquestions, SubquestionStats{
+					Name:      subquestion.Name,
+					Text:      subquestion.Text,
+					Responses: len(subanswers),
+					Counts:    countOptions(question.Options, subanswers, len(subanswers)),
+				})
+			}
+		case QuestionTypeLikert:
+			scale := scaleOf(QuestionAnswer{Scale: question.Scale})
+			var options []Option
+			for _, point := range likertPoints(QuestionAnswer{Scale: question.Scale}) {
+				display := point
+				switch point {
+				case strconv.Itoa(scale.Min):
+					display = strings.TrimSpace(point + " " + scale.MinLabel)
+				case strconv.Itoa(scale.Max):
+					display = strings.TrimSpace(point + " " + scale.MaxLabel)
+				}
+				options = append(options, Option{Value: point, Display: display})
+			}
+			stats.Counts = countOptions(options, answers, stats.Responses)
+			stats.summarize(numbers(answers))
+		case QuestionTypeNumber:
+			values := numbers(answers)
+			stats.summarize(values)
+			stats.Counts = histogram(values)
+		case QuestionTypeDate, QuestionTypeTime:
+			var values [][]string
+			for _, answer := range answers {
+				value := answer[0]
+				if question.Type == QuestionTypeTime && len(value) >= 2 {
+					value = value[:2] + ":00" // bucket times by the hour
+				}
+				values = append(values, []string{value})
+			}
+			stats.Counts = countValues(values, stats.Responses)
+		case QuestionTypeRanking:
+			stats.Counts, stats.MeanRanks = rankOptions(question.Options, answers, stats.Responses)
+		case QuestionTypeShorttext, QuestionTypeLongtext, QuestionTypeURL, QuestionTypeEmail:
+			for _, answer := range answers {
+				stats.Texts = append(stats.Texts, answer[0])
+			}
+		}
+		results = append(results, stats)
+	}
+	return results
+}
+
+// nonEmpty returns the answer without its blank values.
+func nonEmpty(answer []string) []string {
+	var values []string
+	for _, value := range answer {
+		if strings.TrimSpace(value) != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)*1000/float64(total)) / 10
+}
+
+// countOptions counts how many answers picked each option. Values that are
+// not one of the options (e.g. from an option that has since been removed)
+// are counted after the options.
+func countOptions(options []Option, answers [][]string, total int) []Count {
+	var counts []Count
+	index := make(map[string]int)
+	for _, option := range options {
+		index[option.Value] = len(counts)
+		counts = append(counts, Count{Value: option.Value, Display: option.Display})
+	}
+	var others [][]string
+	for _, answer := range answers {
+		for _, value := range answer {
+			if i, ok := index[value]; ok {
+				counts[i].Count++
+			} else {
+				others = append(others, []string{value})
+			}
+		}
+	}
+	counts = append(counts, countValues(others, total)...)
+	for i := range counts {
+		if counts[i].Display == "" {
+			counts[i].Display = counts[i].Value
+		}
+		counts[i].Percent = percent(counts[i].Count, total)
+	}
+	return counts
+}
+
+// countValues counts each distinct value, sorted by value.
+func countValues(answers [][]string, total int) []Count {
+	seen := make(map[string]int)
+	var counts []Count
+	for _, answer := range answers {
+		for _, value := range answer {
+			if i, ok := seen[value]; ok {
+				counts[i].Count++
+				continue
+			}
+			seen[value] = len(counts)
+			counts = append(counts, Count{Value: value, Display: value, Count: 1})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Value < counts[j].Value })
+	for i := range counts {
+		counts[i].Percent = percent(counts[i].Count, total)
+	}
+	return counts
+}
+
+// numbers returns the answers that are numbers.
+func numbers(answers [][]string) []float64 {
+	var values []float64
+	for _, answer := range answers {
+		value, err := strconv.ParseFloat(strings.TrimSpace(answer[0]), 64)
+		if err == nil && !math.IsNaN(value) && !math.IsInf(value, 0) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (stats *QuestionStats) summarize(values []float64) {
+	if len(values) == 0 {
+		return
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Mean = sum / float64(len(sorted))
+	if n := len(sorted); n%2 == 1 {
+		stats.Median = sorted[n/2]
+	} else {
+		stats.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+}
+
+// histogram divides the range of the values into numberBuckets equal parts
+// and counts the values in each.
+func histogram(values []float64) []Count {
+	if len(values) == 0 {
+		return nil
+	}
+	min, max := values[0], values[0]
+	for _, value := range values {
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+	}
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	if min == max {
+		return []Count{{Value: format(min), Display: format(min), Count: len(values), Percent: 100}}
+	}
+	width := (max - min) / numberBuckets
+	counts := make([]Count, numberBuckets)
+	for i := range counts {
+		lower, upper := min+width*float64(i), min+width*float64(i+1)
+		if i == numberBuckets-1 {
+			upper = max
+		}
+		lower, upper = math.Round(lower*100)/100, math.Round(upper*100)/100
+		counts[i].Value = format(lower)
+		counts[i].Display = fmt.Sprintf("%s to %s", format(lower), format(upper))
+	}
+	for _, value := range values {
+		i := int((value - min) / width)
+		if i >= numberBuckets {
+			i = numberBuckets - 1 // the maximum falls into the last bucket
+		}
+		counts[i].Count++
+	}
+	for i := range counts {
+		counts[i].Percent = percent(counts[i].Count, len(values))
+	}
+	return counts
+}
+
+// rankOptions counts how often each option was ranked first, along with the
+// mean position each option was ranked at.
+func rankOptions(options []Option, answers [][]string, total int) (counts []Count, meanRanks []float64) {
+	counts = make([]Count, len(options))
+	meanRanks = make([]float64, len(options))
+	for i, option := range options {
+		counts[i] = Count{Value: option.Value, Display: option.Display}
+		var sum, n int
+		for _, answer := range answers {
+			for rank, value := range answer {
+				if value == option.Value {
+					sum += rank + 1
+					n++
+					if rank == 0 {
+						counts[i].Count++
+					}
+					break
+				}
+			}
+		}
+		if n > 0 {
+			meanRanks[i] = math.Round(float64(sum)*100/float64(n)) / 100
+		}
+		counts[i].Percent = percent(counts[i].Count, total)
+	}
+	return counts, meanRanks
+}
@@ -0,0 +1,59 @@
+package formx
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestAnalyze(t *testing.T) {
+	is := is.New(t)
+	options := []Option{{Value: "go", Display: "Go"}, {Value: "rust", Display: "Rust"}}
+	questions := Questions{
+		{Type: QuestionTypeParagraph, Text: "Instructions"},
+		{Type: QuestionTypeCheckbox, Name: "langs", Options: options, PreviousNames: []string{"languages"}},
+		{Type: QuestionTypeMultiradio, Options: options, Subquestions: []Subquestion{{Name: "q1", Text: "Q1"}}},
+		{Type: QuestionTypeNumber, Name: "hours"},
+		{Type: QuestionTypeLikert, Name: "happy", Scale: &Scale{Min: 1, Max: 3, MinLabel: "Sad", MaxLabel: "Happy"}},
+		{Type: QuestionTypeRanking, Name: "rank", Options: options},
+		{Type: QuestionTypeLongtext, Name: "comments"},
+	}
+	responses := []Answers{
+		{"languages": {"go", "rust"}, "q1": {"go"}, "hours": {"10"}, "happy": {"3"}, "rank": {"go", "rust"}, "comments": {"Great"}},
+		{"langs": {"go", "cobol"}, "hours": {"20"}, "happy": {"1"}, "rank": {"rust", "go"}},
+		{"langs": {""}, "hours": {"30"}, "happy": {"3"}, "rank": {"go", "rust"}, "comments": {" "}},
+	}
+	stats := Analyze(questions, responses)
+	is.Equal(len(stats), 6) // the paragraph is left out
+
+	langs := stats[0]
+	is.Equal(langs.Responses, 2) // the answer stored under the previous name counts
+	is.Equal(langs.Skipped, 1)
+	is.Equal(langs.Counts, []Count{
+		{Value: "go", Display: "Go", Count: 2, Percent: 100},
+		{Value: "rust", Display: "Rust", Count: 1, Percent: 50},
+		{Value: "cobol", Display: "cobol", Count: 1, Percent: 50},
+	})
+
+	is.Equal(stats[1].Responses, 1)
+	is.Equal(stats[1].Subquestions[0].Counts[0].Count, 1)
+
+	hours := stats[2]
+	is.Equal(hours.Mean, 20.0)
+	is.Equal(hours.Median, 20.0)
+	is.Equal(len(hours.Counts), numberBuckets)
+	is.Equal(hours.Counts[0].Count, 1)
+	is.Equal(hours.Counts[5].Count, 1)
+	is.Equal(hours.Counts[numberBuckets-1].Count, 1)
+
+	happy := stats[3]
+	is.Equal(happy.Counts[0].Display, "1 Sad")
+	is.Equal(happy.Counts[2].Count, 2)
+	is.True(happy.HasSummary())
+
+	rank := stats[4]
+	is.Equal(rank.Counts[0].Count, 2) // go was ranked first twice
+	is.Equal(rank.MeanRanks, []float64{1.33, 1.67})
+
+	is.Equal(stats[5].Texts, []string{"Great"})
+}
@@ -0,0 +1,104 @@
+package formx
+
+// Change is a question whose answer differs between two sets of answers to
+// the same questions, e.g. two revisions of a submission. Before and After are
+// the values as they should be displayed (the Display of an option instead of
+// its Value).
+type Change struct {
+	Name   string
+	Text   string
+	Type   string
+	Before []string
+	After  []string
+}
+
+// Diff returns the questions (in order) whose answer in after differs from
+// the answer in before. Subquestions of a multiradio question are compared
+// individually. Blank values are ignored, so a question left blank and a
+// question that was never answered are the same.
+func Diff(questions Questions, before, after Answers) []Change {
+	before, after = questions.Normalize(before), questions.Normalize(after)
+	var changes []Change
+	compare := func(name, text, questionType string, options []Option) {
+		a, b := nonEmpty(before[name]), nonEmpty(after[name])
+		if sameValues(a, b) {
+			return
+		}
+		changes = append(changes, Change{
+			Name:   name,
+			Text:   text,
+			Type:   questionType,
+			Before: displayValues(options, a),
+			After:  displayValues(options, b),
+		})
+	}
+	for _, question := range questions {
+		switch question.Type {
+		case QuestionTypeParagraph:
+			continue
+		case QuestionTypeMultiradio:
+			for _, subquestion := range question.Subquestions {
+				compare(subquestion.Name, question.Text+": "+subquestion.Text, QuestionTypeRadio, question.Options)
+			}
+		default:
+			compare(question.Name, question.Text, question.Type, question.Options)
+		}
+	}
+	return changes
+}
+
+// Conflict is a question that has been answered differently in two copies of
+// the same answers, e.g. when two students of a team edit their submission at
+// the same time.
+type Conflict struct {
+	Name   string
+	Text   string
+	Type   string
+	Yours  []string
+	Theirs []string
+}
+
+// Conflicts returns the questions (in order) whose answer in yours differs
+// from the answer in theirs, see Diff.
+func Conflicts(questions Questions, yours, theirs Answers) []Conflict {
+	var conflicts []Conflict
+	for _, change := range Diff(questions, theirs, yours) {
+		conflicts = append(conflicts, Conflict{
+			Name:   change.Name,
+			Text:   change.Text,
+			Type:   change.Type,
+			Yours:  change.After,
+			Theirs: change.Before,
+		})
+	}
+	return conflicts
+}
+
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// displayValues replaces every value that is one of the options with the
+// option's Display.
+func displayValues(options []Option, values []string) []string {
+	var displays []string
+	for _, value := range values {
+		display := value
+		for _, option := range options {
+			if option.Value == value && option.Display != "" {
+				display = option.Display
+				break
+			}
+		}
+		displays = append(displays, display)
+	}
+	return displays
+}
@@ -0,0 +1,40 @@
+package formx
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestConflicts(t *testing.T) {
+	is := is.New(t)
+	options := []Option{{Value: "go", Display: "Go"}, {Value: "rust", Display: "Rust"}}
+	questions := Questions{
+		{Type: QuestionTypeParagraph, Text: "Instructions"},
+		{Type: QuestionTypeShorttext, Name: "title", Text: "Title", PreviousNames: []string{"name"}},
+		{Type: QuestionTypeCheckbox, Name: "langs", Text: "Languages", Options: options},
+		{Type: QuestionTypeMultiradio, Text: "Rate", Options: options, Subquestions: []Subquestion{{Name: "q1", Text: "Q1"}, {Name: "q2", Text: "Q2"}}},
+		{Type: QuestionTypeLongtext, Name: "comments", Text: "Comments"},
+	}
+	yours := Answers{"title": {"Skylab"}, "langs": {"go"}, "q1": {"go"}, "q2": {"rust"}, "comments": {""}}
+	theirs := Answers{"name": {"Skylab"}, "langs": {"go", "rust"}, "q1": {"go"}, "q2": {"go"}}
+	is.Equal(Conflicts(questions, yours, theirs), []Conflict{
+		{Name: "langs", Text: "Languages", Type: QuestionTypeCheckbox, Yours: []string{"Go"}, Theirs: []string{"Go", "Rust"}},
+		{Name: "q2", Text: "Rate: Q2", Type: QuestionTypeRadio, Yours: []string{"Rust"}, Theirs: []string{"Go"}},
+	})
+	is.Equal(len(Conflicts(questions, yours, yours)), 0)
+}
+
+func TestDiff(t *testing.T) {
+	is := is.New(t)
+	questions := Questions{
+		{Type: QuestionTypeLongtext, Name: "log", Text: "Project log"},
+		{Type: QuestionTypeURL, Name: "poster", Text: "Poster"},
+	}
+	before := Answers{"log": {"Week 1"}, "poster": {"https://example.com/poster.png"}}
+	after := Answers{"log": {""}, "poster": {"https://example.com/poster.png"}}
+	is.Equal(Diff(questions, before, after), []Change{
+		{Name: "log", Text: "Project log", Type: QuestionTypeLongtext, Before: []string{"Week 1"}},
+	})
+	is.Equal(len(Diff(questions, after, after)), 0)
+}
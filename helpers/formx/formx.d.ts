@@ -11,6 +11,11 @@ export enum Type {
   Date = "date",
   Time = "time",
   Image = "image",
+  Number = "number",
+  Likert = "likert",
+  URL = "url",
+  Email = "email",
+  Ranking = "ranking",
   // Null = "",
 }
 
@@ -28,6 +33,36 @@ export interface IOption {
 export interface ISubquestion {
   Name: string;
   Text: string;
+  PreviousNames?: Array<string>;
+}
+
+// ICondition makes a question only show up if the answer to the question
+// named Name contains any of Values.
+export interface ICondition {
+  Name: string;
+  Values: Array<string>;
+}
+
+// IValidation holds the rules that an answer must satisfy before the form can
+// be submitted. Missing fields mean the rule is not enforced.
+export interface IValidation {
+  Required?: boolean;
+  MinLength?: number;
+  MaxLength?: number;
+  MinSelections?: number;
+  MaxSelections?: number;
+  Pattern?: string;
+  Format?: "" | "url" | "email";
+  Min?: number;
+  Max?: number;
+}
+
+// IScale is the range of a likert question, with optional endpoint labels.
+export interface IScale {
+  Min: number;
+  Max: number;
+  MinLabel: string;
+  MaxLabel: string;
 }
 
 export interface IQuestion {
@@ -36,12 +71,17 @@ export interface IQuestion {
   Name?: string;
   Options?: Array<IOption>;
   Subquestions?: Array<ISubquestion>;
+  ShowIf?: ICondition;
+  Validation?: IValidation;
+  Scale?: IScale;
+  PreviousNames?: Array<string>;
 }
 
 export interface ISubquestionAnswer {
   Name: string;
   Text: string;
   Answer?: Array<string>;
+  PreviousNames?: Array<string>;
 }
 
 export interface IQuestionAnswer {
@@ -51,6 +91,12 @@ export interface IQuestionAnswer {
   Options?: Array<IOption>;
   Subquestions?: Array<ISubquestionAnswer>;
   Answer?: Array<string>;
+  ShowIf?: ICondition;
+  Hidden?: boolean;
+  Validation?: IValidation;
+  Error?: string;
+  Scale?: IScale;
+  PreviousNames?: Array<string>;
 }
 
 export interface INode {
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -20,16 +21,61 @@ type Option struct {
 }
 
 type Subquestion struct {
-	Name string `json:"Name"`
-	Text string `json:"Text"`
+	Name          string   `json:"Name"`
+	Text          string   `json:"Text"`
+	PreviousNames []string `json:"PreviousNames,omitempty"` // see Questions.Normalize
 }
 
+// Condition makes a question only show up if the answer to another question
+// (identified by its Name) contains any of Values, e.g. only asking applicants
+// to describe their prior experience if they chose Artemis.
+type Condition struct {
+	Name   string   `json:"Name"`
+	Values []string `json:"Values"`
+}
+
+// Met reports whether the answers satisfy the condition. A nil condition is
+// always met.
+func (cond *Condition) Met(answers Answers) bool {
+	if cond == nil {
+		return true
+	}
+	for _, answer := range answers[cond.Name] {
+		for _, value := range cond.Values {
+			if answer == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Scale is the range of a likert (linear scale) question, with optional
+// labels for its endpoints e.g. 1 "Strongly disagree" to 5 "Strongly agree".
+type Scale struct {
+	Min      int    `json:"Min"`
+	Max      int    `json:"Max"`
+	MinLabel string `json:"MinLabel"`
+	MaxLabel string `json:"MaxLabel"`
+}
+
+// DefaultScale is used for likert questions that do not specify a Scale.
+var DefaultScale = Scale{Min: 1, Max: 5}
+
 type Question struct {
 	Type         string        `json:"Type"`
 	Text         string        `json:"Text"`
 	Name         string        `json:"Name"`
 	Options      []Option      `json:"Options"`
 	Subquestions []Subquestion `json:"Subquestions"`
+	ShowIf       *Condition    `json:"ShowIf,omitempty"`
+	Validation   *Validation   `json:"Validation,omitempty"`
+	Scale        *Scale        `json:"Scale,omitempty"` // likert only
+
+	// PreviousNames are the names the question had in earlier versions of
+	// the form, so that answers stored under an old name are not orphaned
+	// when the question is renamed. See Questions.Normalize.
+	PreviousNames []string `json:"PreviousNames,omitempty"`
 }
 
 func (question Question) Value() (driver.Value, error) {
@@ -74,6 +120,52 @@ func (questions *Questions) Scan(value interface{}) error {
 	return err
 }
 
+// Shown reports which questions (by Name) are shown given the answers. A
+// question is shown if it has no condition, or if its condition is met and
+// the question it depends on is itself shown. A condition on a subquestion
+// depends on the question the subquestion belongs to. Questions without a Name
+// (e.g. paragraphs) are keyed by their index in questions instead.
+func (questions Questions) Shown(answers Answers) map[string]bool {
+	byName := make(map[string]Question)
+	for _, question := range questions {
+		if question.Name != "" {
+			byName[question.Name] = question
+		}
+		for _, subquestion := range question.Subquestions {
+			if subquestion.Name != "" {
+				byName[subquestion.Name] = question
+			}
+		}
+	}
+	var isShown func(question Question, seen map[string]bool) bool
+	isShown = func(question Question, seen map[string]bool) bool {
+		if question.ShowIf == nil {
+			return true
+		}
+		if !question.ShowIf.Met(answers) {
+			return false
+		}
+		dependency, ok := byName[question.ShowIf.Name]
+		if !ok || seen[dependency.Name] {
+			return true // unknown or circular dependencies are treated as shown
+		}
+		seen[dependency.Name] = true
+		return isShown(dependency, seen)
+	}
+	shown := make(map[string]bool)
+	for i, question := range questions {
+		shown[questionKey(i, question)] = isShown(question, map[string]bool{question.Name: true})
+	}
+	return shown
+}
+
+func questionKey(i int, question Question) string {
+	if question.Name != "" {
+		return question.Name
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
 type Answers map[string][]string
 
 func (answers Answers) Value() (driver.Value, error) {
@@ -104,9 +196,10 @@ func (answers Answers) IsEmpty() bool {
 }
 
 type SubquestionAnswer struct {
-	Name   string `json:"Name"`
-	Text   string `json:"Text"`
-	Answer string `json:"Answer"`
+	Name          string   `json:"Name"`
+	Text          string   `json:"Text"`
+	Answer        string   `json:"Answer"`
+	PreviousNames []string `json:"PreviousNames,omitempty"`
 }
 
 type QuestionAnswer struct {
@@ -116,6 +209,12 @@ type QuestionAnswer struct {
 	Options            []Option            `json:"Options"`
 	SubquestionAnswers []SubquestionAnswer `json:"SubquestionAnswers"`
 	Answer             []string            `json:"Answer"`
+	ShowIf             *Condition          `json:"ShowIf,omitempty"`
+	Hidden             bool                `json:"Hidden"` // whether the question's ShowIf condition is not met by the answers
+	Validation         *Validation         `json:"Validation,omitempty"`
+	Error              string              `json:"Error,omitempty"` // set by WithErrors
+	Scale              *Scale              `json:"Scale,omitempty"`
+	PreviousNames      []string            `json:"PreviousNames,omitempty"`
 }
 
 const (
@@ -129,6 +228,11 @@ const (
 	QuestionTypeDate       = "date"
 	QuestionTypeTime       = "time"
 	QuestionTypeImage      = "image"
+	QuestionTypeNumber     = "number"
+	QuestionTypeLikert     = "likert"
+	QuestionTypeURL        = "url"
+	QuestionTypeEmail      = "email"
+	QuestionTypeRanking    = "ranking"
 	QuestionTypeNull       = ""
 )
 
@@ -146,6 +250,11 @@ func addQuestionTypes(funcs template.FuncMap) template.FuncMap {
 	funcs["QuestionTypeDate"] = func() string { return QuestionTypeDate }
 	funcs["QuestionTypeTime"] = func() string { return QuestionTypeTime }
 	funcs["QuestionTypeImage"] = func() string { return QuestionTypeImage }
+	funcs["QuestionTypeNumber"] = func() string { return QuestionTypeNumber }
+	funcs["QuestionTypeLikert"] = func() string { return QuestionTypeLikert }
+	funcs["QuestionTypeURL"] = func() string { return QuestionTypeURL }
+	funcs["QuestionTypeEmail"] = func() string { return QuestionTypeEmail }
+	funcs["QuestionTypeRanking"] = func() string { return QuestionTypeRanking }
 	return funcs
 }
 
@@ -163,6 +272,15 @@ func Funcs(funcs template.FuncMap, policy *bluemonday.Policy) template.FuncMap {
 	funcs["FormxCheckboxAnswers"] = CheckboxAnswers
 	funcs["FormxRadioSelectAnswers"] = RadioSelectAnswers
 	funcs["FormxMultiradioAnswers"] = MultiradioAnswers
+	funcs["FormxLikertAnswer"] = LikertAnswer
+	funcs["FormxLikertPoints"] = likertPoints
+	funcs["FormxRankingAnswers"] = RankingAnswers
+	funcs["FormxRankingOptions"] = rankingOptions
+	funcs["FormxNumberMin"] = numberMin
+	funcs["FormxNumberMax"] = numberMax
+	funcs["FormxJSON"] = toJSON
+	funcs["FormxWithErrors"] = WithErrors
+	funcs["FormxMaxLength"] = maxLength
 	return funcs
 }
 
@@ -172,18 +290,28 @@ func idfy(inputs ...string) (output string) {
 	return output[0:8]
 }
 
+// MergeQuestionsAnswers pairs each question with its answer. Questions whose
+// ShowIf condition is not met by the answers are marked as Hidden.
 func MergeQuestionsAnswers(questions Questions, answers Answers) []QuestionAnswer {
 	var qas []QuestionAnswer
-	for _, question := range questions {
+	answers = questions.Normalize(answers)
+	shown := questions.Shown(answers)
+	for i, question := range questions {
 		var qa QuestionAnswer
 		qa.Type = question.Type
 		qa.Text = question.Text
 		qa.Name = question.Name
 		qa.Options = append([]Option{}, question.Options...)
+		qa.ShowIf = question.ShowIf
+		qa.Validation = question.Validation
+		qa.Scale = question.Scale
+		qa.PreviousNames = question.PreviousNames
+		qa.Hidden = !shown[questionKey(i, question)]
 		for _, subquestion := range question.Subquestions {
 			var subqa SubquestionAnswer
 			subqa.Name = subquestion.Name
 			subqa.Text = subquestion.Text
+			subqa.PreviousNames = subquestion.PreviousNames
 			answer := answers[subquestion.Name]
 			if len(answer) > 0 {
 				subqa.Answer = answer[0]
@@ -197,6 +325,18 @@ func MergeQuestionsAnswers(questions Questions, answers Answers) []QuestionAnswe
 	return qas
 }
 
+// maxLength returns the maximum length of a text answer to the question,
+// falling back to the defaults if the question does not set one.
+func maxLength(qa QuestionAnswer) int {
+	if qa.Validation != nil && qa.Validation.MaxLength > 0 {
+		return qa.Validation.MaxLength
+	}
+	if qa.Type == QuestionTypeLongtext {
+		return DefaultMaxLengthLongtext
+	}
+	return DefaultMaxLengthShorttext
+}
+
 func answerValue(answer []string) string {
 	if len(answer) > 0 {
 		return answer[0]
@@ -214,6 +354,10 @@ func answersContainValue(answer []string, value string) bool {
 	return false
 }
 
+// ExtractAnswers picks out the answers to questions from the form values.
+// Answers to questions that are hidden (because their ShowIf condition is not
+// met) are dropped, so that stale answers from before the user changed their
+// mind are not kept.
 func ExtractAnswers(form url.Values, questions []Question) (answers Answers) {
 	allAnswers := make(map[string][]string)
 	for name, values := range form {
@@ -226,10 +370,39 @@ func ExtractAnswers(form url.Values, questions []Question) (answers Answers) {
 			for _, subqn := range qn.Subquestions {
 				answers[subqn.Name] = allAnswers[subqn.Name]
 			}
+		case QuestionTypeNumber, QuestionTypeURL, QuestionTypeEmail:
+			for _, value := range allAnswers[qn.Name] {
+				answers[qn.Name] = append(answers[qn.Name], strings.TrimSpace(value))
+			}
+		case QuestionTypeRanking:
+			// The order of the values is the ranking. Only the first occurrence
+			// of each option counts.
+			seen := make(map[string]bool)
+			for _, value := range allAnswers[qn.Name] {
+				if seen[value] || !hasOption(qn.Options, value) {
+					continue
+				}
+				seen[value] = true
+				answers[qn.Name] = append(answers[qn.Name], value)
+			}
 		default:
 			answers[qn.Name] = allAnswers[qn.Name]
 		}
 	}
+	shown := Questions(questions).Shown(answers)
+	for i, qn := range questions {
+		if shown[questionKey(i, qn)] {
+			continue
+		}
+		switch qn.Type {
+		case QuestionTypeMultiradio:
+			for _, subqn := range qn.Subquestions {
+				answers[subqn.Name] = nil
+			}
+		default:
+			answers[qn.Name] = nil
+		}
+	}
 	return answers
 }
 
@@ -241,6 +414,11 @@ func SanitizeHTML(policy *bluemonday.Policy) func(string) template.HTML {
 	}
 }
 
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
 func JoinSlice(slice []string) string {
 	return strings.Join(slice, ", ")
 }
@@ -274,3 +452,106 @@ func MultiradioAnswers(qna QuestionAnswer, subqna SubquestionAnswer) string {
 	}
 	return display[subqna.Answer]
 }
+
+// maxScalePoints stops a mistyped scale from rendering thousands of radio
+// buttons.
+const maxScalePoints = 100
+
+func scaleOf(qna QuestionAnswer) Scale {
+	if qna.Scale != nil && qna.Scale.Max > qna.Scale.Min && qna.Scale.Max-qna.Scale.Min < maxScalePoints {
+		return *qna.Scale
+	}
+	return DefaultScale
+}
+
+// likertPoints returns every point on the question's scale, in order.
+func likertPoints(qna QuestionAnswer) []string {
+	scale := scaleOf(qna)
+	points := make([]string, 0, scale.Max-scale.Min+1)
+	for i := scale.Min; i <= scale.Max; i++ {
+		points = append(points, strconv.Itoa(i))
+	}
+	return points
+}
+
+// LikertAnswer displays the point chosen on a likert question together with
+// the scale e.g. "4 (on a scale of 1 to 5)". If the point is an endpoint with
+// a label, the label is shown as well e.g. "5, Strongly agree (on a scale of 1
+// to 5)".
+func LikertAnswer(qna QuestionAnswer) string {
+	value := answerValue(qna.Answer)
+	if value == "" {
+		return ""
+	}
+	scale := scaleOf(qna)
+	var label string
+	switch value {
+	case strconv.Itoa(scale.Min):
+		label = scale.MinLabel
+	case strconv.Itoa(scale.Max):
+		label = scale.MaxLabel
+	}
+	if label != "" {
+		value += ", " + label
+	}
+	return fmt.Sprintf("%s (on a scale of %d to %d)", value, scale.Min, scale.Max)
+}
+
+// rankedOptions returns the options of a ranking question that have been
+// ranked, in the order that they were ranked.
+func rankedOptions(qna QuestionAnswer) []Option {
+	byValue := make(map[string]Option)
+	for _, opt := range qna.Options {
+		byValue[opt.Value] = opt
+	}
+	var options []Option
+	ranked := make(map[string]bool)
+	for _, value := range qna.Answer {
+		if opt, ok := byValue[value]; ok && !ranked[value] {
+			ranked[value] = true
+			options = append(options, opt)
+		}
+	}
+	return options
+}
+
+// rankingOptions returns the options of a ranking question in the order that
+// they were ranked, followed by any options that have not been ranked yet.
+func rankingOptions(qna QuestionAnswer) []Option {
+	options := rankedOptions(qna)
+	ranked := make(map[string]bool)
+	for _, opt := range options {
+		ranked[opt.Value] = true
+	}
+	for _, opt := range qna.Options {
+		if !ranked[opt.Value] {
+			options = append(options, opt)
+		}
+	}
+	return options
+}
+
+// RankingAnswers displays the options of a ranking question in the order
+// that they were ranked e.g. "1. Go, 2. Rust, 3. Java". Options that have not
+// been ranked are left out.
+func RankingAnswers(qna QuestionAnswer) string {
+	var displays []string
+	for i, opt := range rankedOptions(qna) {
+		displays = append(displays, fmt.Sprintf("%d. %s", i+1, opt.Display))
+	}
+	return JoinSlice(displays)
+}
+
+func numberMin(qna QuestionAnswer) string {
+	if qna.Validation == nil || qna.Validation.Min == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*qna.Validation.Min, 'f', -1, 64)
+}
+
+func numberMax(qna QuestionAnswer) string {
+	if qna.Validation == nil || qna.Validation.Max == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*qna.Validation.Max, 'f', -1, 64)
+}
@@ -0,0 +1,123 @@
+package formx
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func conditionalQuestions() Questions {
+	return Questions{
+		{Type: QuestionTypeParagraph, Text: "Tell us about yourself"},
+		{Type: QuestionTypeRadio, Name: "level", Options: []Option{{Display: "Vostok", Value: "vostok"}, {Display: "Artemis", Value: "artemis"}}},
+		{Type: QuestionTypeLongtext, Name: "experience", ShowIf: &Condition{Name: "level", Values: []string{"artemis"}}},
+		{Type: QuestionTypeShorttext, Name: "repo", ShowIf: &Condition{Name: "experience", Values: []string{"yes"}}},
+	}
+}
+
+func TestShown(t *testing.T) {
+	is := is.New(t)
+	questions := conditionalQuestions()
+
+	shown := questions.Shown(Answers{"level": {"vostok"}})
+	is.True(shown["#0"])
+	is.True(shown["level"])
+	is.True(!shown["experience"])
+	is.True(!shown["repo"])
+
+	shown = questions.Shown(Answers{"level": {"artemis"}, "experience": {"yes"}})
+	is.True(shown["experience"])
+	is.True(shown["repo"])
+
+	// repo depends on experience, which is hidden, so repo is hidden too even
+	// though the stale answer to experience matches
+	shown = questions.Shown(Answers{"level": {"vostok"}, "experience": {"yes"}})
+	is.True(!shown["repo"])
+}
+
+func TestShownSubquestion(t *testing.T) {
+	is := is.New(t)
+	yes := []Option{{Value: "yes"}, {Value: "no"}}
+	questions := Questions{
+		{Type: QuestionTypeRadio, Name: "student", Options: yes},
+		{
+			Type: QuestionTypeMultiradio, Name: "skills", Options: yes,
+			Subquestions: []Subquestion{{Name: "skills_go"}, {Name: "skills_rust"}},
+			ShowIf:       &Condition{Name: "student", Values: []string{"yes"}},
+		},
+		{Type: QuestionTypeShorttext, Name: "go_project", ShowIf: &Condition{Name: "skills_go", Values: []string{"yes"}}},
+		{Type: QuestionTypeShorttext, Name: "mystery", ShowIf: &Condition{Name: "unknown", Values: []string{"yes"}}},
+	}
+
+	shown := questions.Shown(Answers{"student": {"yes"}, "skills_go": {"yes"}, "unknown": {"yes"}})
+	is.True(shown["go_project"])
+	is.True(shown["mystery"]) // unknown dependencies are treated as shown
+
+	shown = questions.Shown(Answers{"student": {"yes"}, "skills_go": {"no"}})
+	is.True(!shown["go_project"])
+
+	// skills_go belongs to skills, which is hidden
+	shown = questions.Shown(Answers{"student": {"no"}, "skills_go": {"yes"}})
+	is.True(!shown["go_project"])
+}
+
+func TestExtractAnswersDropsHidden(t *testing.T) {
+	is := is.New(t)
+	form := url.Values{"level": {"vostok"}, "experience": {"yes"}, "repo": {"github.com/example"}}
+	answers := ExtractAnswers(form, conditionalQuestions())
+	is.Equal(answers["level"], []string{"vostok"})
+	is.Equal(answers["experience"], []string(nil))
+	is.Equal(answers["repo"], []string(nil))
+
+	qas := MergeQuestionsAnswers(conditionalQuestions(), answers)
+	is.True(!qas[1].Hidden)
+	is.True(qas[2].Hidden)
+	is.True(qas[3].Hidden)
+}
+
+func TestNewQuestionTypes(t *testing.T) {
+	is := is.New(t)
+	options := []Option{{Value: "go", Display: "Go"}, {Value: "rust", Display: "Rust"}, {Value: "java", Display: "Java"}}
+	min, max := 0.0, 10.0
+	questions := Questions{
+		{Type: QuestionTypeNumber, Name: "hours", Validation: &Validation{Min: &min, Max: &max}},
+		{Type: QuestionTypeLikert, Name: "happy", Scale: &Scale{Min: 1, Max: 7, MinLabel: "Not at all", MaxLabel: "Very"}},
+		{Type: QuestionTypeURL, Name: "repo"},
+		{Type: QuestionTypeEmail, Name: "email"},
+		{Type: QuestionTypeRanking, Name: "langs", Options: options, Validation: &Validation{Required: true}},
+	}
+	form := url.Values{
+		"hours": {" 7.5 "},
+		"happy": {"7"},
+		"repo":  {"https://github.com/bokwoon95/nusskylabx"},
+		"email": {"someone@u.nus.edu "},
+		"langs": {"rust", "go", "rust", "cobol", "java"},
+	}
+	answers := ExtractAnswers(form, questions)
+	is.Equal(answers["hours"], []string{"7.5"})
+	is.Equal(answers["email"], []string{"someone@u.nus.edu"})
+	is.Equal(answers["langs"], []string{"rust", "go", "java"}) // duplicates and non-options dropped, order kept
+	is.Equal(Validate(questions, answers), nil)
+
+	qas := MergeQuestionsAnswers(questions, answers)
+	is.Equal(LikertAnswer(qas[1]), "7, Very (on a scale of 1 to 7)")
+	is.Equal(RankingAnswers(qas[4]), "1. Rust, 2. Go, 3. Java")
+	partial := MergeQuestionsAnswers(questions, Answers{"langs": {"java"}})
+	is.Equal(RankingAnswers(partial[4]), "1. Java") // unranked options are left out
+	is.Equal(likertPoints(qas[1]), []string{"1", "2", "3", "4", "5", "6", "7"})
+
+	errs := Validate(questions, Answers{
+		"hours": {"11"},
+		"happy": {"0"},
+		"repo":  {"not a url"},
+		"email": {"@"},
+		"langs": {"go"},
+	})
+	is.Equal(errs["hours"], "Must be at most 10")
+	is.Equal(errs["happy"], "Must be a point on the scale from 1 to 7")
+	is.True(errs["repo"] != "")
+	is.True(errs["email"] != "")
+	is.Equal(errs["langs"], "Rank all of the options")
+	is.Equal(Validate(questions, Answers{"hours": {"abc"}})["hours"], "Must be a number")
+}
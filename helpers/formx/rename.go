@@ -0,0 +1,220 @@
+package formx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Names returns the names that answers to the questions are stored under, i.e.
+// the Name of every question and subquestion that has one.
+func (questions Questions) Names() []string {
+	var names []string
+	for _, question := range questions {
+		if question.Name != "" {
+			names = append(names, question.Name)
+		}
+		for _, subquestion := range question.Subquestions {
+			if subquestion.Name != "" {
+				names = append(names, subquestion.Name)
+			}
+		}
+	}
+	return names
+}
+
+// knownNames returns every name that answers to the questions may be stored
+// under, including the PreviousNames.
+func (questions Questions) knownNames() map[string]bool {
+	known := make(map[string]bool)
+	for _, name := range questions.Names() {
+		known[name] = true
+	}
+	for _, question := range questions {
+		for _, name := range question.PreviousNames {
+			known[name] = true
+		}
+		for _, subquestion := range question.Subquestions {
+			for _, name := range subquestion.PreviousNames {
+				known[name] = true
+			}
+		}
+	}
+	return known
+}
+
+// Normalize returns a copy of the answers where answers stored under a
+// previous name of a question are moved to its current name. Answers written
+// against an older version of the form can then be displayed and validated
+// against the current version. If there is already an answer under the
+// current name, it wins.
+func (questions Questions) Normalize(answers Answers) Answers {
+	normalized := make(Answers, len(answers))
+	for name, answer := range answers {
+		normalized[name] = answer
+	}
+	move := func(name string, previousNames []string) {
+		if name == "" || len(normalized[name]) > 0 {
+			return
+		}
+		for _, previousName := range previousNames {
+			if answer := normalized[previousName]; len(answer) > 0 {
+				normalized[name] = answer
+				delete(normalized, previousName)
+				return
+			}
+		}
+	}
+	for _, question := range questions {
+		move(question.Name, question.PreviousNames)
+		for _, subquestion := range question.Subquestions {
+			move(subquestion.Name, subquestion.PreviousNames)
+		}
+	}
+	return normalized
+}
+
+// Orphans returns the names (sorted) that answers to the old questions may be
+// stored under but which the new questions no longer know about, either as a
+// current or a previous name. Answers stored under these names would no
+// longer show up anywhere once the form is changed from old to new.
+func Orphans(old, new Questions) []string {
+	known := new.knownNames()
+	var orphans []string
+	for name := range old.knownNames() {
+		if !known[name] {
+			orphans = append(orphans, name)
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// CarryPreviousNames returns a copy of the new questions where every question
+// and subquestion that kept its name also keeps the PreviousNames it had in
+// the old questions, in case they were lost while editing the form.
+func CarryPreviousNames(old, new Questions) Questions {
+	previousNames := make(map[string][]string)
+	for _, question := range old {
+		if question.Name != "" {
+			previousNames[question.Name] = question.PreviousNames
+		}
+		for _, subquestion := range question.Subquestions {
+			if subquestion.Name != "" {
+				previousNames[subquestion.Name] = subquestion.PreviousNames
+			}
+		}
+	}
+	questions := new.clone()
+	for i := range questions {
+		questions[i].PreviousNames = union(questions[i].PreviousNames, previousNames[questions[i].Name])
+		for j := range questions[i].Subquestions {
+			subquestion := &questions[i].Subquestions[j]
+			subquestion.PreviousNames = union(subquestion.PreviousNames, previousNames[subquestion.Name])
+		}
+	}
+	return questions
+}
+
+// Rename returns a copy of the questions where each old name in renames (old
+// name => new name) is recorded as a previous name of the question or
+// subquestion currently named new name.
+func (questions Questions) Rename(renames map[string]string) (Questions, error) {
+	questions = questions.clone()
+	current := make(map[string]bool)
+	for _, name := range questions.Names() {
+		current[name] = true
+	}
+	oldNames := make([]string, 0, len(renames))
+	for oldName := range renames {
+		oldNames = append(oldNames, oldName)
+	}
+	sort.Strings(oldNames)
+	for _, oldName := range oldNames {
+		newName := renames[oldName]
+		if current[oldName] {
+			return nil, fmt.Errorf("cannot rename %q to %q: %q is still the name of a question", oldName, newName, oldName)
+		}
+		if !current[newName] {
+			return nil, fmt.Errorf("cannot rename %q to %q: there is no question named %q", oldName, newName, newName)
+		}
+		for i := range questions {
+			if questions[i].Name == newName {
+				questions[i].PreviousNames = union(questions[i].PreviousNames, []string{oldName})
+			}
+			for j := range questions[i].Subquestions {
+				if questions[i].Subquestions[j].Name == newName {
+					questions[i].Subquestions[j].PreviousNames = union(questions[i].Subquestions[j].PreviousNames, []string{oldName})
+				}
+			}
+		}
+	}
+	return questions, nil
+}
+
+// SuggestRenames guesses which new question each orphaned name (see Orphans)
+// was renamed to: if the question at the same position in the new questions
+// has the same type and a name that did not exist in the old questions, it is
+// most likely the same question under a new name.
+func SuggestRenames(old, new Questions, orphans []string) map[string]string {
+	isOrphan := make(map[string]bool)
+	for _, name := range orphans {
+		isOrphan[name] = true
+	}
+	oldKnown := old.knownNames()
+	suggestions := make(map[string]string)
+	for i, question := range old {
+		if i >= len(new) || new[i].Type != question.Type {
+			continue
+		}
+		if isOrphan[question.Name] && new[i].Name != "" && !oldKnown[new[i].Name] {
+			suggestions[question.Name] = new[i].Name
+		}
+		for j, subquestion := range question.Subquestions {
+			if j >= len(new[i].Subquestions) {
+				break
+			}
+			newName := new[i].Subquestions[j].Name
+			if isOrphan[subquestion.Name] && newName != "" && !oldKnown[newName] {
+				suggestions[subquestion.Name] = newName
+			}
+		}
+	}
+	return suggestions
+}
+
+// clone returns a copy of the questions that can be modified without
+// affecting the original.
+func (questions Questions) clone() Questions {
+	if questions == nil {
+		return nil
+	}
+	cloned := make(Questions, len(questions))
+	for i, question := range questions {
+		question.PreviousNames = append([]string(nil), question.PreviousNames...)
+		if question.Subquestions != nil {
+			question.Subquestions = append(make([]Subquestion, 0, len(question.Subquestions)), question.Subquestions...)
+		}
+		for j := range question.Subquestions {
+			question.Subquestions[j].PreviousNames = append([]string(nil), question.Subquestions[j].PreviousNames...)
+		}
+		cloned[i] = question
+	}
+	return cloned
+}
+
+// union returns the names in a followed by the names in b that are not in a.
+func union(a, b []string) []string {
+	for _, name := range b {
+		found := false
+		for _, existing := range a {
+			if existing == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, name)
+		}
+	}
+	return a
+}
@@ -0,0 +1,52 @@
+package formx
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRename(t *testing.T) {
+	is := is.New(t)
+	old := Questions{
+		{Type: QuestionTypeShorttext, Name: "team_name"},
+		{Type: QuestionTypeLongtext, Name: "idea"},
+		{Type: QuestionTypeMultiradio, Subquestions: []Subquestion{{Name: "q1"}, {Name: "q2"}}},
+		{Type: QuestionTypeShorttext, Name: "poster"},
+	}
+	new := Questions{
+		{Type: QuestionTypeShorttext, Name: "team_name"},
+		{Type: QuestionTypeLongtext, Name: "project_idea"},
+		{Type: QuestionTypeMultiradio, Subquestions: []Subquestion{{Name: "q1"}, {Name: "question_2"}}},
+	}
+
+	orphans := Orphans(old, new)
+	is.Equal(orphans, []string{"idea", "poster", "q2"})
+	is.Equal(SuggestRenames(old, new, orphans), map[string]string{"idea": "project_idea", "q2": "question_2"})
+
+	renamed, err := new.Rename(map[string]string{"idea": "project_idea", "q2": "question_2"})
+	is.NoErr(err)
+	is.Equal(renamed[1].PreviousNames, []string{"idea"})
+	is.Equal(renamed[2].Subquestions[1].PreviousNames, []string{"q2"})
+	is.Equal(new[1].PreviousNames, []string(nil)) // the original is untouched
+	is.Equal(Orphans(old, renamed), []string{"poster"})
+
+	_, err = new.Rename(map[string]string{"idea": "nonexistent"})
+	is.True(err != nil)
+	_, err = new.Rename(map[string]string{"team_name": "project_idea"})
+	is.True(err != nil)
+
+	// Answers written against the old version show up under the new names
+	answers := Answers{"team_name": {"Skylab"}, "idea": {"A website"}, "q2": {"a"}}
+	normalized := renamed.Normalize(answers)
+	is.Equal(normalized["project_idea"], []string{"A website"})
+	is.Equal(normalized["question_2"], []string{"a"})
+	is.Equal(answers["project_idea"], []string(nil))
+	qas := MergeQuestionsAnswers(renamed, answers)
+	is.Equal(qas[1].Answer, []string{"A website"})
+
+	// Renames survive the next edit even if the PreviousNames were lost
+	next := CarryPreviousNames(renamed, new)
+	is.Equal(next[1].PreviousNames, []string{"idea"})
+	is.Equal(Orphans(renamed, next), []string(nil))
+}
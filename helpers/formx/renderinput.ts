@@ -1,5 +1,5 @@
 import m from "mithril";
-import { Type, IQuestion, INode, EventHandler } from "./formx.d";
+import { Type, IQuestion, IValidation, IScale, INode, EventHandler } from "./formx.d";
 
 export function renderInput(node: INode, nodes: Array<INode>): m.Vnode | Array<m.Vnode> {
   const renderfunc = ri.get(node.question.Type);
@@ -20,9 +20,132 @@ export function renderInput(node: INode, nodes: Array<INode>): m.Vnode | Array<m
       ),
     ),
     m("div", renderfunc(node)),
+    renderValidation(node),
+    renderShowIf(node, nodes),
   ];
 }
 
+/**
+ * renderValidation renders the inputs for the rules that an answer to the
+ * question must satisfy before the form can be submitted. Which rules are
+ * available depends on the question type.
+ */
+function renderValidation(node: INode): m.Vnode | null {
+  const type = node.question.Type;
+  if (type === Type.Paragraph || type === Type.Image) {
+    return null;
+  }
+  const validation = node.question.Validation || {};
+  const numberInput = (label: string, key: "MinLength" | "MaxLength" | "MinSelections" | "MaxSelections") =>
+    m(
+      "label.db",
+      `${label}: `,
+      m("input.w3", {
+        type: "number",
+        min: 0,
+        oninput: updateValidation(node, key),
+        value: validation[key] || "",
+      }),
+    );
+  const boundInput = (label: string, key: "Min" | "Max") =>
+    m(
+      "label.db",
+      `${label}: `,
+      m("input.w4", {
+        type: "number",
+        step: "any",
+        oninput: updateValidation(node, key),
+        value: validation[key] === undefined ? "" : validation[key],
+      }),
+    );
+  return m(
+    "p",
+    m(
+      "label.db.pointer",
+      m("input.mr2.pointer", {
+        type: "checkbox",
+        onchange: updateValidation(node, "Required"),
+        checked: !!validation.Required,
+      }),
+      "Required",
+    ),
+    type === Type.Shorttext || type === Type.Longtext
+      ? [
+          numberInput("Minimum length", "MinLength"),
+          numberInput("Maximum length", "MaxLength"),
+          m(
+            "label.db",
+            "Format: ",
+            m(
+              "select",
+              { onchange: updateValidation(node, "Format") },
+              ["", "url", "email"].map((format) =>
+                m("option", { value: format, selected: (validation.Format || "") === format }, format || "(any)"),
+              ),
+            ),
+          ),
+          m(
+            "label.db",
+            "Pattern (regular expression): ",
+            m("input.w-50", {
+              type: "text",
+              oninput: updateValidation(node, "Pattern"),
+              value: validation.Pattern || "",
+              autocomplete: "off",
+            }),
+          ),
+        ]
+      : null,
+    type === Type.Checkbox
+      ? [numberInput("Minimum selections", "MinSelections"), numberInput("Maximum selections", "MaxSelections")]
+      : null,
+    type === Type.Number ? [boundInput("Minimum", "Min"), boundInput("Maximum", "Max")] : null,
+  );
+}
+
+/**
+ * renderShowIf renders the inputs for a question's display condition: the
+ * question is only shown if the answer to another question contains one of
+ * the listed values.
+ */
+function renderShowIf(node: INode, nodes: Array<INode>): m.Vnode {
+  const showIf = node.question.ShowIf;
+  const names = nodes
+    .filter((x) => x.uuid !== node.uuid)
+    .reduce(
+      (acc: Array<string>, x) =>
+        acc.concat(
+          x.question.Type === Type.Multiradio
+            ? (x.question.Subquestions || []).map((subqn) => subqn.Name)
+            : [x.question.Name],
+        ),
+      [],
+    )
+    .filter((name) => name);
+  return m(
+    "p",
+    m("div", "Show only if the answer to:"),
+    m(
+      "select",
+      { onchange: updateShowIfName(node) },
+      m("option", { value: "", selected: !showIf }, "(always show)"),
+      names.map((name) => m("option", { value: name, selected: showIf && showIf.Name === name }, name)),
+    ),
+    showIf
+      ? m(
+          "div",
+          m("div", "is one of these values (comma separated):"),
+          m("input.db.w-80", {
+            type: "text",
+            oninput: updateShowIfValues(node),
+            value: showIf.Values.join(", "),
+            autocomplete: "off",
+          }),
+        )
+      : null,
+  );
+}
+
 const ri = new Map<Type, (node: INode) => m.Vnode>(); // render input
 // ri Paragraph
 ri.set(Type.Paragraph, function (node: INode): m.Vnode {
@@ -338,6 +461,52 @@ ri.set(Type.Image, function (node: INode): m.Vnode {
   );
 });
 
+// ri Number, URL, Email: only differ from short text in how they are rendered
+// and validated
+ri.set(Type.Number, ri.get(Type.Shorttext));
+ri.set(Type.URL, ri.get(Type.Shorttext));
+ri.set(Type.Email, ri.get(Type.Shorttext));
+// ri Ranking: the options are the things to be ranked
+ri.set(Type.Ranking, ri.get(Type.Radio));
+// ri Likert
+ri.set(Type.Likert, function (node: INode): m.Vnode {
+  const scale = node.question.Scale || { Min: 1, Max: 5, MinLabel: "", MaxLabel: "" };
+  return m(
+    "div",
+    m(
+      "p.pr4",
+      m("div", "Text:"),
+      m("textarea.w-100", { oninput: updateText(node), cols: 40, rows: 10 }, node.question.Text),
+    ),
+    m(
+      "p",
+      m("div", m("span", "Name:")),
+      m("input.db.w-80", {
+        type: "text",
+        oninput: updateName(node),
+        value: node.question.Name,
+        required: true,
+        autocomplete: "off",
+      }),
+    ),
+    m(
+      "p",
+      m("div", "Scale:"),
+      m(
+        "div",
+        m("input.w3", { type: "number", oninput: updateScale(node, "Min"), value: scale.Min }),
+        m("input.ml2", { type: "text", placeholder: "label", oninput: updateScale(node, "MinLabel"), value: scale.MinLabel }),
+      ),
+      m("div", "to"),
+      m(
+        "div",
+        m("input.w3", { type: "number", oninput: updateScale(node, "Max"), value: scale.Max }),
+        m("input.ml2", { type: "text", placeholder: "label", oninput: updateScale(node, "MaxLabel"), value: scale.MaxLabel }),
+      ),
+    ),
+  );
+});
+
 function changeQuestion(node: INode, nodes: Array<INode>): EventHandler {
   return function (event: Event) {
     const el = event.currentTarget as HTMLSelectElement;
@@ -350,6 +519,10 @@ function changeQuestion(node: INode, nodes: Array<INode>): EventHandler {
       Name: node.question.Name,
       Options: node.question.Options || [],
       Subquestions: node.question.Subquestions || [],
+      ShowIf: node.question.ShowIf,
+      Validation: node.question.Validation,
+      Scale: node.question.Scale,
+      PreviousNames: node.question.PreviousNames,
     };
     const index = nodes.findIndex((x) => x.uuid === node.uuid);
     nodes[index].question = newquestion;
@@ -367,6 +540,78 @@ function updateName(node: INode): EventHandler {
   };
 }
 
+function updateValidation(node: INode, key: keyof IValidation): EventHandler {
+  return function (event: Event) {
+    const el = event.currentTarget as HTMLInputElement;
+    const validation: IValidation = node.question.Validation || {};
+    switch (key) {
+      case "Required":
+        validation.Required = el.checked;
+        break;
+      case "MinLength":
+      case "MaxLength":
+      case "MinSelections":
+      case "MaxSelections":
+        validation[key] = parseInt(el.value, 10) || 0;
+        break;
+      case "Format":
+        validation.Format = el.value as IValidation["Format"];
+        break;
+      case "Pattern":
+        validation.Pattern = el.value;
+        break;
+      case "Min":
+      case "Max":
+        if (el.value === "" || isNaN(parseFloat(el.value))) {
+          delete validation[key];
+        } else {
+          validation[key] = parseFloat(el.value);
+        }
+        break;
+    }
+    node.question.Validation = validation;
+  };
+}
+
+function updateScale(node: INode, key: keyof IScale): EventHandler {
+  return function (event: Event) {
+    const el = event.currentTarget as HTMLInputElement;
+    const scale: IScale = node.question.Scale || { Min: 1, Max: 5, MinLabel: "", MaxLabel: "" };
+    switch (key) {
+      case "Min":
+      case "Max":
+        scale[key] = parseInt(el.value, 10) || 0;
+        break;
+      case "MinLabel":
+      case "MaxLabel":
+        scale[key] = el.value;
+        break;
+    }
+    node.question.Scale = scale;
+  };
+}
+
+function updateShowIfName(node: INode): EventHandler {
+  return function (event: Event) {
+    const el = event.currentTarget as HTMLSelectElement;
+    if (el.value === "") {
+      delete node.question.ShowIf;
+      return;
+    }
+    node.question.ShowIf = { Name: el.value, Values: node.question.ShowIf ? node.question.ShowIf.Values : [] };
+  };
+}
+
+function updateShowIfValues(node: INode): EventHandler {
+  return function (event: Event) {
+    const el = event.currentTarget as HTMLInputElement;
+    node.question.ShowIf.Values = el.value
+      .split(",")
+      .map((value) => value.trim())
+      .filter((value) => value !== "");
+  };
+}
+
 function updateText(node: INode): EventHandler {
   return function (event: Event) {
     const el = event.currentTarget as HTMLInputElement;
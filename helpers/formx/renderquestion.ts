@@ -13,7 +13,12 @@ export function renderQuestion(node: INode): m.Vnode | Array<m.Vnode> {
   if (!renderfunc) {
     return m("pre", `rendering function not found for question of type: ${node.question.Type}`);
   }
-  return [m("h4", "Output"), renderfunc(node)];
+  const showIf = node.question.ShowIf;
+  return [
+    m("h4", "Output"),
+    showIf ? m("div.f7.gray", `Only shown if ${showIf.Name} is one of: ${showIf.Values.join(", ")}`) : null,
+    renderfunc(node),
+  ];
 }
 
 const rq = new Map<Type, (node: INode) => m.Vnode>(); // render question
@@ -133,6 +138,74 @@ rq.set(Type.Date, function (node: INode): m.Vnode {
 rq.set(Type.Time, function (node: INode): m.Vnode {
   return m("p", m("div", m.trust(node.question.Text)), m("input", { type: "time", name: node.question.Name }));
 });
+// rq Number
+rq.set(Type.Number, function (node: INode): m.Vnode {
+  const validation = node.question.Validation || {};
+  return m(
+    "p",
+    m("div", m.trust(node.question.Text)),
+    m("input", { type: "number", step: "any", name: node.question.Name, min: validation.Min, max: validation.Max }),
+  );
+});
+// rq Likert
+rq.set(Type.Likert, function (node: INode): m.Vnode {
+  const scale = node.question.Scale || { Min: 1, Max: 5, MinLabel: "", MaxLabel: "" };
+  const points: Array<number> = [];
+  for (let i = scale.Min; i <= scale.Max && points.length < 100; i++) {
+    points.push(i);
+  }
+  return m(
+    "p",
+    m("div", m.trust(node.question.Text)),
+    m(
+      "div.flex.items-center.flex-wrap",
+      scale.MinLabel ? m("span.mr3", scale.MinLabel) : null,
+      ...points.map((point) =>
+        m(
+          "label.pointer.flex.flex-column.items-center.mh2",
+          { for: idfy(node.question.Name, String(point)) },
+          m("span", point),
+          m("input.pointer", {
+            type: "radio",
+            name: node.question.Name,
+            value: point,
+            id: idfy(node.question.Name, String(point)),
+          }),
+        ),
+      ),
+      scale.MaxLabel ? m("span.ml3", scale.MaxLabel) : null,
+    ),
+  );
+});
+// rq URL
+rq.set(Type.URL, function (node: INode): m.Vnode {
+  return m(
+    "p",
+    m("div", m.trust(node.question.Text)),
+    m("input.form-input.w-75", { type: "url", name: node.question.Name, placeholder: "https://" }),
+  );
+});
+// rq Email
+rq.set(Type.Email, function (node: INode): m.Vnode {
+  return m(
+    "p",
+    m("div", m.trust(node.question.Text)),
+    m("input.form-input.w-75", { type: "email", name: node.question.Name }),
+  );
+});
+// rq Ranking
+rq.set(Type.Ranking, function (node: INode): m.Vnode {
+  return m(
+    "p",
+    m("div", m.trust(node.question.Text)),
+    m(
+      "ol.pl4",
+      ...(node.question.Options || []).map((option) =>
+        m("li.ba.b--light-gray.pa1.mv1", m("input", { type: "hidden", name: node.question.Name, value: option.Value }), option.Display),
+      ),
+    ),
+  );
+});
 // rq Image
 rq.set(Type.Image, function (node: INode): m.Vnode {
   return m(
@@ -0,0 +1,136 @@
+package formx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaError lists everything that is wrong with a set of questions, such as
+// a form definition uploaded by an admin.
+type SchemaError struct {
+	Problems []string
+}
+
+func (err SchemaError) Error() string {
+	return "formx: invalid questions: " + strings.Join(err.Problems, "; ")
+}
+
+// questionTypes are the valid values of Question.Type
+var questionTypes = map[string]bool{
+	QuestionTypeParagraph:  true,
+	QuestionTypeShorttext:  true,
+	QuestionTypeLongtext:   true,
+	QuestionTypeCheckbox:   true,
+	QuestionTypeSelect:     true,
+	QuestionTypeRadio:      true,
+	QuestionTypeMultiradio: true,
+	QuestionTypeDate:       true,
+	QuestionTypeTime:       true,
+	QuestionTypeImage:      true,
+	QuestionTypeNumber:     true,
+	QuestionTypeLikert:     true,
+	QuestionTypeURL:        true,
+	QuestionTypeEmail:      true,
+	QuestionTypeRanking:    true,
+}
+
+// hasOptions reports whether questions of the type pick their answers from
+// Options.
+func hasOptions(questionType string) bool {
+	switch questionType {
+	case QuestionTypeCheckbox, QuestionTypeSelect, QuestionTypeRadio, QuestionTypeMultiradio, QuestionTypeRanking:
+		return true
+	}
+	return false
+}
+
+// Check makes sure that the questions are well formed: every question has a
+// valid type, every answer is stored under a unique name, choice questions
+// have options and conditions refer to existing questions. It returns a
+// SchemaError listing every problem found, or nil.
+func (questions Questions) Check() error {
+	var problems []string
+	problemf := func(i int, format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf("question %d: ", i+1)+fmt.Sprintf(format, a...))
+	}
+	// Names of every question and subquestion, mapped to the (1-indexed)
+	// question that first used it
+	names := make(map[string]int)
+	useName := func(i int, name string) {
+		if name == "" {
+			return
+		}
+		if j, ok := names[name]; ok {
+			problemf(i, "name %q is already used by question %d", name, j)
+			return
+		}
+		names[name] = i + 1
+	}
+	for i, question := range questions {
+		if !questionTypes[question.Type] {
+			problemf(i, "%q is not a valid question type", question.Type)
+			continue
+		}
+		switch question.Type {
+		case QuestionTypeParagraph:
+		case QuestionTypeMultiradio:
+			useName(i, question.Name)
+			if len(question.Subquestions) == 0 {
+				problemf(i, "a %s question needs at least one subquestion", question.Type)
+			}
+			for j, subquestion := range question.Subquestions {
+				if subquestion.Name == "" {
+					problemf(i, "subquestion %d has no name", j+1)
+				}
+				useName(i, subquestion.Name)
+			}
+		default:
+			if question.Name == "" {
+				problemf(i, "a %s question needs a name", question.Type)
+			}
+			useName(i, question.Name)
+		}
+		if hasOptions(question.Type) {
+			if len(question.Options) == 0 {
+				problemf(i, "a %s question needs at least one option", question.Type)
+			}
+			values := make(map[string]bool)
+			for j, option := range question.Options {
+				switch {
+				case option.Value == "":
+					problemf(i, "option %d has no value", j+1)
+				case values[option.Value]:
+					problemf(i, "option value %q is used more than once", option.Value)
+				}
+				values[option.Value] = true
+			}
+		}
+		if question.Scale != nil && question.Type == QuestionTypeLikert {
+			if question.Scale.Min >= question.Scale.Max || question.Scale.Max-question.Scale.Min >= maxScalePoints {
+				problemf(i, "scale from %d to %d is invalid", question.Scale.Min, question.Scale.Max)
+			}
+		}
+	}
+	// Previous names and conditions can only be checked once every name is
+	// known
+	for i, question := range questions {
+		previousNames := append([]string{}, question.PreviousNames...)
+		for _, subquestion := range question.Subquestions {
+			previousNames = append(previousNames, subquestion.PreviousNames...)
+		}
+		for _, name := range previousNames {
+			if j, ok := names[name]; ok {
+				problemf(i, "previous name %q is the current name of question %d", name, j)
+			}
+		}
+		if question.ShowIf != nil {
+			if _, ok := names[question.ShowIf.Name]; !ok || question.ShowIf.Name == question.Name {
+				problemf(i, "condition refers to %q which is not the name of another question", question.ShowIf.Name)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return SchemaError{Problems: problems}
+	}
+	return nil
+}
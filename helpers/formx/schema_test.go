@@ -0,0 +1,38 @@
+package formx
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	options := []Option{{Value: "a", Display: "A"}, {Value: "b", Display: "B"}}
+	is.NoErr(conditionalQuestions().Check())
+	is.NoErr(Questions{
+		{Type: QuestionTypeMultiradio, Options: options, Subquestions: []Subquestion{{Name: "q1"}, {Name: "q2"}}},
+		{Type: QuestionTypeShorttext, Name: "team_name", PreviousNames: []string{"name"}},
+	}.Check())
+
+	err := Questions{
+		{Type: "essay", Name: "essay"},
+		{Type: QuestionTypeShorttext},
+		{Type: QuestionTypeRadio, Name: "level"},
+		{Type: QuestionTypeCheckbox, Name: "level", Options: []Option{{Value: "a"}, {Value: "a"}}},
+		{Type: QuestionTypeMultiradio, Options: options},
+		{Type: QuestionTypeLongtext, Name: "idea", ShowIf: &Condition{Name: "missing"}, PreviousNames: []string{"level"}},
+	}.Check()
+	schemaErr, ok := err.(SchemaError)
+	is.True(ok)
+	is.Equal(schemaErr.Problems, []string{
+		`question 1: "essay" is not a valid question type`,
+		`question 2: a short text question needs a name`,
+		`question 3: a radio question needs at least one option`,
+		`question 4: name "level" is already used by question 3`,
+		`question 4: option value "a" is used more than once`,
+		`question 5: a multiradio question needs at least one subquestion`,
+		`question 6: previous name "level" is the current name of question 3`,
+		`question 6: condition refers to "missing" which is not the name of another question`,
+	})
+}
@@ -0,0 +1,24 @@
+package formx
+
+// Score adds up the answers of a form used as a rubric. Number and likert
+// questions count for the number answered, and radio and select questions
+// count for the value of the chosen option if that value is a number. All
+// other questions do not count. scored is false if none of the questions that
+// count were answered.
+func Score(questions Questions, answers Answers) (score float64, scored bool) {
+	answers = questions.Normalize(answers)
+	for _, question := range questions {
+		switch question.Type {
+		case QuestionTypeNumber, QuestionTypeLikert, QuestionTypeRadio, QuestionTypeSelect:
+		default:
+			continue
+		}
+		values := numbers([][]string{{answerValue(answers[question.Name])}})
+		if len(values) == 0 {
+			continue
+		}
+		score += values[0]
+		scored = true
+	}
+	return score, scored
+}
@@ -0,0 +1,31 @@
+package formx
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestScore(t *testing.T) {
+	is := is.New(t)
+	questions := Questions{
+		{Type: QuestionTypeParagraph, Text: "Score each criterion"},
+		{Type: QuestionTypeLikert, Name: "idea", Scale: &Scale{Min: 1, Max: 5}, PreviousNames: []string{"originality"}},
+		{Type: QuestionTypeRadio, Name: "feasibility", Options: []Option{{Value: "0", Display: "Low"}, {Value: "2", Display: "High"}}},
+		{Type: QuestionTypeSelect, Name: "track", Options: []Option{{Value: "web"}, {Value: "mobile"}}},
+		{Type: QuestionTypeNumber, Name: "bonus"},
+		{Type: QuestionTypeLongtext, Name: "comments"},
+	}
+	score, scored := Score(questions, Answers{
+		"originality": {"4"}, // stored under the previous name
+		"feasibility": {"2"},
+		"track":       {"web"}, // not a number, does not count
+		"bonus":       {"0.5"},
+		"comments":    {"10"}, // not a scored question
+	})
+	is.True(scored)
+	is.Equal(score, 6.5)
+
+	_, scored = Score(questions, Answers{"track": {"web"}, "bonus": {""}})
+	is.True(!scored)
+}
@@ -39,7 +39,57 @@ export function isIQuestionArray(obj: any): obj is Array<IQuestion> {
       }
       return true;
     })(question.Subquestions);
-    if (!hasCorrectType || !hasText || !hasName || !hasOptions || !hasSubquestions) {
+    const hasShowIf = (function (showIf: any): boolean {
+      if (showIf === null || showIf === undefined) {
+        return true;
+      }
+      if (typeof showIf.Name !== "string") {
+        return false;
+      }
+      return Array.isArray(showIf.Values) && showIf.Values.every((value: any) => typeof value === "string");
+    })(question.ShowIf);
+    const hasValidation = (function (validation: any): boolean {
+      if (validation === null || validation === undefined) {
+        return true;
+      }
+      if (typeof validation !== "object") {
+        return false;
+      }
+      const numbers = ["MinLength", "MaxLength", "MinSelections", "MaxSelections", "Min", "Max"];
+      const strings = ["Pattern", "Format"];
+      return (
+        numbers.every((key) => validation[key] === undefined || typeof validation[key] === "number") &&
+        strings.every((key) => validation[key] === undefined || typeof validation[key] === "string") &&
+        (validation.Required === undefined || typeof validation.Required === "boolean")
+      );
+    })(question.Validation);
+    const hasScale = (function (scale: any): boolean {
+      if (scale === null || scale === undefined) {
+        return true;
+      }
+      return (
+        typeof scale.Min === "number" &&
+        typeof scale.Max === "number" &&
+        typeof scale.MinLabel === "string" &&
+        typeof scale.MaxLabel === "string"
+      );
+    })(question.Scale);
+    const hasPreviousNames =
+      question.PreviousNames === undefined ||
+      question.PreviousNames === null ||
+      (Array.isArray(question.PreviousNames) &&
+        question.PreviousNames.every((name: any) => typeof name === "string"));
+    if (
+      !hasCorrectType ||
+      !hasText ||
+      !hasName ||
+      !hasOptions ||
+      !hasSubquestions ||
+      !hasShowIf ||
+      !hasValidation ||
+      !hasScale ||
+      !hasPreviousNames
+    ) {
       return false;
     }
   }
@@ -0,0 +1,314 @@
+package formx
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validation holds the rules that the answer to a question must satisfy
+// before its form can be submitted. Zero values mean the rule is not enforced.
+type Validation struct {
+	Required      bool   `json:"Required,omitempty"`
+	MinLength     int    `json:"MinLength,omitempty"`
+	MaxLength     int    `json:"MaxLength,omitempty"`
+	MinSelections int    `json:"MinSelections,omitempty"` // checkbox only
+	MaxSelections int    `json:"MaxSelections,omitempty"` // checkbox only
+	Pattern       string `json:"Pattern,omitempty"`       // must match the whole answer
+	Format        string `json:"Format,omitempty"`        // FormatURL or FormatEmail
+
+	// Bounds of a number question, nil means unbounded
+	Min *float64 `json:"Min,omitempty"`
+	Max *float64 `json:"Max,omitempty"`
+}
+
+const (
+	FormatURL   = "url"
+	FormatEmail = "email"
+)
+
+// Text answers are capped at these lengths (in characters) even if the
+// question does not set a MaxLength, so that nobody can stuff megabytes of
+// text into a form.
+const (
+	DefaultMaxLengthShorttext = 1000
+	DefaultMaxLengthLongtext  = 100000
+)
+
+// maxNumberLength is far longer than any sensible number, it stops drafts from
+// saving junk in number fields.
+const maxNumberLength = 64
+
+// ValidationErrors maps a question's Name to the reason its answer is invalid.
+// Questions without a Name are keyed by their index in the form instead (see
+// Questions.Shown).
+type ValidationErrors map[string]string
+
+func (errs ValidationErrors) Error() string {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	msgs := make([]string, len(keys))
+	for i, key := range keys {
+		msgs[i] = key + ": " + errs[key]
+	}
+	return "formx: invalid answers: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks answers against the Validation rules of every question that
+// is shown. It returns nil if all answers are valid.
+func Validate(questions Questions, answers Answers) ValidationErrors {
+	return validate(questions, answers, true)
+}
+
+// ValidateDraft is like Validate but only enforces the upper limits (maximum
+// length and maximum selections), as a draft is allowed to be incomplete.
+func ValidateDraft(questions Questions, answers Answers) ValidationErrors {
+	return validate(questions, answers, false)
+}
+
+func validate(questions Questions, answers Answers, complete bool) ValidationErrors {
+	errs := ValidationErrors{}
+	answers = questions.Normalize(answers)
+	shown := questions.Shown(answers)
+	for i, question := range questions {
+		key := questionKey(i, question)
+		if !shown[key] {
+			continue
+		}
+		var rules Validation
+		if question.Validation != nil {
+			rules = *question.Validation
+		}
+		var msg string
+		switch question.Type {
+		case QuestionTypeShorttext, QuestionTypeLongtext, QuestionTypeDate, QuestionTypeTime:
+			msg = validateText(question.Type, rules, answerValue(answers[question.Name]), complete)
+		case QuestionTypeURL:
+			rules.Format = FormatURL
+			msg = validateText(question.Type, rules, answerValue(answers[question.Name]), complete)
+		case QuestionTypeEmail:
+			rules.Format = FormatEmail
+			msg = validateText(question.Type, rules, answerValue(answers[question.Name]), complete)
+		case QuestionTypeNumber:
+			msg = validateNumber(rules, answerValue(answers[question.Name]), complete)
+		case QuestionTypeLikert:
+			msg = validateLikert(question, rules, answerValue(answers[question.Name]), complete)
+		case QuestionTypeRanking:
+			msg = validateRanking(question.Options, rules, answers[question.Name], complete)
+		case QuestionTypeCheckbox:
+			msg = validateSelections(question.Options, rules, answers[question.Name], complete)
+		case QuestionTypeSelect, QuestionTypeRadio:
+			rules.MaxSelections = 1
+			msg = validateSelections(question.Options, rules, answers[question.Name], complete)
+		case QuestionTypeMultiradio:
+			for _, subquestion := range question.Subquestions {
+				answer := answerValue(answers[subquestion.Name])
+				switch {
+				case answer == "" && rules.Required && complete:
+					msg = fmt.Sprintf("%s is required", subquestion.Text)
+				case answer != "" && complete && !hasOption(question.Options, answer):
+					msg = fmt.Sprintf("%q is not one of the options", answer)
+				}
+				if msg != "" {
+					break
+				}
+			}
+		}
+		if msg != "" {
+			errs[key] = msg
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateText(questionType string, rules Validation, answer string, complete bool) string {
+	maxLength := rules.MaxLength
+	if maxLength == 0 {
+		switch questionType {
+		case QuestionTypeLongtext:
+			maxLength = DefaultMaxLengthLongtext
+		default:
+			maxLength = DefaultMaxLengthShorttext
+		}
+	}
+	length := utf8.RuneCountInString(answer)
+	if length > maxLength {
+		return fmt.Sprintf("Must be at most %d characters long (currently %d)", maxLength, length)
+	}
+	if !complete {
+		return ""
+	}
+	if strings.TrimSpace(answer) == "" {
+		if rules.Required {
+			return "This question is required"
+		}
+		return "" // the remaining rules only apply to answers that were given
+	}
+	if rules.MinLength > 0 && length < rules.MinLength {
+		return fmt.Sprintf("Must be at least %d characters long (currently %d)", rules.MinLength, length)
+	}
+	if rules.Pattern != "" {
+		// A pattern that does not compile is the form's fault, not the
+		// user's, so it is not enforced
+		if re, err := regexp.Compile(`^(?:` + rules.Pattern + `)$`); err == nil && !re.MatchString(answer) {
+			return "Does not match the required format"
+		}
+	}
+	switch rules.Format {
+	case FormatURL:
+		if !isURL(answer) {
+			return "Must be a valid URL starting with http:// or https://"
+		}
+	case FormatEmail:
+		if !isEmail(answer) {
+			return "Must be a valid email address"
+		}
+	}
+	return ""
+}
+
+func validateNumber(rules Validation, answer string, complete bool) string {
+	if len(answer) > maxNumberLength {
+		return "Must be a number"
+	}
+	if !complete {
+		return ""
+	}
+	if answer == "" {
+		if rules.Required {
+			return "This question is required"
+		}
+		return ""
+	}
+	number, err := strconv.ParseFloat(answer, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return "Must be a number"
+	}
+	if rules.Min != nil && number < *rules.Min {
+		return "Must be at least " + strconv.FormatFloat(*rules.Min, 'f', -1, 64)
+	}
+	if rules.Max != nil && number > *rules.Max {
+		return "Must be at most " + strconv.FormatFloat(*rules.Max, 'f', -1, 64)
+	}
+	return ""
+}
+
+func validateLikert(question Question, rules Validation, answer string, complete bool) string {
+	if len(answer) > maxNumberLength {
+		return "Must be a point on the scale"
+	}
+	if !complete {
+		return ""
+	}
+	if answer == "" {
+		if rules.Required {
+			return "This question is required"
+		}
+		return ""
+	}
+	scale := scaleOf(QuestionAnswer{Scale: question.Scale})
+	point, err := strconv.Atoi(answer)
+	if err != nil || point < scale.Min || point > scale.Max {
+		return fmt.Sprintf("Must be a point on the scale from %d to %d", scale.Min, scale.Max)
+	}
+	return ""
+}
+
+// validateRanking checks that a ranking answer only ranks options and ranks
+// each of them at most once. If the question is required, every option must
+// be ranked.
+func validateRanking(options []Option, rules Validation, answer []string, complete bool) string {
+	if !complete {
+		return ""
+	}
+	ranked := make(map[string]bool)
+	for _, value := range answer {
+		if !hasOption(options, value) {
+			return fmt.Sprintf("%q is not one of the options", value)
+		}
+		if ranked[value] {
+			return fmt.Sprintf("%q is ranked more than once", value)
+		}
+		ranked[value] = true
+	}
+	if rules.Required && len(ranked) < len(options) {
+		return "Rank all of the options"
+	}
+	return ""
+}
+
+func validateSelections(options []Option, rules Validation, answer []string, complete bool) string {
+	var selected []string
+	for _, value := range answer {
+		if value != "" {
+			selected = append(selected, value)
+		}
+	}
+	if rules.MaxSelections > 0 && len(selected) > rules.MaxSelections {
+		if rules.MaxSelections == 1 {
+			return "Select only one option"
+		}
+		return fmt.Sprintf("Select at most %d options", rules.MaxSelections)
+	}
+	if !complete {
+		return ""
+	}
+	for _, value := range selected {
+		if !hasOption(options, value) {
+			return fmt.Sprintf("%q is not one of the options", value)
+		}
+	}
+	if len(selected) == 0 && rules.Required {
+		return "This question is required"
+	}
+	if len(selected) > 0 && rules.MinSelections > 0 && len(selected) < rules.MinSelections {
+		return fmt.Sprintf("Select at least %d options", rules.MinSelections)
+	}
+	return ""
+}
+
+func hasOption(options []Option, value string) bool {
+	for _, option := range options {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// WithErrors attaches the validation errors to the merged questions and
+// answers, so that render_form.html can show each error under its question.
+func WithErrors(qas []QuestionAnswer, errs ValidationErrors) []QuestionAnswer {
+	if len(errs) == 0 {
+		return qas
+	}
+	for i := range qas {
+		qas[i].Error = errs[questionKey(i, Question{Name: qas[i].Name})]
+	}
+	return qas
+}
@@ -0,0 +1,90 @@
+package formx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+	options := []Option{{Value: "a"}, {Value: "b"}, {Value: "c"}}
+	questions := Questions{
+		{Type: QuestionTypeParagraph, Text: "Instructions"},
+		{Type: QuestionTypeShorttext, Name: "name", Validation: &Validation{Required: true, MinLength: 2, MaxLength: 10}},
+		{Type: QuestionTypeShorttext, Name: "repo", Validation: &Validation{Format: FormatURL}},
+		{Type: QuestionTypeShorttext, Name: "email", Validation: &Validation{Format: FormatEmail}},
+		{Type: QuestionTypeShorttext, Name: "matric", Validation: &Validation{Pattern: `A\d{7}[A-Z]`}},
+		{Type: QuestionTypeCheckbox, Name: "tech", Options: options, Validation: &Validation{MinSelections: 2, MaxSelections: 2}},
+		{Type: QuestionTypeRadio, Name: "level", Options: options, Validation: &Validation{Required: true}},
+		{Type: QuestionTypeMultiradio, Options: options, Subquestions: []Subquestion{{Name: "q1", Text: "Q1"}, {Name: "q2", Text: "Q2"}}, Validation: &Validation{Required: true}},
+		{Type: QuestionTypeLongtext, Name: "notes"},
+	}
+
+	valid := Answers{
+		"name":   {"Skylab"},
+		"repo":   {"https://github.com/bokwoon95/nusskylabx"},
+		"email":  {"someone@u.nus.edu"},
+		"matric": {"A1234567X"},
+		"tech":   {"a", "b"},
+		"level":  {"c"},
+		"q1":     {"a"},
+		"q2":     {"b"},
+	}
+	is.Equal(Validate(questions, valid), nil)
+
+	errs := Validate(questions, Answers{
+		"name":   {" "},
+		"repo":   {"github.com/bokwoon95"},
+		"email":  {"not an email"},
+		"matric": {"A1234567"},
+		"tech":   {"a"},
+		"level":  {"z"},
+		"q1":     {"a"},
+		"notes":  {strings.Repeat("x", DefaultMaxLengthLongtext+1)},
+	})
+	is.Equal(len(errs), 8)
+	is.Equal(errs["name"], "This question is required")
+	is.True(errs["repo"] != "")
+	is.True(errs["email"] != "")
+	is.True(errs["matric"] != "")
+	is.Equal(errs["tech"], "Select at least 2 options")
+	is.Equal(errs["level"], `"z" is not one of the options`)
+	is.Equal(errs["#7"], "Q2 is required") // multiradio without a Name is keyed by its index
+	is.True(errs["notes"] != "")
+
+	// Optional questions may be left blank
+	errs = Validate(questions, Answers{"name": {"Skylab"}, "level": {"a"}, "q1": {"a"}, "q2": {"a"}})
+	is.Equal(errs, nil)
+}
+
+func TestValidateDraft(t *testing.T) {
+	is := is.New(t)
+	questions := Questions{
+		{Type: QuestionTypeShorttext, Name: "name", Validation: &Validation{Required: true, MaxLength: 5}},
+		{Type: QuestionTypeShorttext, Name: "title"},
+		{Type: QuestionTypeCheckbox, Name: "tech", Options: []Option{{Value: "a"}, {Value: "b"}}, Validation: &Validation{MaxSelections: 1}},
+	}
+	// Required answers may be missing from a draft, but upper limits apply
+	is.Equal(ValidateDraft(questions, Answers{}), nil)
+	errs := ValidateDraft(questions, Answers{
+		"name":  {"toolong"},
+		"title": {strings.Repeat("x", DefaultMaxLengthShorttext+1)},
+		"tech":  {"a", "b"},
+	})
+	is.Equal(len(errs), 3)
+}
+
+func TestValidateSkipsHidden(t *testing.T) {
+	is := is.New(t)
+	questions := conditionalQuestions()
+	questions[2].Validation = &Validation{Required: true}
+	is.Equal(Validate(questions, Answers{"level": {"vostok"}}), nil)
+	errs := Validate(questions, Answers{"level": {"artemis"}})
+	is.Equal(errs["experience"], "This question is required")
+
+	qas := WithErrors(MergeQuestionsAnswers(questions, Answers{"level": {"artemis"}}), errs)
+	is.Equal(qas[2].Error, "This question is required")
+	is.Equal(qas[1].Error, "")
+}
@@ -0,0 +1,129 @@
+// Package ratelimit provides fixed window rate limiting. Counts are kept in a
+// Store, which may be in memory (for a single instance) or backed by a shared
+// database (so that several instances enforce the same limits).
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store counts the number of hits a key receives within fixed windows of time.
+type Store interface {
+	// Hit records one hit against key and returns the number of hits the key
+	// has received in the current window (including this one), as well as
+	// when the current window ends.
+	Hit(ctx context.Context, key string, window time.Duration) (count int, reset time.Time, err error)
+}
+
+// Limit is the maximum number of Requests allowed within every Window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result is the outcome of checking a key against a Limit.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RetryAfter is how long the client should wait before trying again.
+func (res Result) RetryAfter(now time.Time) time.Duration {
+	if res.Reset.Before(now) {
+		return 0
+	}
+	return res.Reset.Sub(now)
+}
+
+// WindowStart returns the start of the fixed window that now falls in. Windows
+// are aligned to the unix epoch so that every instance agrees on them.
+func WindowStart(now time.Time, window time.Duration) time.Time {
+	return now.Truncate(window)
+}
+
+// Check records a hit against key and reports whether it is within limit.
+func Check(ctx context.Context, store Store, key string, limit Limit) (Result, error) {
+	count, reset, err := store.Hit(ctx, key, limit.Window)
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{
+		Allowed:   count <= limit.Requests,
+		Limit:     limit.Requests,
+		Remaining: limit.Requests - count,
+		Reset:     reset,
+	}
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	return res, nil
+}
+
+// SetHeaders sets the X-RateLimit-* headers describing res on w, as well as
+// the Retry-After header if the request was not allowed.
+func SetHeaders(w http.ResponseWriter, res Result) {
+	now := time.Now()
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(res.Reset.Unix(), 10))
+	if !res.Allowed {
+		seconds := int(res.RetryAfter(now).Seconds() + 0.999) // round up
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+}
+
+type memoryEntry struct {
+	windowStart time.Time
+	count       int
+	reset       time.Time
+}
+
+// MemoryStore is a Store that keeps its counts in memory. Limits are only
+// enforced per process, use a database backed Store if several instances are
+// running behind a load balancer.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates a new MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// sweepInterval is how often expired entries are removed from a MemoryStore.
+const sweepInterval = time.Minute
+
+// Hit implements Store.
+func (store *MemoryStore) Hit(ctx context.Context, key string, window time.Duration) (count int, reset time.Time, err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	now := store.now()
+	if now.Sub(store.lastSweep) > sweepInterval {
+		for k, entry := range store.entries {
+			if !entry.reset.After(now) {
+				delete(store.entries, k)
+			}
+		}
+		store.lastSweep = now
+	}
+	windowStart := WindowStart(now, window)
+	entry := store.entries[key]
+	if !entry.windowStart.Equal(windowStart) {
+		entry = memoryEntry{windowStart: windowStart, reset: windowStart.Add(window)}
+	}
+	entry.count++
+	store.entries[key] = entry
+	return entry.count, entry.reset, nil
+}
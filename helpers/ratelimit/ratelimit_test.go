@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestMemoryStore(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Window: time.Minute}
+
+	res, err := Check(ctx, store, "login:ip:1.2.3.4", limit)
+	is.NoErr(err)
+	is.True(res.Allowed)
+	is.Equal(res.Remaining, 1)
+	is.Equal(res.Reset, now.Add(time.Minute))
+
+	res, err = Check(ctx, store, "login:ip:1.2.3.4", limit)
+	is.NoErr(err)
+	is.True(res.Allowed)
+	is.Equal(res.Remaining, 0)
+
+	res, err = Check(ctx, store, "login:ip:1.2.3.4", limit)
+	is.NoErr(err)
+	is.True(!res.Allowed) // third request in the same window
+	is.Equal(res.Remaining, 0)
+
+	res, err = Check(ctx, store, "login:ip:5.6.7.8", limit)
+	is.NoErr(err)
+	is.True(res.Allowed) // other keys are counted separately
+
+	now = now.Add(time.Minute)
+	res, err = Check(ctx, store, "login:ip:1.2.3.4", limit)
+	is.NoErr(err)
+	is.True(res.Allowed) // new window
+	is.Equal(res.Remaining, 1)
+
+	now = now.Add(time.Hour)
+	_, _ = Check(ctx, store, "login:ip:9.9.9.9", limit)
+	is.Equal(len(store.entries), 1) // expired entries are swept
+}
+
+func TestSetHeaders(t *testing.T) {
+	is := is.New(t)
+	rec := httptest.NewRecorder()
+	reset := time.Now().Add(30 * time.Second)
+	SetHeaders(rec, Result{Allowed: false, Limit: 5, Remaining: 0, Reset: reset})
+	is.Equal(rec.Header().Get("X-RateLimit-Limit"), "5")
+	is.Equal(rec.Header().Get("X-RateLimit-Remaining"), "0")
+	is.True(rec.Header().Get("Retry-After") == "30" || rec.Header().Get("Retry-After") == "29")
+
+	rec = httptest.NewRecorder()
+	SetHeaders(rec, Result{Allowed: true, Limit: 5, Remaining: 4, Reset: reset})
+	is.Equal(rec.Header().Get("Retry-After"), "")
+}
@@ -0,0 +1,127 @@
+// Package webhook provides utilities for sending and verifying HMAC-signed
+// webhook requests
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent along with every webhook request.
+const (
+	HeaderEvent     = "X-Skylab-Event"     // Name of the event e.g. submission.submitted
+	HeaderDelivery  = "X-Skylab-Delivery"  // Unique ID of the delivery, identical across retries
+	HeaderTimestamp = "X-Skylab-Timestamp" // Unix timestamp of when the request was signed
+	HeaderSignature = "X-Skylab-Signature" // sha256=<hex encoded HMAC of "timestamp.body">
+)
+
+// maxResponseBody is the maximum number of bytes of the receiver's response
+// body that will be kept.
+const maxResponseBody = 4096
+
+// Request is a single webhook request to be sent to a receiver.
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Response is the receiver's response to a webhook Request.
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+// OK reports whether the receiver acknowledged the webhook with a 2XX status
+// code.
+func (resp Response) OK() bool {
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// Sign computes the signature of a webhook body, in the same format as the
+// HeaderSignature header.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that the signature and timestamp headers of an incoming
+// webhook request match its body. Requests older than tolerance are rejected
+// to prevent replay attacks (a tolerance of 0 disables the check).
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header: %w", HeaderTimestamp, err)
+	}
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)) > tolerance {
+		return fmt.Errorf("timestamp %d is older than %s", timestamp, tolerance)
+	}
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Send signs and POSTs the webhook Request to its URL. A non-nil error is
+// only returned if the request could not be completed at all (e.g. the
+// receiver could not be reached), a non-2XX response is not an error and
+// should be checked with Response.OK.
+func Send(ctx context.Context, client *http.Client, req Request) (resp Response, err error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	timestamp := time.Now().Unix()
+	httpReq, err := http.NewRequest(http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "Skylab-Webhook")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(httpResp.Body, maxResponseBody))
+	resp.StatusCode = httpResp.StatusCode
+	resp.Body = strings.ToValidUTF8(string(b), "")
+	return resp, nil
+}
+
+// Backoff returns how long to wait before the next attempt, given the number
+// of attempts made so far. It doubles from one minute up to a cap of six
+// hours.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := time.Minute
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= 6*time.Hour {
+			return 6 * time.Hour
+		}
+	}
+	return backoff
+}
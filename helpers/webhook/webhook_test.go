@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSend(t *testing.T) {
+	is := is.New(t)
+	const secret = "s3cr3t"
+	body := []byte(`{"event":"submission.submitted","data":{"SubmissionID":1}}`)
+	var received http.Header
+	var receivedBody []byte
+	var verifyErr error
+	// Local HTTP receiver that verifies the signature like a real consumer would
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		verifyErr = Verify(secret, r.Header, receivedBody, time.Minute)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	resp, err := Send(context.Background(), receiver.Client(), Request{
+		URL:        receiver.URL,
+		Secret:     secret,
+		Event:      "submission.submitted",
+		DeliveryID: "42",
+		Body:       body,
+	})
+	is.NoErr(err)
+	is.True(resp.OK())
+	is.Equal(resp.StatusCode, http.StatusNoContent)
+	is.NoErr(verifyErr)                          // receiver could verify the signature
+	is.Equal(string(receivedBody), string(body)) // body arrives unchanged
+	is.Equal(received.Get(HeaderEvent), "submission.submitted")
+	is.Equal(received.Get(HeaderDelivery), "42")
+	is.Equal(received.Get("Content-Type"), "application/json")
+}
+
+func TestSend_Non2XX(t *testing.T) {
+	is := is.New(t)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer receiver.Close()
+	resp, err := Send(context.Background(), receiver.Client(), Request{URL: receiver.URL, Body: []byte("{}")})
+	is.NoErr(err) // a non-2XX response is not an error
+	is.True(!resp.OK())
+	is.Equal(resp.Body, "boom")
+}
+
+func TestSend_Unreachable(t *testing.T) {
+	is := is.New(t)
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+	_, err := Send(context.Background(), nil, Request{URL: url, Body: []byte("{}")})
+	is.True(err != nil)
+}
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+	body := []byte(`{"hello":"world"}`)
+	now := time.Now().Unix()
+	header := http.Header{}
+	header.Set(HeaderTimestamp, "")
+	is.True(Verify("secret", header, body, 0) != nil) // missing timestamp
+
+	header.Set(HeaderTimestamp, strconv.FormatInt(now, 10))
+	header.Set(HeaderSignature, Sign("secret", now, body))
+	is.NoErr(Verify("secret", header, body, time.Minute))
+	is.True(Verify("wrong secret", header, body, time.Minute) != nil)
+	is.True(Verify("secret", header, []byte(`{"hello":"there"}`), time.Minute) != nil)
+
+	old := time.Now().Add(-time.Hour).Unix()
+	header.Set(HeaderTimestamp, strconv.FormatInt(old, 10))
+	header.Set(HeaderSignature, Sign("secret", old, body))
+	is.True(Verify("secret", header, body, time.Minute) != nil) // too old
+	is.NoErr(Verify("secret", header, body, 0))                 // tolerance disabled
+}
+
+func TestBackoff(t *testing.T) {
+	is := is.New(t)
+	is.Equal(Backoff(0), time.Minute)
+	is.Equal(Backoff(1), time.Minute)
+	is.Equal(Backoff(2), 2*time.Minute)
+	is.Equal(Backoff(3), 4*time.Minute)
+	is.Equal(Backoff(100), 6*time.Hour)
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/bokwoon95/nusskylabx/app"
 	"github.com/bokwoon95/nusskylabx/app/skylab"
+	"github.com/bokwoon95/nusskylabx/app/webhooks"
 
 	"github.com/go-chi/docgen"
 )
@@ -25,18 +28,20 @@ func main() {
 	skylab.LoadDotenv()
 	// ENTRYPOINT: All routes are registered here
 	skylb, err := app.NewSkylab(skylab.Config{
-		BaseURL:       os.Getenv("BASE_URL"),
-		Port:          os.Getenv("PORT"),
-		DatabaseURL:   os.Getenv("DATABASE_URL"),
-		MigrationDir:  os.Getenv("MIGRATION_DIR"),
-		IsProd:        os.Getenv("IS_PROD"),
-		DebugMode:     os.Getenv("DEBUG_MODE"),
-		SecretKey:     os.Getenv("SECRET_KEY"),
-		MailerEnabled: os.Getenv("MAILER_ENABLED"),
-		SmtpHost:      os.Getenv("SMTP_HOST"),
-		SmtpPort:      os.Getenv("SMTP_PORT"),
-		SmtpUsername:  os.Getenv("SMTP_USERNAME"),
-		SmtpPassword:  os.Getenv("SMTP_PASSWORD"),
+		BaseURL:        os.Getenv("BASE_URL"),
+		Port:           os.Getenv("PORT"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		MigrationDir:   os.Getenv("MIGRATION_DIR"),
+		IsProd:         os.Getenv("IS_PROD"),
+		DebugMode:      os.Getenv("DEBUG_MODE"),
+		SecretKey:      os.Getenv("SECRET_KEY"),
+		TrustedProxies: os.Getenv("TRUSTED_PROXIES"),
+		OIDCProviders:  os.Getenv("OIDC_PROVIDERS"),
+		MailerEnabled:  os.Getenv("MAILER_ENABLED"),
+		SmtpHost:       os.Getenv("SMTP_HOST"),
+		SmtpPort:       os.Getenv("SMTP_PORT"),
+		SmtpUsername:   os.Getenv("SMTP_USERNAME"),
+		SmtpPassword:   os.Getenv("SMTP_PASSWORD"),
 	})
 	if err != nil {
 		log.Fatalln(err)
@@ -63,5 +68,12 @@ func main() {
 	default:
 		fmt.Printf("Listening on localhost%s, reverse proxied from %s\n", skylb.Port(), skylb.BaseURLWithProtocol())
 	}
+	if _, err = skylb.EncryptTOTPSecrets(); err != nil {
+		log.Fatalln(err)
+		return
+	}
+	go webhooks.New(skylb).Run(context.Background(), webhooks.DefaultInterval)
+	go skylb.RunSessionCleanup(context.Background(), time.Hour)
+	go skylb.RunRateLimitCleanup(context.Background(), time.Hour)
 	log.Fatal(http.ListenAndServe(skylb.Port(), skylb.Mux))
 }
@@ -0,0 +1,17 @@
+CREATE TABLE api_tokens (
+    api_token_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,user_id INT NOT NULL
+    ,name TEXT NOT NULL DEFAULT ''
+    ,hash TEXT NOT NULL
+    ,read_only BOOLEAN NOT NULL DEFAULT TRUE
+    ,roles TEXT[] NOT NULL DEFAULT '{}'
+    ,expires_at TIMESTAMPTZ NOT NULL
+    ,last_used_at TIMESTAMPTZ
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (hash)
+    ,FOREIGN KEY (user_id) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE api_tokens IS 'api_tokens contains the personal access tokens used by scripts to authenticate as a user. Only the hash of each token is stored. An empty roles array means the token is scoped to all of the user''s roles.';
+CREATE TRIGGER api_tokens_updated_at BEFORE UPDATE ON api_tokens FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
@@ -0,0 +1,9 @@
+DROP FUNCTION IF EXISTS trg.webhook_application_accepted CASCADE;
+DROP FUNCTION IF EXISTS trg.webhook_submitted CASCADE;
+DROP FUNCTION IF EXISTS trg.webhook_team_status_changed CASCADE;
+
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhook_events CASCADE;
+DROP TABLE IF EXISTS webhooks CASCADE;
+DROP TABLE IF EXISTS webhook_delivery_status_enum CASCADE;
+DROP TABLE IF EXISTS webhook_event_enum CASCADE;
@@ -0,0 +1,64 @@
+-- webhook_event
+CREATE TABLE webhook_event_enum (event TEXT PRIMARY KEY);
+INSERT INTO
+    webhook_event_enum (event)
+VALUES
+    ('application.accepted')
+    ,('submission.submitted')
+    ,('team_evaluation.submitted')
+    ,('user_evaluation.submitted')
+    ,('team.status_changed')
+RETURNING *;
+
+-- webhook_delivery_status
+CREATE TABLE webhook_delivery_status_enum (status TEXT PRIMARY KEY);
+INSERT INTO webhook_delivery_status_enum (status) VALUES ('pending'), ('succeeded'), ('failed') RETURNING *;
+
+CREATE TABLE webhooks (
+    webhook_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,url TEXT NOT NULL
+    ,secret TEXT NOT NULL
+    ,description TEXT NOT NULL DEFAULT ''
+    ,events TEXT[] NOT NULL DEFAULT '{}'
+    ,enabled BOOLEAN NOT NULL DEFAULT TRUE
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+COMMENT ON TABLE webhooks IS 'webhooks contains the admin-configured endpoints that lifecycle events are POSTed to. An empty events array means the webhook subscribes to every event.';
+CREATE TRIGGER webhooks_updated_at BEFORE UPDATE ON webhooks FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
+
+CREATE TABLE webhook_events (
+    webhook_event_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,event TEXT NOT NULL
+    ,object_id INT NOT NULL
+    ,changes JSONB
+    ,payload JSONB
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,dispatched_at TIMESTAMPTZ
+
+    ,FOREIGN KEY (event) REFERENCES webhook_event_enum (event) ON UPDATE CASCADE
+);
+COMMENT ON TABLE webhook_events IS 'webhook_events is an outbox of lifecycle events, populated by the triggers in sql/triggers/webhooks.sql. When an event is dispatched its payload is computed and it is fanned out into webhook_deliveries.';
+CREATE INDEX webhook_events_dispatched_at_idx ON webhook_events (dispatched_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+    webhook_delivery_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,webhook_id INT NOT NULL
+    ,webhook_event_id INT NOT NULL
+    ,status TEXT NOT NULL DEFAULT 'pending'
+    ,attempts INT NOT NULL DEFAULT 0
+    ,next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,response_status INT
+    ,response_body TEXT NOT NULL DEFAULT ''
+    ,error TEXT NOT NULL DEFAULT ''
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (webhook_id, webhook_event_id)
+    ,FOREIGN KEY (webhook_id) REFERENCES webhooks (webhook_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (webhook_event_id) REFERENCES webhook_events (webhook_event_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (status) REFERENCES webhook_delivery_status_enum (status) ON UPDATE CASCADE
+);
+COMMENT ON TABLE webhook_deliveries IS 'webhook_deliveries is the delivery log of every event sent to every webhook, including retries.';
+CREATE TRIGGER webhook_deliveries_updated_at BEFORE UPDATE ON webhook_deliveries FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS password_tokens CASCADE;
+DROP TABLE IF EXISTS password_token_purpose_enum CASCADE;
+DROP TABLE IF EXISTS password_login_roles CASCADE;
@@ -0,0 +1,25 @@
+CREATE TABLE password_login_roles (
+    role TEXT PRIMARY KEY
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (role) REFERENCES role_enum (role) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE password_login_roles IS 'password_login_roles contains the roles that admins have allowed to login with an email and password. A user may use password login if any of their roles is in this table.';
+
+CREATE TABLE password_token_purpose_enum (purpose TEXT PRIMARY KEY);
+INSERT INTO password_token_purpose_enum (purpose) VALUES ('set'), ('reset');
+
+CREATE TABLE password_tokens (
+    password_token_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,user_id INT NOT NULL
+    ,purpose TEXT NOT NULL
+    ,hash TEXT NOT NULL
+    ,expires_at TIMESTAMPTZ NOT NULL
+    ,used_at TIMESTAMPTZ
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (hash)
+    ,FOREIGN KEY (user_id) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (purpose) REFERENCES password_token_purpose_enum (purpose) ON UPDATE CASCADE
+);
+COMMENT ON TABLE password_tokens IS 'password_tokens contains the single-use tokens emailed to users for setting or resetting their password. Only the hash of each token is stored.';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS sessions_user_id_idx;
+ALTER TABLE sessions DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE sessions DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE sessions DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS session_id;
+COMMENT ON TABLE sessions IS 'sessions contains the list of users currently logged in.';
@@ -0,0 +1,9 @@
+-- session_id lets a session be referred to (e.g. in a revoke button) without
+-- exposing its hash
+ALTER TABLE sessions ADD COLUMN session_id INT GENERATED BY DEFAULT AS IDENTITY UNIQUE;
+ALTER TABLE sessions ADD COLUMN expires_at TIMESTAMPTZ NOT NULL DEFAULT NOW() + INTERVAL '30 days';
+ALTER TABLE sessions ADD COLUMN last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+COMMENT ON TABLE sessions IS 'sessions contains the list of users currently logged in. A session is only valid before its expires_at, and if it has been seen within the idle timeout.';
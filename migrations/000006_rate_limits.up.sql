@@ -0,0 +1,7 @@
+CREATE TABLE rate_limits (
+    key TEXT PRIMARY KEY
+    ,window_start TIMESTAMPTZ NOT NULL
+    ,window_end TIMESTAMPTZ NOT NULL
+    ,count INT NOT NULL DEFAULT 0
+);
+COMMENT ON TABLE rate_limits IS 'rate_limits counts the requests made by each IP address or user to a rate limited endpoint within the current window, so that every instance of skylab enforces the same limits.';
@@ -0,0 +1,26 @@
+CREATE TABLE user_totp (
+    user_id INT PRIMARY KEY
+    ,secret TEXT NOT NULL
+    ,enabled_at TIMESTAMPTZ
+    ,last_used_step BIGINT NOT NULL DEFAULT 0
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (user_id) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE user_totp IS 'user_totp contains the TOTP secret of each user who has set up two-factor authentication. Two-factor authentication is only enforced once enabled_at is set, i.e. after the user has confirmed a code from their authenticator app. last_used_step prevents a code from being used twice.';
+
+CREATE TABLE totp_recovery_codes (
+    totp_recovery_code_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,user_id INT NOT NULL
+    ,hash TEXT NOT NULL
+    ,used_at TIMESTAMPTZ
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (hash)
+    ,FOREIGN KEY (user_id) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE totp_recovery_codes IS 'totp_recovery_codes contains the single-use codes a user can enter instead of a TOTP code if they lose their authenticator app. Only the hash of each code is stored.';
+
+-- A session of a user with two-factor authentication enabled is pending (and
+-- cannot be used) until totp_verified_at is set
+ALTER TABLE sessions ADD COLUMN totp_verified_at TIMESTAMPTZ;
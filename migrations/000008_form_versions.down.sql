@@ -0,0 +1,12 @@
+DROP FUNCTION IF EXISTS trg.form_version CASCADE;
+DROP FUNCTION IF EXISTS trg.forms_version CASCADE;
+DROP FUNCTION IF EXISTS trg.forms_version_history CASCADE;
+ALTER TABLE feedback_on_users DROP COLUMN IF EXISTS feedback_form_version;
+ALTER TABLE feedback_on_teams DROP COLUMN IF EXISTS feedback_form_version;
+ALTER TABLE user_evaluations DROP COLUMN IF EXISTS evaluation_form_version;
+ALTER TABLE team_evaluations DROP COLUMN IF EXISTS evaluation_form_version;
+ALTER TABLE submissions DROP COLUMN IF EXISTS submission_form_version;
+ALTER TABLE user_roles_applicants DROP COLUMN IF EXISTS applicant_form_version;
+ALTER TABLE applications DROP COLUMN IF EXISTS application_form_version;
+DROP TABLE IF EXISTS form_versions CASCADE;
+ALTER TABLE forms DROP COLUMN IF EXISTS version;
@@ -0,0 +1,39 @@
+-- forms.version is bumped by trg.forms_version (see sql/triggers/form_versions.sql)
+-- every time the questions of a form change
+ALTER TABLE forms ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE form_versions (
+    form_version_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,form_id INT NOT NULL
+    ,version INT NOT NULL
+    ,questions JSONB
+    ,renames JSONB
+    ,created_by INT
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (form_id, version)
+    ,FOREIGN KEY (form_id) REFERENCES forms (form_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (created_by) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+COMMENT ON TABLE form_versions IS 'form_versions contains every version of the questions of each form. renames maps the old name of each question renamed in that version to its new name.';
+
+INSERT INTO form_versions (form_id, version, questions)
+SELECT form_id, version, questions FROM forms WHERE questions IS NOT NULL;
+
+-- Each answer records the version of the form it was written against, set by
+-- trg.form_version whenever the answer is written
+ALTER TABLE applications ADD COLUMN application_form_version INT;
+ALTER TABLE user_roles_applicants ADD COLUMN applicant_form_version INT;
+ALTER TABLE submissions ADD COLUMN submission_form_version INT;
+ALTER TABLE team_evaluations ADD COLUMN evaluation_form_version INT;
+ALTER TABLE user_evaluations ADD COLUMN evaluation_form_version INT;
+ALTER TABLE feedback_on_teams ADD COLUMN feedback_form_version INT;
+ALTER TABLE feedback_on_users ADD COLUMN feedback_form_version INT;
+
+UPDATE applications SET application_form_version = 1 WHERE application_data IS NOT NULL;
+UPDATE user_roles_applicants SET applicant_form_version = 1 WHERE applicant_data IS NOT NULL;
+UPDATE submissions SET submission_form_version = 1 WHERE submission_data IS NOT NULL;
+UPDATE team_evaluations SET evaluation_form_version = 1 WHERE evaluation_data IS NOT NULL;
+UPDATE user_evaluations SET evaluation_form_version = 1 WHERE evaluation_data IS NOT NULL;
+UPDATE feedback_on_teams SET feedback_form_version = 1 WHERE feedback_data IS NOT NULL;
+UPDATE feedback_on_users SET feedback_form_version = 1 WHERE feedback_data IS NOT NULL;
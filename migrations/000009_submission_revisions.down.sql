@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS trg.submission_revisions CASCADE;
+DROP TABLE IF EXISTS submission_revisions CASCADE;
@@ -0,0 +1,26 @@
+-- A new revision is added by trg.submission_revisions (see
+-- sql/triggers/submission_revisions.sql) every time the answers of a
+-- submission change
+CREATE TABLE submission_revisions (
+    submission_revision_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,submission_id INT NOT NULL
+    ,revision INT NOT NULL
+    ,submission_form_version INT
+    ,submission_data JSONB
+    ,readme TEXT NOT NULL DEFAULT ''
+    ,poster TEXT NOT NULL DEFAULT ''
+    ,video TEXT NOT NULL DEFAULT ''
+    ,restored_from INT
+    ,created_by INT
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (submission_id, revision)
+    ,FOREIGN KEY (submission_id) REFERENCES submissions (submission_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (created_by) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+COMMENT ON TABLE submission_revisions IS 'submission_revisions contains every revision of the answers of each submission. restored_from is the revision that a revision was restored from, if any.';
+
+INSERT INTO submission_revisions (submission_id, revision, submission_form_version, submission_data, readme, poster, video, created_at)
+SELECT submission_id, 1, submission_form_version, submission_data, readme, poster, video, updated_at
+FROM submissions
+WHERE submission_data IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS matchmaking_requests CASCADE;
+DROP TABLE IF EXISTS matchmaking_profiles CASCADE;
+DROP TABLE IF EXISTS matchmaking_requests_status_enum CASCADE;
@@ -0,0 +1,36 @@
+CREATE TABLE matchmaking_requests_status_enum (status TEXT PRIMARY KEY);
+INSERT INTO matchmaking_requests_status_enum (status) VALUES ('pending'), ('accepted'), ('declined'), ('cancelled') RETURNING *;
+
+-- An applicant without a partner joins the matchmaking pool by filling in a
+-- profile, and leaves it by deleting the profile
+CREATE TABLE matchmaking_profiles (
+    user_role_id INT PRIMARY KEY
+    ,project_level TEXT NOT NULL DEFAULT 'gemini'
+    ,skills TEXT[] NOT NULL DEFAULT '{}'
+    ,availability TEXT[] NOT NULL DEFAULT '{}'
+    ,about TEXT NOT NULL DEFAULT ''
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (user_role_id) REFERENCES user_roles (user_role_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (project_level) REFERENCES project_level_enum (project_level) ON UPDATE CASCADE
+);
+COMMENT ON TABLE matchmaking_profiles IS 'matchmaking_profiles contains the profiles of the applicants looking for a partner.';
+CREATE TRIGGER matchmaking_profiles_updated_at BEFORE UPDATE ON matchmaking_profiles FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
+
+CREATE TABLE matchmaking_requests (
+    matchmaking_request_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,requester_user_role_id INT NOT NULL
+    ,requestee_user_role_id INT NOT NULL
+    ,status TEXT NOT NULL DEFAULT 'pending'
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (requester_user_role_id, requestee_user_role_id)
+    ,CHECK (requester_user_role_id <> requestee_user_role_id)
+    ,FOREIGN KEY (requester_user_role_id) REFERENCES user_roles (user_role_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (requestee_user_role_id) REFERENCES user_roles (user_role_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (status) REFERENCES matchmaking_requests_status_enum (status) ON UPDATE CASCADE
+);
+COMMENT ON TABLE matchmaking_requests IS 'matchmaking_requests contains the requests to partner up sent between applicants in the matchmaking pool.';
+CREATE TRIGGER matchmaking_requests_updated_at BEFORE UPDATE ON matchmaking_requests FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
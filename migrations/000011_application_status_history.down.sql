@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS trg.application_status_history CASCADE;
+DROP TABLE IF EXISTS application_status_history CASCADE;
+ALTER TABLE applications DROP COLUMN IF EXISTS staged_status;
+UPDATE applications SET status = 'pending' WHERE status IN ('rejected', 'waitlisted');
+DELETE FROM applications_status_enum WHERE status IN ('rejected', 'waitlisted');
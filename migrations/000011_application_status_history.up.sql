@@ -0,0 +1,27 @@
+INSERT INTO applications_status_enum (status) VALUES ('rejected'), ('waitlisted') RETURNING *;
+
+-- Admins stage a decision on an application into staged_status, and publish
+-- all the staged decisions of a cohort in one go (see
+-- Admins.ListApplicationsPublish)
+ALTER TABLE applications ADD COLUMN staged_status TEXT;
+ALTER TABLE applications ADD CONSTRAINT applications_staged_status_fkey FOREIGN KEY (staged_status) REFERENCES applications_status_enum (status) ON UPDATE CASCADE;
+
+-- A row is added by trg.application_status_history (see
+-- sql/triggers/application_status_history.sql) every time the status of an
+-- application changes
+CREATE TABLE application_status_history (
+    application_status_history_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,application_id INT NOT NULL
+    ,old_status TEXT
+    ,new_status TEXT NOT NULL
+    ,changed_by INT
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (application_id) REFERENCES applications (application_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (changed_by) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+COMMENT ON TABLE application_status_history IS 'application_status_history contains every status transition of each application. old_status is NULL for the status an application was created with.';
+
+INSERT INTO application_status_history (application_id, new_status, created_at)
+SELECT application_id, status, created_at
+FROM applications;
@@ -0,0 +1,22 @@
+-- An admin assigns an admin or adviser to review an application by adding a
+-- row, which the reviewer fills in with the answers to the review form (the
+-- form of the application period with the subsection 'review'). score is the
+-- total of the answers to the review form, see formx.Score.
+CREATE TABLE application_reviews (
+    application_review_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,application_id INT NOT NULL
+    ,reviewer_user_role_id INT NOT NULL
+    ,review_form_id INT
+    ,review_data JSONB
+    ,score NUMERIC
+    ,submitted BOOLEAN NOT NULL DEFAULT FALSE
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,UNIQUE (application_id, reviewer_user_role_id)
+    ,FOREIGN KEY (application_id) REFERENCES applications (application_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (reviewer_user_role_id) REFERENCES user_roles (user_role_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (review_form_id) REFERENCES forms (form_id) ON UPDATE CASCADE
+);
+COMMENT ON TABLE application_reviews IS 'application_reviews contains the reviewers assigned to each application and their reviews of it.';
+CREATE TRIGGER application_reviews_updated_at BEFORE UPDATE ON application_reviews FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
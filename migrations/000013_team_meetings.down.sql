@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_meeting_attendance CASCADE;
+DROP TABLE IF EXISTS team_meetings CASCADE;
@@ -0,0 +1,31 @@
+-- An adviser logs every meeting they have with one of their teams as a row in
+-- team_meetings, and which of the team's students attended it in
+-- team_meeting_attendance.
+CREATE TABLE team_meetings (
+    team_meeting_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,team_id INT NOT NULL
+    ,adviser_user_role_id INT NOT NULL
+    ,meeting_date DATE NOT NULL
+    ,notes TEXT NOT NULL DEFAULT ''
+    ,action_items TEXT NOT NULL DEFAULT ''
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (team_id) REFERENCES teams (team_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (adviser_user_role_id) REFERENCES user_roles (user_role_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE team_meetings IS 'team_meetings contains the meetings advisers had with their teams.';
+CREATE INDEX team_meetings_team_id_idx ON team_meetings (team_id);
+CREATE INDEX team_meetings_adviser_user_role_id_idx ON team_meetings (adviser_user_role_id);
+CREATE TRIGGER team_meetings_updated_at BEFORE UPDATE ON team_meetings FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
+
+CREATE TABLE team_meeting_attendance (
+    team_meeting_id INT NOT NULL
+    ,student_user_role_id INT NOT NULL
+    ,attended BOOLEAN NOT NULL DEFAULT FALSE
+
+    ,PRIMARY KEY (team_meeting_id, student_user_role_id)
+    ,FOREIGN KEY (team_meeting_id) REFERENCES team_meetings (team_meeting_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (student_user_role_id) REFERENCES user_roles (user_role_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE team_meeting_attendance IS 'team_meeting_attendance records whether each student of a team attended a meeting with their adviser.';
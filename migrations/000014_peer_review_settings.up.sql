@@ -0,0 +1,19 @@
+-- peer_review_settings controls what the evaluatee team sees of the evaluations
+-- done during an evaluation period. A period without a row in
+-- peer_review_settings shows every evaluation to the evaluatee as soon as it is
+-- made, along with who made it.
+CREATE TABLE peer_review_settings (
+    period_id INT PRIMARY KEY
+    ,anonymous BOOLEAN NOT NULL DEFAULT FALSE
+    ,reveal_after_submission BOOLEAN NOT NULL DEFAULT FALSE
+    ,release_at TIMESTAMPTZ
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    ,updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (period_id) REFERENCES periods (period_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+COMMENT ON TABLE peer_review_settings IS 'peer_review_settings dictates whether evaluators are hidden from evaluatees, whether evaluatees must submit their own evaluations first and when evaluations are released to evaluatees.';
+COMMENT ON COLUMN peer_review_settings.anonymous IS 'anonymous hides the evaluator from the evaluatee.';
+COMMENT ON COLUMN peer_review_settings.reveal_after_submission IS 'reveal_after_submission hides the evaluations of a team''s submission until the team has submitted all of its own evaluations.';
+COMMENT ON COLUMN peer_review_settings.release_at IS 'release_at hides every evaluation from the evaluatee until then.';
+CREATE TRIGGER peer_review_settings_updated_at BEFORE UPDATE ON peer_review_settings FOR EACH ROW EXECUTE PROCEDURE trg.updated_at();
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS trg.team_status_history CASCADE;
+DROP TABLE IF EXISTS team_status_history CASCADE;
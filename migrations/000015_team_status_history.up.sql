@@ -0,0 +1,20 @@
+-- A row is added by trg.team_status_history (see
+-- sql/triggers/team_status_history.sql) every time the status of a team
+-- changes
+CREATE TABLE team_status_history (
+    team_status_history_id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY
+    ,team_id INT NOT NULL
+    ,old_status TEXT
+    ,new_status TEXT NOT NULL
+    ,reason TEXT NOT NULL DEFAULT ''
+    ,changed_by INT
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+
+    ,FOREIGN KEY (team_id) REFERENCES teams (team_id) ON UPDATE CASCADE ON DELETE CASCADE
+    ,FOREIGN KEY (changed_by) REFERENCES users (user_id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+COMMENT ON TABLE team_status_history IS 'team_status_history contains every status transition of each team together with the reason given for it. old_status is NULL for the status a team was created with.';
+
+INSERT INTO team_status_history (team_id, new_status, created_at)
+SELECT team_id, status, created_at
+FROM teams;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS application_reviews_form_version ON application_reviews;
+ALTER TABLE application_reviews DROP COLUMN IF EXISTS review_form_version;
@@ -0,0 +1,9 @@
+-- Like the other answers (see 000008_form_versions), each review records the
+-- version of the review form it was written against, set by trg.form_version
+-- whenever the review is written
+ALTER TABLE application_reviews ADD COLUMN review_form_version INT;
+
+UPDATE application_reviews AS ar
+SET review_form_version = f.version
+FROM forms AS f
+WHERE f.form_id = ar.review_form_id AND ar.review_data IS NOT NULL;
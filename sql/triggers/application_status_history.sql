@@ -0,0 +1,22 @@
+-- Records every status transition of an application in
+-- application_status_history. The transaction that changes the status may set
+-- var.user_id (see Admins.ListApplicationsPublish) to record who changed
+-- it.
+DROP FUNCTION IF EXISTS trg.application_status_history CASCADE;
+CREATE OR REPLACE FUNCTION trg.application_status_history()
+RETURNS TRIGGER AS $$ DECLARE
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO application_status_history (application_id, old_status, new_status, changed_by)
+    VALUES (
+        NEW.application_id
+        ,CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END
+        ,NEW.status
+        ,NULLIF(current_setting('var.user_id', TRUE), '')::INT
+    );
+    RETURN NULL;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER application_status_history AFTER INSERT OR UPDATE OF status ON applications FOR EACH ROW EXECUTE PROCEDURE trg.application_status_history();
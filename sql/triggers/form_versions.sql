@@ -0,0 +1,47 @@
+-- Bumps the version of a form whenever its questions change
+DROP FUNCTION IF EXISTS trg.forms_version CASCADE;
+CREATE OR REPLACE FUNCTION trg.forms_version()
+RETURNS TRIGGER AS $$ BEGIN
+    -- A form that had no questions yet is still on its first version
+    IF OLD.questions IS NOT NULL AND NEW.questions IS DISTINCT FROM OLD.questions THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER forms_version BEFORE UPDATE OF questions ON forms FOR EACH ROW EXECUTE PROCEDURE trg.forms_version();
+
+-- Keeps a copy of every version of a form's questions in form_versions
+DROP FUNCTION IF EXISTS trg.forms_version_history CASCADE;
+CREATE OR REPLACE FUNCTION trg.forms_version_history()
+RETURNS TRIGGER AS $$ BEGIN
+    IF NEW.questions IS NULL THEN
+        RETURN NULL;
+    END IF;
+    INSERT INTO form_versions (form_id, version, questions)
+    VALUES (NEW.form_id, NEW.version, NEW.questions)
+    ON CONFLICT (form_id, version) DO UPDATE SET questions = EXCLUDED.questions
+    ;
+    RETURN NULL;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER forms_version_history AFTER INSERT OR UPDATE OF questions ON forms FOR EACH ROW EXECUTE PROCEDURE trg.forms_version_history();
+
+-- Stamps an answer with the current version of its form whenever the answer
+-- is written. TG_ARGV[0] is the form_id column of the table and TG_ARGV[1] is
+-- the column to store the version in.
+DROP FUNCTION IF EXISTS trg.form_version CASCADE;
+CREATE OR REPLACE FUNCTION trg.form_version()
+RETURNS TRIGGER AS $$ DECLARE
+    var_version INT;
+BEGIN
+    SELECT version INTO var_version FROM forms WHERE form_id = (to_jsonb(NEW)->>TG_ARGV[0])::INT;
+    NEW = jsonb_populate_record(NEW, jsonb_build_object(TG_ARGV[1], var_version));
+    RETURN NEW;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER applications_form_version BEFORE INSERT OR UPDATE OF application_data ON applications FOR EACH ROW WHEN (NEW.application_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('application_form_id', 'application_form_version');
+CREATE TRIGGER user_roles_applicants_form_version BEFORE INSERT OR UPDATE OF applicant_data ON user_roles_applicants FOR EACH ROW WHEN (NEW.applicant_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('applicant_form_id', 'applicant_form_version');
+CREATE TRIGGER submissions_form_version BEFORE INSERT OR UPDATE OF submission_data ON submissions FOR EACH ROW WHEN (NEW.submission_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('submission_form_id', 'submission_form_version');
+CREATE TRIGGER team_evaluations_form_version BEFORE INSERT OR UPDATE OF evaluation_data ON team_evaluations FOR EACH ROW WHEN (NEW.evaluation_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('evaluation_form_id', 'evaluation_form_version');
+CREATE TRIGGER user_evaluations_form_version BEFORE INSERT OR UPDATE OF evaluation_data ON user_evaluations FOR EACH ROW WHEN (NEW.evaluation_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('evaluation_form_id', 'evaluation_form_version');
+CREATE TRIGGER feedback_on_teams_form_version BEFORE INSERT OR UPDATE OF feedback_data ON feedback_on_teams FOR EACH ROW WHEN (NEW.feedback_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('feedback_form_id', 'feedback_form_version');
+CREATE TRIGGER feedback_on_users_form_version BEFORE INSERT OR UPDATE OF feedback_data ON feedback_on_users FOR EACH ROW WHEN (NEW.feedback_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('feedback_form_id', 'feedback_form_version');
+CREATE TRIGGER application_reviews_form_version BEFORE INSERT OR UPDATE OF review_data ON application_reviews FOR EACH ROW WHEN (NEW.review_data IS NOT NULL) EXECUTE PROCEDURE trg.form_version('review_form_id', 'review_form_version');
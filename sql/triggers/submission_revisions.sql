@@ -0,0 +1,39 @@
+-- Keeps a copy of every revision of a submission's answers, readme, poster and
+-- video in submission_revisions. Saves that change none of them do not add a
+-- revision. The transaction that saves the answers may set var.user_id and
+-- var.restored_from (see Skylab.SaveSubmissionAnswers) to record who saved the
+-- revision and which revision it was restored from.
+DROP FUNCTION IF EXISTS trg.submission_revisions CASCADE;
+CREATE OR REPLACE FUNCTION trg.submission_revisions()
+RETURNS TRIGGER AS $$ DECLARE
+    var_revision INT;
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.submission_data IS NULL AND NEW.readme = '' AND NEW.poster = '' AND NEW.video = '' THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE'
+        AND NEW.submission_data IS NOT DISTINCT FROM OLD.submission_data
+        AND NEW.readme IS NOT DISTINCT FROM OLD.readme
+        AND NEW.poster IS NOT DISTINCT FROM OLD.poster
+        AND NEW.video IS NOT DISTINCT FROM OLD.video
+    THEN
+        RETURN NULL;
+    END IF;
+
+    -- Lock the submission so that concurrent saves number their revisions one
+    -- after the other instead of both taking the same next revision
+    PERFORM 1 FROM submissions WHERE submission_id = NEW.submission_id FOR UPDATE;
+    SELECT COALESCE(MAX(revision), 0) + 1
+    INTO var_revision
+    FROM submission_revisions
+    WHERE submission_id = NEW.submission_id
+    ;
+    INSERT INTO submission_revisions (submission_id, revision, submission_form_version, submission_data, readme, poster, video, restored_from, created_by)
+    VALUES (
+        NEW.submission_id, var_revision, NEW.submission_form_version, NEW.submission_data, NEW.readme, NEW.poster, NEW.video
+        ,NULLIF(current_setting('var.restored_from', TRUE), '')::INT
+        ,NULLIF(current_setting('var.user_id', TRUE), '')::INT
+    );
+    RETURN NULL;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER submission_revisions AFTER INSERT OR UPDATE OF submission_data, readme, poster, video ON submissions FOR EACH ROW EXECUTE PROCEDURE trg.submission_revisions();
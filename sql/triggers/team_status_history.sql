@@ -0,0 +1,22 @@
+-- Records every status transition of a team in team_status_history. The
+-- transaction that changes the status may set var.user_id and var.reason (see
+-- Skylab.SetTeamStatus) to record who changed it and why.
+DROP FUNCTION IF EXISTS trg.team_status_history CASCADE;
+CREATE OR REPLACE FUNCTION trg.team_status_history()
+RETURNS TRIGGER AS $$ DECLARE
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO team_status_history (team_id, old_status, new_status, reason, changed_by)
+    VALUES (
+        NEW.team_id
+        ,CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END
+        ,NEW.status
+        ,COALESCE(current_setting('var.reason', TRUE), '')
+        ,NULLIF(current_setting('var.user_id', TRUE), '')::INT
+    );
+    RETURN NULL;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER team_status_history AFTER INSERT OR UPDATE OF status ON teams FOR EACH ROW EXECUTE PROCEDURE trg.team_status_history();
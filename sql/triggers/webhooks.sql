@@ -0,0 +1,39 @@
+-- Lifecycle events are recorded by triggers (instead of in the Go handlers)
+-- so that they are also captured when the underlying rows are modified by the
+-- plpgsql functions in sql/functions or by hand.
+DROP FUNCTION IF EXISTS trg.webhook_application_accepted CASCADE;
+CREATE OR REPLACE FUNCTION trg.webhook_application_accepted()
+RETURNS TRIGGER AS $$ BEGIN
+    IF NEW.status = 'accepted' AND OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO webhook_events (event, object_id, changes)
+        VALUES ('application.accepted', NEW.application_id, jsonb_build_object('old_status', OLD.status, 'new_status', NEW.status));
+    END IF;
+    RETURN NEW;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER applications_webhook AFTER UPDATE OF status ON applications FOR EACH ROW EXECUTE PROCEDURE trg.webhook_application_accepted();
+
+DROP FUNCTION IF EXISTS trg.webhook_submitted CASCADE;
+CREATE OR REPLACE FUNCTION trg.webhook_submitted()
+RETURNS TRIGGER AS $$ DECLARE
+    var_event TEXT := TG_ARGV[0];
+    var_object_id INT := (to_jsonb(NEW) ->> TG_ARGV[1])::INT;
+BEGIN
+    IF NEW.submitted AND NOT OLD.submitted THEN
+        INSERT INTO webhook_events (event, object_id) VALUES (var_event, var_object_id);
+    END IF;
+    RETURN NEW;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER submissions_webhook AFTER UPDATE OF submitted ON submissions FOR EACH ROW EXECUTE PROCEDURE trg.webhook_submitted('submission.submitted', 'submission_id');
+CREATE TRIGGER team_evaluations_webhook AFTER UPDATE OF submitted ON team_evaluations FOR EACH ROW EXECUTE PROCEDURE trg.webhook_submitted('team_evaluation.submitted', 'team_evaluation_id');
+CREATE TRIGGER user_evaluations_webhook AFTER UPDATE OF submitted ON user_evaluations FOR EACH ROW EXECUTE PROCEDURE trg.webhook_submitted('user_evaluation.submitted', 'user_evaluation_id');
+
+DROP FUNCTION IF EXISTS trg.webhook_team_status_changed CASCADE;
+CREATE OR REPLACE FUNCTION trg.webhook_team_status_changed()
+RETURNS TRIGGER AS $$ BEGIN
+    IF OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO webhook_events (event, object_id, changes)
+        VALUES ('team.status_changed', NEW.team_id, jsonb_build_object('old_status', OLD.status, 'new_status', NEW.status));
+    END IF;
+    RETURN NEW;
+END $$ LANGUAGE plpgsql;
+CREATE TRIGGER teams_webhook AFTER UPDATE OF status ON teams FOR EACH ROW EXECUTE PROCEDURE trg.webhook_team_status_changed();
@@ -0,0 +1,45 @@
+"use strict";
+
+// Shows and hides formx questions that have a ShowIf condition as the answers
+// they depend on change. Hidden questions have their inputs disabled so that
+// they are not submitted (the server drops them anyway).
+(function () {
+  function answersFor(root, name) {
+    const answers = [];
+    for (const el of root.querySelectorAll("[name]")) {
+      if (el.name !== name || el.disabled) {
+        continue;
+      }
+      if ((el.type === "checkbox" || el.type === "radio") && !el.checked) {
+        continue;
+      }
+      answers.push(el.value);
+    }
+    return answers;
+  }
+
+  function update() {
+    // Questions can only depend on questions above them in practice, so a
+    // single pass in document order is enough
+    for (const question of document.querySelectorAll("[data-formx-showif]")) {
+      const condition = JSON.parse(question.dataset.formxShowif);
+      const root = question.closest("form") || document;
+      const dependency = root.querySelector(`[data-formx-name="${CSS.escape(condition.Name)}"]`);
+      const answers = answersFor(root, condition.Name);
+      const met = answers.some((answer) => (condition.Values || []).includes(answer));
+      const hidden = !met || (dependency !== null && dependency.hidden);
+      question.hidden = hidden;
+      for (const el of question.querySelectorAll("input, select, textarea")) {
+        el.disabled = hidden;
+      }
+    }
+  }
+
+  document.addEventListener("change", update);
+  document.addEventListener("input", update);
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", update);
+  } else {
+    update();
+  }
+})();
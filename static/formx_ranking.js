@@ -0,0 +1,51 @@
+"use strict";
+
+// Lets users reorder the options of formx ranking questions, either by
+// dragging them or with the up/down buttons. Each option carries a hidden
+// input, so the order of the inputs in the form is the submitted ranking.
+(function () {
+  let dragged = null;
+
+  document.addEventListener("dragstart", function (event) {
+    const item = event.target.closest && event.target.closest("[data-formx-ranking] > li");
+    if (!item) {
+      return;
+    }
+    dragged = item;
+    event.dataTransfer.effectAllowed = "move";
+    // Firefox does not start a drag unless some data is set
+    event.dataTransfer.setData("text/plain", "");
+  });
+
+  document.addEventListener("dragover", function (event) {
+    if (!dragged) {
+      return;
+    }
+    const item = event.target.closest && event.target.closest("[data-formx-ranking] > li");
+    if (!item || item === dragged || item.parentNode !== dragged.parentNode) {
+      return;
+    }
+    event.preventDefault();
+    const rect = item.getBoundingClientRect();
+    const after = event.clientY > rect.top + rect.height / 2;
+    item.parentNode.insertBefore(dragged, after ? item.nextSibling : item);
+  });
+
+  document.addEventListener("dragend", function () {
+    dragged = null;
+  });
+
+  document.addEventListener("click", function (event) {
+    const button = event.target.closest && event.target.closest("[data-formx-ranking-move]");
+    if (!button) {
+      return;
+    }
+    const item = button.closest("li");
+    if (button.dataset.formxRankingMove === "up" && item.previousElementSibling) {
+      item.parentNode.insertBefore(item, item.previousElementSibling);
+    } else if (button.dataset.formxRankingMove === "down" && item.nextElementSibling) {
+      item.parentNode.insertBefore(item.nextElementSibling, item);
+    }
+    button.focus();
+  });
+})();
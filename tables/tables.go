@@ -5,24 +5,138 @@ import (
 	sq "github.com/bokwoon95/go-structured-query/postgres"
 )
 
+// TABLE_API_TOKENS references the public.api_tokens table.
+type TABLE_API_TOKENS struct {
+	*sq.TableInfo
+	API_TOKEN_ID sq.NumberField
+	CREATED_AT   sq.TimeField
+	EXPIRES_AT   sq.TimeField
+	HASH         sq.StringField
+	LAST_USED_AT sq.TimeField
+	NAME         sq.StringField
+	READ_ONLY    sq.BooleanField
+	ROLES        sq.ArrayField
+	UPDATED_AT   sq.TimeField
+	USER_ID      sq.NumberField
+}
+
+// API_TOKENS creates an instance of the public.api_tokens table.
+func API_TOKENS() TABLE_API_TOKENS {
+	tbl := TABLE_API_TOKENS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "api_tokens",
+	}}
+	tbl.API_TOKEN_ID = sq.NewNumberField("api_token_id", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.EXPIRES_AT = sq.NewTimeField("expires_at", tbl.TableInfo)
+	tbl.HASH = sq.NewStringField("hash", tbl.TableInfo)
+	tbl.LAST_USED_AT = sq.NewTimeField("last_used_at", tbl.TableInfo)
+	tbl.NAME = sq.NewStringField("name", tbl.TableInfo)
+	tbl.READ_ONLY = sq.NewBooleanField("read_only", tbl.TableInfo)
+	tbl.ROLES = sq.NewArrayField("roles", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	tbl.USER_ID = sq.NewNumberField("user_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_API_TOKENS) As(alias string) TABLE_API_TOKENS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_APPLICATION_REVIEWS references the public.application_reviews table.
+type TABLE_APPLICATION_REVIEWS struct {
+	*sq.TableInfo
+	APPLICATION_ID        sq.NumberField
+	APPLICATION_REVIEW_ID sq.NumberField
+	CREATED_AT            sq.TimeField
+	REVIEW_DATA           sq.JSONField
+	REVIEW_FORM_ID        sq.NumberField
+	REVIEW_FORM_VERSION   sq.NumberField
+	REVIEWER_USER_ROLE_ID sq.NumberField
+	SCORE                 sq.NumberField
+	SUBMITTED             sq.BooleanField
+	UPDATED_AT            sq.TimeField
+}
+
+// APPLICATION_REVIEWS creates an instance of the public.application_reviews table.
+func APPLICATION_REVIEWS() TABLE_APPLICATION_REVIEWS {
+	tbl := TABLE_APPLICATION_REVIEWS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "application_reviews",
+	}}
+	tbl.APPLICATION_ID = sq.NewNumberField("application_id", tbl.TableInfo)
+	tbl.APPLICATION_REVIEW_ID = sq.NewNumberField("application_review_id", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.REVIEW_DATA = sq.NewJSONField("review_data", tbl.TableInfo)
+	tbl.REVIEW_FORM_ID = sq.NewNumberField("review_form_id", tbl.TableInfo)
+	tbl.REVIEW_FORM_VERSION = sq.NewNumberField("review_form_version", tbl.TableInfo)
+	tbl.REVIEWER_USER_ROLE_ID = sq.NewNumberField("reviewer_user_role_id", tbl.TableInfo)
+	tbl.SCORE = sq.NewNumberField("score", tbl.TableInfo)
+	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_APPLICATION_REVIEWS) As(alias string) TABLE_APPLICATION_REVIEWS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_APPLICATION_STATUS_HISTORY references the public.application_status_history table.
+type TABLE_APPLICATION_STATUS_HISTORY struct {
+	*sq.TableInfo
+	APPLICATION_ID                sq.NumberField
+	APPLICATION_STATUS_HISTORY_ID sq.NumberField
+	CHANGED_BY                    sq.NumberField
+	CREATED_AT                    sq.TimeField
+	NEW_STATUS                    sq.StringField
+	OLD_STATUS                    sq.StringField
+}
+
+// APPLICATION_STATUS_HISTORY creates an instance of the public.application_status_history table.
+func APPLICATION_STATUS_HISTORY() TABLE_APPLICATION_STATUS_HISTORY {
+	tbl := TABLE_APPLICATION_STATUS_HISTORY{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "application_status_history",
+	}}
+	tbl.APPLICATION_ID = sq.NewNumberField("application_id", tbl.TableInfo)
+	tbl.APPLICATION_STATUS_HISTORY_ID = sq.NewNumberField("application_status_history_id", tbl.TableInfo)
+	tbl.CHANGED_BY = sq.NewNumberField("changed_by", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.NEW_STATUS = sq.NewStringField("new_status", tbl.TableInfo)
+	tbl.OLD_STATUS = sq.NewStringField("old_status", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_APPLICATION_STATUS_HISTORY) As(alias string) TABLE_APPLICATION_STATUS_HISTORY {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_APPLICATIONS references the public.applications table.
 type TABLE_APPLICATIONS struct {
 	*sq.TableInfo
-	APPLICATION_DATA     sq.JSONField
-	APPLICATION_FORM_ID  sq.NumberField
-	APPLICATION_ID       sq.NumberField
-	COHORT               sq.StringField
-	CREATED_AT           sq.TimeField
-	CREATOR_USER_ROLE_ID sq.NumberField
-	DELETED_AT           sq.TimeField
-	MAGICSTRING          sq.StringField
-	PROJECT_IDEA         sq.StringField
-	PROJECT_LEVEL        sq.StringField
-	STATUS               sq.StringField
-	SUBMITTED            sq.BooleanField
-	TEAM_ID              sq.NumberField
-	TEAM_NAME            sq.StringField
-	UPDATED_AT           sq.TimeField
+	APPLICATION_DATA         sq.JSONField
+	APPLICATION_FORM_ID      sq.NumberField
+	APPLICATION_FORM_VERSION sq.NumberField
+	APPLICATION_ID           sq.NumberField
+	COHORT                   sq.StringField
+	CREATED_AT               sq.TimeField
+	CREATOR_USER_ROLE_ID     sq.NumberField
+	DELETED_AT               sq.TimeField
+	MAGICSTRING              sq.StringField
+	PROJECT_IDEA             sq.StringField
+	PROJECT_LEVEL            sq.StringField
+	STAGED_STATUS            sq.StringField
+	STATUS                   sq.StringField
+	SUBMITTED                sq.BooleanField
+	TEAM_ID                  sq.NumberField
+	TEAM_NAME                sq.StringField
+	UPDATED_AT               sq.TimeField
 }
 
 // APPLICATIONS creates an instance of the public.applications table.
@@ -33,6 +147,7 @@ func APPLICATIONS() TABLE_APPLICATIONS {
 	}}
 	tbl.APPLICATION_DATA = sq.NewJSONField("application_data", tbl.TableInfo)
 	tbl.APPLICATION_FORM_ID = sq.NewNumberField("application_form_id", tbl.TableInfo)
+	tbl.APPLICATION_FORM_VERSION = sq.NewNumberField("application_form_version", tbl.TableInfo)
 	tbl.APPLICATION_ID = sq.NewNumberField("application_id", tbl.TableInfo)
 	tbl.COHORT = sq.NewStringField("cohort", tbl.TableInfo)
 	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
@@ -41,6 +156,7 @@ func APPLICATIONS() TABLE_APPLICATIONS {
 	tbl.MAGICSTRING = sq.NewStringField("magicstring", tbl.TableInfo)
 	tbl.PROJECT_IDEA = sq.NewStringField("project_idea", tbl.TableInfo)
 	tbl.PROJECT_LEVEL = sq.NewStringField("project_level", tbl.TableInfo)
+	tbl.STAGED_STATUS = sq.NewStringField("staged_status", tbl.TableInfo)
 	tbl.STATUS = sq.NewStringField("status", tbl.TableInfo)
 	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
 	tbl.TEAM_ID = sq.NewNumberField("team_id", tbl.TableInfo)
@@ -104,16 +220,17 @@ func (tbl TABLE_COHORT_ENUM) As(alias string) TABLE_COHORT_ENUM {
 // TABLE_FEEDBACK_ON_TEAMS references the public.feedback_on_teams table.
 type TABLE_FEEDBACK_ON_TEAMS struct {
 	*sq.TableInfo
-	CREATED_AT          sq.TimeField
-	DELETED_AT          sq.TimeField
-	EVALUATEE_TEAM_ID   sq.NumberField
-	EVALUATOR_TEAM_ID   sq.NumberField
-	FEEDBACK_DATA       sq.JSONField
-	FEEDBACK_FORM_ID    sq.NumberField
-	FEEDBACK_ID_ON_TEAM sq.NumberField
-	OVERRIDE_OPEN       sq.BooleanField
-	SUBMITTED           sq.BooleanField
-	UPDATED_AT          sq.TimeField
+	CREATED_AT            sq.TimeField
+	DELETED_AT            sq.TimeField
+	EVALUATEE_TEAM_ID     sq.NumberField
+	EVALUATOR_TEAM_ID     sq.NumberField
+	FEEDBACK_DATA         sq.JSONField
+	FEEDBACK_FORM_ID      sq.NumberField
+	FEEDBACK_FORM_VERSION sq.NumberField
+	FEEDBACK_ID_ON_TEAM   sq.NumberField
+	OVERRIDE_OPEN         sq.BooleanField
+	SUBMITTED             sq.BooleanField
+	UPDATED_AT            sq.TimeField
 }
 
 // FEEDBACK_ON_TEAMS creates an instance of the public.feedback_on_teams table.
@@ -128,6 +245,7 @@ func FEEDBACK_ON_TEAMS() TABLE_FEEDBACK_ON_TEAMS {
 	tbl.EVALUATOR_TEAM_ID = sq.NewNumberField("evaluator_team_id", tbl.TableInfo)
 	tbl.FEEDBACK_DATA = sq.NewJSONField("feedback_data", tbl.TableInfo)
 	tbl.FEEDBACK_FORM_ID = sq.NewNumberField("feedback_form_id", tbl.TableInfo)
+	tbl.FEEDBACK_FORM_VERSION = sq.NewNumberField("feedback_form_version", tbl.TableInfo)
 	tbl.FEEDBACK_ID_ON_TEAM = sq.NewNumberField("feedback_id_on_team", tbl.TableInfo)
 	tbl.OVERRIDE_OPEN = sq.NewBooleanField("override_open", tbl.TableInfo)
 	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
@@ -150,6 +268,7 @@ type TABLE_FEEDBACK_ON_USERS struct {
 	EVALUATOR_TEAM_ID      sq.NumberField
 	FEEDBACK_DATA          sq.JSONField
 	FEEDBACK_FORM_ID       sq.NumberField
+	FEEDBACK_FORM_VERSION  sq.NumberField
 	FEEDBACK_ID_ON_USER    sq.NumberField
 	OVERRIDE_OPEN          sq.BooleanField
 	SUBMITTED              sq.BooleanField
@@ -168,6 +287,7 @@ func FEEDBACK_ON_USERS() TABLE_FEEDBACK_ON_USERS {
 	tbl.EVALUATOR_TEAM_ID = sq.NewNumberField("evaluator_team_id", tbl.TableInfo)
 	tbl.FEEDBACK_DATA = sq.NewJSONField("feedback_data", tbl.TableInfo)
 	tbl.FEEDBACK_FORM_ID = sq.NewNumberField("feedback_form_id", tbl.TableInfo)
+	tbl.FEEDBACK_FORM_VERSION = sq.NewNumberField("feedback_form_version", tbl.TableInfo)
 	tbl.FEEDBACK_ID_ON_USER = sq.NewNumberField("feedback_id_on_user", tbl.TableInfo)
 	tbl.OVERRIDE_OPEN = sq.NewBooleanField("override_open", tbl.TableInfo)
 	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
@@ -181,6 +301,40 @@ func (tbl TABLE_FEEDBACK_ON_USERS) As(alias string) TABLE_FEEDBACK_ON_USERS {
 	return tbl
 }
 
+// TABLE_FORM_VERSIONS references the public.form_versions table.
+type TABLE_FORM_VERSIONS struct {
+	*sq.TableInfo
+	CREATED_AT      sq.TimeField
+	CREATED_BY      sq.NumberField
+	FORM_ID         sq.NumberField
+	FORM_VERSION_ID sq.NumberField
+	QUESTIONS       sq.JSONField
+	RENAMES         sq.JSONField
+	VERSION         sq.NumberField
+}
+
+// FORM_VERSIONS creates an instance of the public.form_versions table.
+func FORM_VERSIONS() TABLE_FORM_VERSIONS {
+	tbl := TABLE_FORM_VERSIONS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "form_versions",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.CREATED_BY = sq.NewNumberField("created_by", tbl.TableInfo)
+	tbl.FORM_ID = sq.NewNumberField("form_id", tbl.TableInfo)
+	tbl.FORM_VERSION_ID = sq.NewNumberField("form_version_id", tbl.TableInfo)
+	tbl.QUESTIONS = sq.NewJSONField("questions", tbl.TableInfo)
+	tbl.RENAMES = sq.NewJSONField("renames", tbl.TableInfo)
+	tbl.VERSION = sq.NewNumberField("version", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_FORM_VERSIONS) As(alias string) TABLE_FORM_VERSIONS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_FORMS references the public.forms table.
 type TABLE_FORMS struct {
 	*sq.TableInfo
@@ -192,6 +346,7 @@ type TABLE_FORMS struct {
 	QUESTIONS  sq.JSONField
 	SUBSECTION sq.StringField
 	UPDATED_AT sq.TimeField
+	VERSION    sq.NumberField
 }
 
 // FORMS creates an instance of the public.forms table.
@@ -208,6 +363,7 @@ func FORMS() TABLE_FORMS {
 	tbl.QUESTIONS = sq.NewJSONField("questions", tbl.TableInfo)
 	tbl.SUBSECTION = sq.NewStringField("subsection", tbl.TableInfo)
 	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	tbl.VERSION = sq.NewNumberField("version", tbl.TableInfo)
 	return tbl
 }
 
@@ -241,6 +397,94 @@ func (tbl TABLE_FORMS_AUTHORIZED_ROLES) As(alias string) TABLE_FORMS_AUTHORIZED_
 	return tbl
 }
 
+// TABLE_MATCHMAKING_PROFILES references the public.matchmaking_profiles table.
+type TABLE_MATCHMAKING_PROFILES struct {
+	*sq.TableInfo
+	ABOUT         sq.StringField
+	AVAILABILITY  sq.ArrayField
+	CREATED_AT    sq.TimeField
+	PROJECT_LEVEL sq.StringField
+	SKILLS        sq.ArrayField
+	UPDATED_AT    sq.TimeField
+	USER_ROLE_ID  sq.NumberField
+}
+
+// MATCHMAKING_PROFILES creates an instance of the public.matchmaking_profiles table.
+func MATCHMAKING_PROFILES() TABLE_MATCHMAKING_PROFILES {
+	tbl := TABLE_MATCHMAKING_PROFILES{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "matchmaking_profiles",
+	}}
+	tbl.ABOUT = sq.NewStringField("about", tbl.TableInfo)
+	tbl.AVAILABILITY = sq.NewArrayField("availability", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.PROJECT_LEVEL = sq.NewStringField("project_level", tbl.TableInfo)
+	tbl.SKILLS = sq.NewArrayField("skills", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	tbl.USER_ROLE_ID = sq.NewNumberField("user_role_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_MATCHMAKING_PROFILES) As(alias string) TABLE_MATCHMAKING_PROFILES {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_MATCHMAKING_REQUESTS references the public.matchmaking_requests table.
+type TABLE_MATCHMAKING_REQUESTS struct {
+	*sq.TableInfo
+	CREATED_AT             sq.TimeField
+	MATCHMAKING_REQUEST_ID sq.NumberField
+	REQUESTEE_USER_ROLE_ID sq.NumberField
+	REQUESTER_USER_ROLE_ID sq.NumberField
+	STATUS                 sq.StringField
+	UPDATED_AT             sq.TimeField
+}
+
+// MATCHMAKING_REQUESTS creates an instance of the public.matchmaking_requests table.
+func MATCHMAKING_REQUESTS() TABLE_MATCHMAKING_REQUESTS {
+	tbl := TABLE_MATCHMAKING_REQUESTS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "matchmaking_requests",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.MATCHMAKING_REQUEST_ID = sq.NewNumberField("matchmaking_request_id", tbl.TableInfo)
+	tbl.REQUESTEE_USER_ROLE_ID = sq.NewNumberField("requestee_user_role_id", tbl.TableInfo)
+	tbl.REQUESTER_USER_ROLE_ID = sq.NewNumberField("requester_user_role_id", tbl.TableInfo)
+	tbl.STATUS = sq.NewStringField("status", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_MATCHMAKING_REQUESTS) As(alias string) TABLE_MATCHMAKING_REQUESTS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_MATCHMAKING_REQUESTS_STATUS_ENUM references the public.matchmaking_requests_status_enum table.
+type TABLE_MATCHMAKING_REQUESTS_STATUS_ENUM struct {
+	*sq.TableInfo
+	STATUS sq.StringField
+}
+
+// MATCHMAKING_REQUESTS_STATUS_ENUM creates an instance of the public.matchmaking_requests_status_enum table.
+func MATCHMAKING_REQUESTS_STATUS_ENUM() TABLE_MATCHMAKING_REQUESTS_STATUS_ENUM {
+	tbl := TABLE_MATCHMAKING_REQUESTS_STATUS_ENUM{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "matchmaking_requests_status_enum",
+	}}
+	tbl.STATUS = sq.NewStringField("status", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_MATCHMAKING_REQUESTS_STATUS_ENUM) As(alias string) TABLE_MATCHMAKING_REQUESTS_STATUS_ENUM {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_MEDIA references the public.media table.
 type TABLE_MEDIA struct {
 	*sq.TableInfo
@@ -319,6 +563,118 @@ func (tbl TABLE_MIME_TYPE_ENUM) As(alias string) TABLE_MIME_TYPE_ENUM {
 	return tbl
 }
 
+// TABLE_PASSWORD_LOGIN_ROLES references the public.password_login_roles table.
+type TABLE_PASSWORD_LOGIN_ROLES struct {
+	*sq.TableInfo
+	CREATED_AT sq.TimeField
+	ROLE       sq.StringField
+}
+
+// PASSWORD_LOGIN_ROLES creates an instance of the public.password_login_roles table.
+func PASSWORD_LOGIN_ROLES() TABLE_PASSWORD_LOGIN_ROLES {
+	tbl := TABLE_PASSWORD_LOGIN_ROLES{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "password_login_roles",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.ROLE = sq.NewStringField("role", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_PASSWORD_LOGIN_ROLES) As(alias string) TABLE_PASSWORD_LOGIN_ROLES {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_PASSWORD_TOKEN_PURPOSE_ENUM references the public.password_token_purpose_enum table.
+type TABLE_PASSWORD_TOKEN_PURPOSE_ENUM struct {
+	*sq.TableInfo
+	PURPOSE sq.StringField
+}
+
+// PASSWORD_TOKEN_PURPOSE_ENUM creates an instance of the public.password_token_purpose_enum table.
+func PASSWORD_TOKEN_PURPOSE_ENUM() TABLE_PASSWORD_TOKEN_PURPOSE_ENUM {
+	tbl := TABLE_PASSWORD_TOKEN_PURPOSE_ENUM{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "password_token_purpose_enum",
+	}}
+	tbl.PURPOSE = sq.NewStringField("purpose", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_PASSWORD_TOKEN_PURPOSE_ENUM) As(alias string) TABLE_PASSWORD_TOKEN_PURPOSE_ENUM {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_PASSWORD_TOKENS references the public.password_tokens table.
+type TABLE_PASSWORD_TOKENS struct {
+	*sq.TableInfo
+	CREATED_AT        sq.TimeField
+	EXPIRES_AT        sq.TimeField
+	HASH              sq.StringField
+	PASSWORD_TOKEN_ID sq.NumberField
+	PURPOSE           sq.StringField
+	USED_AT           sq.TimeField
+	USER_ID           sq.NumberField
+}
+
+// PASSWORD_TOKENS creates an instance of the public.password_tokens table.
+func PASSWORD_TOKENS() TABLE_PASSWORD_TOKENS {
+	tbl := TABLE_PASSWORD_TOKENS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "password_tokens",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.EXPIRES_AT = sq.NewTimeField("expires_at", tbl.TableInfo)
+	tbl.HASH = sq.NewStringField("hash", tbl.TableInfo)
+	tbl.PASSWORD_TOKEN_ID = sq.NewNumberField("password_token_id", tbl.TableInfo)
+	tbl.PURPOSE = sq.NewStringField("purpose", tbl.TableInfo)
+	tbl.USED_AT = sq.NewTimeField("used_at", tbl.TableInfo)
+	tbl.USER_ID = sq.NewNumberField("user_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_PASSWORD_TOKENS) As(alias string) TABLE_PASSWORD_TOKENS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_PEER_REVIEW_SETTINGS references the public.peer_review_settings table.
+type TABLE_PEER_REVIEW_SETTINGS struct {
+	*sq.TableInfo
+	ANONYMOUS               sq.BooleanField
+	CREATED_AT              sq.TimeField
+	PERIOD_ID               sq.NumberField
+	RELEASE_AT              sq.TimeField
+	REVEAL_AFTER_SUBMISSION sq.BooleanField
+	UPDATED_AT              sq.TimeField
+}
+
+// PEER_REVIEW_SETTINGS creates an instance of the public.peer_review_settings table.
+func PEER_REVIEW_SETTINGS() TABLE_PEER_REVIEW_SETTINGS {
+	tbl := TABLE_PEER_REVIEW_SETTINGS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "peer_review_settings",
+	}}
+	tbl.ANONYMOUS = sq.NewBooleanField("anonymous", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.PERIOD_ID = sq.NewNumberField("period_id", tbl.TableInfo)
+	tbl.RELEASE_AT = sq.NewTimeField("release_at", tbl.TableInfo)
+	tbl.REVEAL_AFTER_SUBMISSION = sq.NewBooleanField("reveal_after_submission", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_PEER_REVIEW_SETTINGS) As(alias string) TABLE_PEER_REVIEW_SETTINGS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_PERIODS references the public.periods table.
 type TABLE_PERIODS struct {
 	*sq.TableInfo
@@ -401,6 +757,34 @@ func (tbl TABLE_PROJECT_LEVEL_ENUM) As(alias string) TABLE_PROJECT_LEVEL_ENUM {
 	return tbl
 }
 
+// TABLE_RATE_LIMITS references the public.rate_limits table.
+type TABLE_RATE_LIMITS struct {
+	*sq.TableInfo
+	COUNT        sq.NumberField
+	KEY          sq.StringField
+	WINDOW_END   sq.TimeField
+	WINDOW_START sq.TimeField
+}
+
+// RATE_LIMITS creates an instance of the public.rate_limits table.
+func RATE_LIMITS() TABLE_RATE_LIMITS {
+	tbl := TABLE_RATE_LIMITS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "rate_limits",
+	}}
+	tbl.COUNT = sq.NewNumberField("count", tbl.TableInfo)
+	tbl.KEY = sq.NewStringField("key", tbl.TableInfo)
+	tbl.WINDOW_END = sq.NewTimeField("window_end", tbl.TableInfo)
+	tbl.WINDOW_START = sq.NewTimeField("window_start", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_RATE_LIMITS) As(alias string) TABLE_RATE_LIMITS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_ROLE_ENUM references the public.role_enum table.
 type TABLE_ROLE_ENUM struct {
 	*sq.TableInfo
@@ -450,9 +834,15 @@ func (tbl TABLE_SCHEMA_MIGRATIONS) As(alias string) TABLE_SCHEMA_MIGRATIONS {
 // TABLE_SESSIONS references the public.sessions table.
 type TABLE_SESSIONS struct {
 	*sq.TableInfo
-	CREATED_AT sq.TimeField
-	HASH       sq.StringField
-	USER_ID    sq.NumberField
+	CREATED_AT       sq.TimeField
+	EXPIRES_AT       sq.TimeField
+	HASH             sq.StringField
+	IP_ADDRESS       sq.StringField
+	LAST_SEEN_AT     sq.TimeField
+	SESSION_ID       sq.NumberField
+	TOTP_VERIFIED_AT sq.TimeField
+	USER_AGENT       sq.StringField
+	USER_ID          sq.NumberField
 }
 
 // SESSIONS creates an instance of the public.sessions table.
@@ -462,7 +852,13 @@ func SESSIONS() TABLE_SESSIONS {
 		Name:   "sessions",
 	}}
 	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.EXPIRES_AT = sq.NewTimeField("expires_at", tbl.TableInfo)
 	tbl.HASH = sq.NewStringField("hash", tbl.TableInfo)
+	tbl.IP_ADDRESS = sq.NewStringField("ip_address", tbl.TableInfo)
+	tbl.LAST_SEEN_AT = sq.NewTimeField("last_seen_at", tbl.TableInfo)
+	tbl.SESSION_ID = sq.NewNumberField("session_id", tbl.TableInfo)
+	tbl.TOTP_VERIFIED_AT = sq.NewTimeField("totp_verified_at", tbl.TableInfo)
+	tbl.USER_AGENT = sq.NewStringField("user_agent", tbl.TableInfo)
 	tbl.USER_ID = sq.NewNumberField("user_id", tbl.TableInfo)
 	return tbl
 }
@@ -495,21 +891,64 @@ func (tbl TABLE_STAGE_ENUM) As(alias string) TABLE_STAGE_ENUM {
 	return tbl
 }
 
+// TABLE_SUBMISSION_REVISIONS references the public.submission_revisions table.
+type TABLE_SUBMISSION_REVISIONS struct {
+	*sq.TableInfo
+	CREATED_AT              sq.TimeField
+	CREATED_BY              sq.NumberField
+	POSTER                  sq.StringField
+	README                  sq.StringField
+	RESTORED_FROM           sq.NumberField
+	REVISION                sq.NumberField
+	SUBMISSION_DATA         sq.JSONField
+	SUBMISSION_FORM_VERSION sq.NumberField
+	SUBMISSION_ID           sq.NumberField
+	SUBMISSION_REVISION_ID  sq.NumberField
+	VIDEO                   sq.StringField
+}
+
+// SUBMISSION_REVISIONS creates an instance of the public.submission_revisions table.
+func SUBMISSION_REVISIONS() TABLE_SUBMISSION_REVISIONS {
+	tbl := TABLE_SUBMISSION_REVISIONS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "submission_revisions",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.CREATED_BY = sq.NewNumberField("created_by", tbl.TableInfo)
+	tbl.POSTER = sq.NewStringField("poster", tbl.TableInfo)
+	tbl.README = sq.NewStringField("readme", tbl.TableInfo)
+	tbl.RESTORED_FROM = sq.NewNumberField("restored_from", tbl.TableInfo)
+	tbl.REVISION = sq.NewNumberField("revision", tbl.TableInfo)
+	tbl.SUBMISSION_DATA = sq.NewJSONField("submission_data", tbl.TableInfo)
+	tbl.SUBMISSION_FORM_VERSION = sq.NewNumberField("submission_form_version", tbl.TableInfo)
+	tbl.SUBMISSION_ID = sq.NewNumberField("submission_id", tbl.TableInfo)
+	tbl.SUBMISSION_REVISION_ID = sq.NewNumberField("submission_revision_id", tbl.TableInfo)
+	tbl.VIDEO = sq.NewStringField("video", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_SUBMISSION_REVISIONS) As(alias string) TABLE_SUBMISSION_REVISIONS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_SUBMISSIONS references the public.submissions table.
 type TABLE_SUBMISSIONS struct {
 	*sq.TableInfo
-	CREATED_AT         sq.TimeField
-	DELETED_AT         sq.TimeField
-	OVERRIDE_OPEN      sq.BooleanField
-	POSTER             sq.StringField
-	README             sq.StringField
-	SUBMISSION_DATA    sq.JSONField
-	SUBMISSION_FORM_ID sq.NumberField
-	SUBMISSION_ID      sq.NumberField
-	SUBMITTED          sq.BooleanField
-	TEAM_ID            sq.NumberField
-	UPDATED_AT         sq.TimeField
-	VIDEO              sq.StringField
+	CREATED_AT              sq.TimeField
+	DELETED_AT              sq.TimeField
+	OVERRIDE_OPEN           sq.BooleanField
+	POSTER                  sq.StringField
+	README                  sq.StringField
+	SUBMISSION_DATA         sq.JSONField
+	SUBMISSION_FORM_ID      sq.NumberField
+	SUBMISSION_FORM_VERSION sq.NumberField
+	SUBMISSION_ID           sq.NumberField
+	SUBMITTED               sq.BooleanField
+	TEAM_ID                 sq.NumberField
+	UPDATED_AT              sq.TimeField
+	VIDEO                   sq.StringField
 }
 
 // SUBMISSIONS creates an instance of the public.submissions table.
@@ -525,6 +964,7 @@ func SUBMISSIONS() TABLE_SUBMISSIONS {
 	tbl.README = sq.NewStringField("readme", tbl.TableInfo)
 	tbl.SUBMISSION_DATA = sq.NewJSONField("submission_data", tbl.TableInfo)
 	tbl.SUBMISSION_FORM_ID = sq.NewNumberField("submission_form_id", tbl.TableInfo)
+	tbl.SUBMISSION_FORM_VERSION = sq.NewNumberField("submission_form_version", tbl.TableInfo)
 	tbl.SUBMISSION_ID = sq.NewNumberField("submission_id", tbl.TableInfo)
 	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
 	tbl.TEAM_ID = sq.NewNumberField("team_id", tbl.TableInfo)
@@ -595,6 +1035,7 @@ type TABLE_TEAM_EVALUATIONS struct {
 	EVALUATEE_SUBMISSION_ID sq.NumberField
 	EVALUATION_DATA         sq.JSONField
 	EVALUATION_FORM_ID      sq.NumberField
+	EVALUATION_FORM_VERSION sq.NumberField
 	EVALUATOR_TEAM_ID       sq.NumberField
 	OVERRIDE_OPEN           sq.BooleanField
 	SUBMITTED               sq.BooleanField
@@ -613,6 +1054,7 @@ func TEAM_EVALUATIONS() TABLE_TEAM_EVALUATIONS {
 	tbl.EVALUATEE_SUBMISSION_ID = sq.NewNumberField("evaluatee_submission_id", tbl.TableInfo)
 	tbl.EVALUATION_DATA = sq.NewJSONField("evaluation_data", tbl.TableInfo)
 	tbl.EVALUATION_FORM_ID = sq.NewNumberField("evaluation_form_id", tbl.TableInfo)
+	tbl.EVALUATION_FORM_VERSION = sq.NewNumberField("evaluation_form_version", tbl.TableInfo)
 	tbl.EVALUATOR_TEAM_ID = sq.NewNumberField("evaluator_team_id", tbl.TableInfo)
 	tbl.OVERRIDE_OPEN = sq.NewBooleanField("override_open", tbl.TableInfo)
 	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
@@ -627,6 +1069,102 @@ func (tbl TABLE_TEAM_EVALUATIONS) As(alias string) TABLE_TEAM_EVALUATIONS {
 	return tbl
 }
 
+// TABLE_TEAM_MEETING_ATTENDANCE references the public.team_meeting_attendance table.
+type TABLE_TEAM_MEETING_ATTENDANCE struct {
+	*sq.TableInfo
+	ATTENDED             sq.BooleanField
+	STUDENT_USER_ROLE_ID sq.NumberField
+	TEAM_MEETING_ID      sq.NumberField
+}
+
+// TEAM_MEETING_ATTENDANCE creates an instance of the public.team_meeting_attendance table.
+func TEAM_MEETING_ATTENDANCE() TABLE_TEAM_MEETING_ATTENDANCE {
+	tbl := TABLE_TEAM_MEETING_ATTENDANCE{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "team_meeting_attendance",
+	}}
+	tbl.ATTENDED = sq.NewBooleanField("attended", tbl.TableInfo)
+	tbl.STUDENT_USER_ROLE_ID = sq.NewNumberField("student_user_role_id", tbl.TableInfo)
+	tbl.TEAM_MEETING_ID = sq.NewNumberField("team_meeting_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_TEAM_MEETING_ATTENDANCE) As(alias string) TABLE_TEAM_MEETING_ATTENDANCE {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_TEAM_MEETINGS references the public.team_meetings table.
+type TABLE_TEAM_MEETINGS struct {
+	*sq.TableInfo
+	ACTION_ITEMS         sq.StringField
+	ADVISER_USER_ROLE_ID sq.NumberField
+	CREATED_AT           sq.TimeField
+	MEETING_DATE         sq.TimeField
+	NOTES                sq.StringField
+	TEAM_ID              sq.NumberField
+	TEAM_MEETING_ID      sq.NumberField
+	UPDATED_AT           sq.TimeField
+}
+
+// TEAM_MEETINGS creates an instance of the public.team_meetings table.
+func TEAM_MEETINGS() TABLE_TEAM_MEETINGS {
+	tbl := TABLE_TEAM_MEETINGS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "team_meetings",
+	}}
+	tbl.ACTION_ITEMS = sq.NewStringField("action_items", tbl.TableInfo)
+	tbl.ADVISER_USER_ROLE_ID = sq.NewNumberField("adviser_user_role_id", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.MEETING_DATE = sq.NewTimeField("meeting_date", tbl.TableInfo)
+	tbl.NOTES = sq.NewStringField("notes", tbl.TableInfo)
+	tbl.TEAM_ID = sq.NewNumberField("team_id", tbl.TableInfo)
+	tbl.TEAM_MEETING_ID = sq.NewNumberField("team_meeting_id", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_TEAM_MEETINGS) As(alias string) TABLE_TEAM_MEETINGS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_TEAM_STATUS_HISTORY references the public.team_status_history table.
+type TABLE_TEAM_STATUS_HISTORY struct {
+	*sq.TableInfo
+	CHANGED_BY             sq.NumberField
+	CREATED_AT             sq.TimeField
+	NEW_STATUS             sq.StringField
+	OLD_STATUS             sq.StringField
+	REASON                 sq.StringField
+	TEAM_ID                sq.NumberField
+	TEAM_STATUS_HISTORY_ID sq.NumberField
+}
+
+// TEAM_STATUS_HISTORY creates an instance of the public.team_status_history table.
+func TEAM_STATUS_HISTORY() TABLE_TEAM_STATUS_HISTORY {
+	tbl := TABLE_TEAM_STATUS_HISTORY{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "team_status_history",
+	}}
+	tbl.CHANGED_BY = sq.NewNumberField("changed_by", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.NEW_STATUS = sq.NewStringField("new_status", tbl.TableInfo)
+	tbl.OLD_STATUS = sq.NewStringField("old_status", tbl.TableInfo)
+	tbl.REASON = sq.NewStringField("reason", tbl.TableInfo)
+	tbl.TEAM_ID = sq.NewNumberField("team_id", tbl.TableInfo)
+	tbl.TEAM_STATUS_HISTORY_ID = sq.NewNumberField("team_status_history_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_TEAM_STATUS_HISTORY) As(alias string) TABLE_TEAM_STATUS_HISTORY {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_TEAMS references the public.teams table.
 type TABLE_TEAMS struct {
 	*sq.TableInfo
@@ -693,6 +1231,36 @@ func (tbl TABLE_TEAMS_STATUS_ENUM) As(alias string) TABLE_TEAMS_STATUS_ENUM {
 	return tbl
 }
 
+// TABLE_TOTP_RECOVERY_CODES references the public.totp_recovery_codes table.
+type TABLE_TOTP_RECOVERY_CODES struct {
+	*sq.TableInfo
+	CREATED_AT            sq.TimeField
+	HASH                  sq.StringField
+	TOTP_RECOVERY_CODE_ID sq.NumberField
+	USED_AT               sq.TimeField
+	USER_ID               sq.NumberField
+}
+
+// TOTP_RECOVERY_CODES creates an instance of the public.totp_recovery_codes table.
+func TOTP_RECOVERY_CODES() TABLE_TOTP_RECOVERY_CODES {
+	tbl := TABLE_TOTP_RECOVERY_CODES{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "totp_recovery_codes",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.HASH = sq.NewStringField("hash", tbl.TableInfo)
+	tbl.TOTP_RECOVERY_CODE_ID = sq.NewNumberField("totp_recovery_code_id", tbl.TableInfo)
+	tbl.USED_AT = sq.NewTimeField("used_at", tbl.TableInfo)
+	tbl.USER_ID = sq.NewNumberField("user_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_TOTP_RECOVERY_CODES) As(alias string) TABLE_TOTP_RECOVERY_CODES {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_USER_EVALUATIONS references the public.user_evaluations table.
 type TABLE_USER_EVALUATIONS struct {
 	*sq.TableInfo
@@ -701,6 +1269,7 @@ type TABLE_USER_EVALUATIONS struct {
 	EVALUATEE_SUBMISSION_ID sq.NumberField
 	EVALUATION_DATA         sq.JSONField
 	EVALUATION_FORM_ID      sq.NumberField
+	EVALUATION_FORM_VERSION sq.NumberField
 	EVALUATOR_USER_ROLE_ID  sq.NumberField
 	OVERRIDE_OPEN           sq.BooleanField
 	SUBMITTED               sq.BooleanField
@@ -719,6 +1288,7 @@ func USER_EVALUATIONS() TABLE_USER_EVALUATIONS {
 	tbl.EVALUATEE_SUBMISSION_ID = sq.NewNumberField("evaluatee_submission_id", tbl.TableInfo)
 	tbl.EVALUATION_DATA = sq.NewJSONField("evaluation_data", tbl.TableInfo)
 	tbl.EVALUATION_FORM_ID = sq.NewNumberField("evaluation_form_id", tbl.TableInfo)
+	tbl.EVALUATION_FORM_VERSION = sq.NewNumberField("evaluation_form_version", tbl.TableInfo)
 	tbl.EVALUATOR_USER_ROLE_ID = sq.NewNumberField("evaluator_user_role_id", tbl.TableInfo)
 	tbl.OVERRIDE_OPEN = sq.NewBooleanField("override_open", tbl.TableInfo)
 	tbl.SUBMITTED = sq.NewBooleanField("submitted", tbl.TableInfo)
@@ -770,10 +1340,11 @@ func (tbl TABLE_USER_ROLES) As(alias string) TABLE_USER_ROLES {
 // TABLE_USER_ROLES_APPLICANTS references the public.user_roles_applicants table.
 type TABLE_USER_ROLES_APPLICANTS struct {
 	*sq.TableInfo
-	APPLICANT_DATA    sq.JSONField
-	APPLICANT_FORM_ID sq.NumberField
-	APPLICATION_ID    sq.NumberField
-	USER_ROLE_ID      sq.NumberField
+	APPLICANT_DATA         sq.JSONField
+	APPLICANT_FORM_ID      sq.NumberField
+	APPLICANT_FORM_VERSION sq.NumberField
+	APPLICATION_ID         sq.NumberField
+	USER_ROLE_ID           sq.NumberField
 }
 
 // USER_ROLES_APPLICANTS creates an instance of the public.user_roles_applicants table.
@@ -784,6 +1355,7 @@ func USER_ROLES_APPLICANTS() TABLE_USER_ROLES_APPLICANTS {
 	}}
 	tbl.APPLICANT_DATA = sq.NewJSONField("applicant_data", tbl.TableInfo)
 	tbl.APPLICANT_FORM_ID = sq.NewNumberField("applicant_form_id", tbl.TableInfo)
+	tbl.APPLICANT_FORM_VERSION = sq.NewNumberField("applicant_form_version", tbl.TableInfo)
 	tbl.APPLICATION_ID = sq.NewNumberField("application_id", tbl.TableInfo)
 	tbl.USER_ROLE_ID = sq.NewNumberField("user_role_id", tbl.TableInfo)
 	return tbl
@@ -821,6 +1393,36 @@ func (tbl TABLE_USER_ROLES_STUDENTS) As(alias string) TABLE_USER_ROLES_STUDENTS
 	return tbl
 }
 
+// TABLE_USER_TOTP references the public.user_totp table.
+type TABLE_USER_TOTP struct {
+	*sq.TableInfo
+	CREATED_AT     sq.TimeField
+	ENABLED_AT     sq.TimeField
+	LAST_USED_STEP sq.NumberField
+	SECRET         sq.StringField
+	USER_ID        sq.NumberField
+}
+
+// USER_TOTP creates an instance of the public.user_totp table.
+func USER_TOTP() TABLE_USER_TOTP {
+	tbl := TABLE_USER_TOTP{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "user_totp",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.ENABLED_AT = sq.NewTimeField("enabled_at", tbl.TableInfo)
+	tbl.LAST_USED_STEP = sq.NewNumberField("last_used_step", tbl.TableInfo)
+	tbl.SECRET = sq.NewStringField("secret", tbl.TableInfo)
+	tbl.USER_ID = sq.NewNumberField("user_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_USER_TOTP) As(alias string) TABLE_USER_TOTP {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // TABLE_USERS references the public.users table.
 type TABLE_USERS struct {
 	*sq.TableInfo
@@ -849,6 +1451,162 @@ func (tbl TABLE_USERS) As(alias string) TABLE_USERS {
 	return tbl
 }
 
+// TABLE_WEBHOOK_DELIVERIES references the public.webhook_deliveries table.
+type TABLE_WEBHOOK_DELIVERIES struct {
+	*sq.TableInfo
+	ATTEMPTS            sq.NumberField
+	CREATED_AT          sq.TimeField
+	ERROR               sq.StringField
+	NEXT_ATTEMPT_AT     sq.TimeField
+	RESPONSE_BODY       sq.StringField
+	RESPONSE_STATUS     sq.NumberField
+	STATUS              sq.StringField
+	UPDATED_AT          sq.TimeField
+	WEBHOOK_DELIVERY_ID sq.NumberField
+	WEBHOOK_EVENT_ID    sq.NumberField
+	WEBHOOK_ID          sq.NumberField
+}
+
+// WEBHOOK_DELIVERIES creates an instance of the public.webhook_deliveries table.
+func WEBHOOK_DELIVERIES() TABLE_WEBHOOK_DELIVERIES {
+	tbl := TABLE_WEBHOOK_DELIVERIES{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "webhook_deliveries",
+	}}
+	tbl.ATTEMPTS = sq.NewNumberField("attempts", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.ERROR = sq.NewStringField("error", tbl.TableInfo)
+	tbl.NEXT_ATTEMPT_AT = sq.NewTimeField("next_attempt_at", tbl.TableInfo)
+	tbl.RESPONSE_BODY = sq.NewStringField("response_body", tbl.TableInfo)
+	tbl.RESPONSE_STATUS = sq.NewNumberField("response_status", tbl.TableInfo)
+	tbl.STATUS = sq.NewStringField("status", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	tbl.WEBHOOK_DELIVERY_ID = sq.NewNumberField("webhook_delivery_id", tbl.TableInfo)
+	tbl.WEBHOOK_EVENT_ID = sq.NewNumberField("webhook_event_id", tbl.TableInfo)
+	tbl.WEBHOOK_ID = sq.NewNumberField("webhook_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_WEBHOOK_DELIVERIES) As(alias string) TABLE_WEBHOOK_DELIVERIES {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_WEBHOOK_DELIVERY_STATUS_ENUM references the public.webhook_delivery_status_enum table.
+type TABLE_WEBHOOK_DELIVERY_STATUS_ENUM struct {
+	*sq.TableInfo
+	STATUS sq.StringField
+}
+
+// WEBHOOK_DELIVERY_STATUS_ENUM creates an instance of the public.webhook_delivery_status_enum table.
+func WEBHOOK_DELIVERY_STATUS_ENUM() TABLE_WEBHOOK_DELIVERY_STATUS_ENUM {
+	tbl := TABLE_WEBHOOK_DELIVERY_STATUS_ENUM{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "webhook_delivery_status_enum",
+	}}
+	tbl.STATUS = sq.NewStringField("status", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_WEBHOOK_DELIVERY_STATUS_ENUM) As(alias string) TABLE_WEBHOOK_DELIVERY_STATUS_ENUM {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_WEBHOOK_EVENT_ENUM references the public.webhook_event_enum table.
+type TABLE_WEBHOOK_EVENT_ENUM struct {
+	*sq.TableInfo
+	EVENT sq.StringField
+}
+
+// WEBHOOK_EVENT_ENUM creates an instance of the public.webhook_event_enum table.
+func WEBHOOK_EVENT_ENUM() TABLE_WEBHOOK_EVENT_ENUM {
+	tbl := TABLE_WEBHOOK_EVENT_ENUM{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "webhook_event_enum",
+	}}
+	tbl.EVENT = sq.NewStringField("event", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_WEBHOOK_EVENT_ENUM) As(alias string) TABLE_WEBHOOK_EVENT_ENUM {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_WEBHOOK_EVENTS references the public.webhook_events table.
+type TABLE_WEBHOOK_EVENTS struct {
+	*sq.TableInfo
+	CHANGES          sq.JSONField
+	CREATED_AT       sq.TimeField
+	DISPATCHED_AT    sq.TimeField
+	EVENT            sq.StringField
+	OBJECT_ID        sq.NumberField
+	PAYLOAD          sq.JSONField
+	WEBHOOK_EVENT_ID sq.NumberField
+}
+
+// WEBHOOK_EVENTS creates an instance of the public.webhook_events table.
+func WEBHOOK_EVENTS() TABLE_WEBHOOK_EVENTS {
+	tbl := TABLE_WEBHOOK_EVENTS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "webhook_events",
+	}}
+	tbl.CHANGES = sq.NewJSONField("changes", tbl.TableInfo)
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.DISPATCHED_AT = sq.NewTimeField("dispatched_at", tbl.TableInfo)
+	tbl.EVENT = sq.NewStringField("event", tbl.TableInfo)
+	tbl.OBJECT_ID = sq.NewNumberField("object_id", tbl.TableInfo)
+	tbl.PAYLOAD = sq.NewJSONField("payload", tbl.TableInfo)
+	tbl.WEBHOOK_EVENT_ID = sq.NewNumberField("webhook_event_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_WEBHOOK_EVENTS) As(alias string) TABLE_WEBHOOK_EVENTS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
+// TABLE_WEBHOOKS references the public.webhooks table.
+type TABLE_WEBHOOKS struct {
+	*sq.TableInfo
+	CREATED_AT  sq.TimeField
+	DESCRIPTION sq.StringField
+	ENABLED     sq.BooleanField
+	EVENTS      sq.ArrayField
+	SECRET      sq.StringField
+	UPDATED_AT  sq.TimeField
+	URL         sq.StringField
+	WEBHOOK_ID  sq.NumberField
+}
+
+// WEBHOOKS creates an instance of the public.webhooks table.
+func WEBHOOKS() TABLE_WEBHOOKS {
+	tbl := TABLE_WEBHOOKS{TableInfo: &sq.TableInfo{
+		Schema: "public",
+		Name:   "webhooks",
+	}}
+	tbl.CREATED_AT = sq.NewTimeField("created_at", tbl.TableInfo)
+	tbl.DESCRIPTION = sq.NewStringField("description", tbl.TableInfo)
+	tbl.ENABLED = sq.NewBooleanField("enabled", tbl.TableInfo)
+	tbl.EVENTS = sq.NewArrayField("events", tbl.TableInfo)
+	tbl.SECRET = sq.NewStringField("secret", tbl.TableInfo)
+	tbl.UPDATED_AT = sq.NewTimeField("updated_at", tbl.TableInfo)
+	tbl.URL = sq.NewStringField("url", tbl.TableInfo)
+	tbl.WEBHOOK_ID = sq.NewNumberField("webhook_id", tbl.TableInfo)
+	return tbl
+}
+
+// As modifies the alias of the underlying table.
+func (tbl TABLE_WEBHOOKS) As(alias string) TABLE_WEBHOOKS {
+	tbl.TableInfo.Alias = alias
+	return tbl
+}
+
 // VIEW_FUNCS references the public.funcs view.
 type VIEW_FUNCS struct {
 	*sq.TableInfo